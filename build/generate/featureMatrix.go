@@ -32,11 +32,18 @@ func generateFeatureMatrix() error {
 			{"ALIAS", "Provider supports some kind of ALIAS, ANAME or flattened CNAME record type"},
 			{"AUTODNSSEC", "Provider can automatically manage DNSSEC"},
 			{"CAA", "Provider can manage CAA records"},
+			{"CDNSKEY", "Provider can manage CDNSKEY records"},
+			{"CDS", "Provider can manage CDS records"},
+			{"DNAME", "Provider can manage DNAME records"},
+			{"HTTPS", "Provider can manage HTTPS records"},
 			{"PTR", "Provider supports adding PTR records for reverse lookup zones"},
 			{"NAPTR", "Provider can manage NAPTR records"},
+			{"OPENPGPKEY", "Provider can manage OPENPGPKEY records"},
+			{"SMIMEA", "Provider can manage SMIMEA records"},
 			{"SOA", "Provider can manage SOA records"},
 			{"SRV", "Driver has explicitly implemented SRV record management"},
 			{"SSHFP", "Provider can manage SSHFP records"},
+			{"SVCB", "Provider can manage SVCB records"},
 			{"TLSA", "Provider can manage TLSA records"},
 			{"TXTMulti", "Provider can manage TXT records with multiple strings"},
 			{"R53_ALIAS", "Provider supports Route 53 limited ALIAS"},
@@ -48,6 +55,7 @@ func generateFeatureMatrix() error {
 			{"create-domains", "This means the provider can automatically create domains that do not currently exist on your account. The 'dnscontrol create-domains' command will initialize any missing domains"},
 			{"no_purge", "indicates you can use NO_PURGE macro to prevent deleting records not managed by dnscontrol. A few providers that generate the entire zone from scratch have a problem implementing this."},
 			{"get-zones", "indicates the dnscontrol get-zones subcommand is implemented."},
+			{"concurrency verified", "This provider can be used by several goroutines at once, so its domains are processed in parallel with --parallel-domains."},
 		},
 	}
 	for _, p := range providerTypes {
@@ -83,15 +91,23 @@ func generateFeatureMatrix() error {
 		setCap("AUTODNSSEC", providers.CanAutoDNSSEC)
 		setCap("AZURE_ALIAS", providers.CanUseAzureAlias)
 		setCap("CAA", providers.CanUseCAA)
+		setCap("CDNSKEY", providers.CanUseCDNSKEY)
+		setCap("CDS", providers.CanUseCDS)
+		setCap("DNAME", providers.CanUseDNAME)
 		setCap("DS", providers.CanUseDS)
+		setCap("HTTPS", providers.CanUseHTTPS)
 		setCap("NAPTR", providers.CanUseNAPTR)
+		setCap("OPENPGPKEY", providers.CanUseOPENPGPKEY)
 		setCap("PTR", providers.CanUsePTR)
 		setCap("R53_ALIAS", providers.CanUseRoute53Alias)
+		setCap("SMIMEA", providers.CanUseSMIMEA)
 		setCap("SOA", providers.CanUseSOA)
 		setCap("SRV", providers.CanUseSRV)
 		setCap("SSHFP", providers.CanUseSSHFP)
+		setCap("SVCB", providers.CanUseSVCB)
 		setCap("TLSA", providers.CanUseTLSA)
 		setCap("get-zones", providers.CanGetZones)
+		setCap("concurrency verified", providers.CanConcur)
 		setDoc("create-domains", providers.DocCreateDomains, true)
 		setDoc("dual host", providers.DocDualHost, false)
 
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/StackExchange/dnscontrol/v3/pkg/credsfile"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args CapabilitiesArgs
+	return &cli.Command{
+		Name:  "capabilities",
+		Usage: "lists the capabilities of a provider (stand-alone)",
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() != 1 {
+				return cli.Exit("Arguments should be: provider (Ex: GCORE), or with --live, credkey or provider (Ex: gcore)", 1)
+			}
+			args.Name = ctx.Args().Get(0)
+			return exit(Capabilities(args))
+		},
+		Flags:     args.flags(),
+		UsageText: "dnscontrol capabilities [command options] provider",
+		Description: `List the capabilities of a provider type, as in the provider feature
+matrix of the documentation.
+
+With --live, the provider is initialized with its creds.json entry (named
+by its key, or by its TYPE if only one entry has it), and the capabilities
+are also listed as they are for that account: the ones the provider
+discovers (such as GCORE's CanAutoDNSSEC, which depends on the plan), and
+the "_capabilities" overrides in creds.json.
+
+EXAMPLES:
+   dnscontrol capabilities GCORE
+   dnscontrol capabilities --live gcore`,
+	}
+}())
+
+// CapabilitiesArgs contains all data/flags needed to run capabilities, independently of CLI.
+type CapabilitiesArgs struct {
+	GetCredentialsArgs        // Args related to creds.json
+	Name               string // provider type, or with Live, the key in creds.json
+	Live               bool   // Initialize the provider and list its effective capabilities
+}
+
+func (args *CapabilitiesArgs) flags() []cli.Flag {
+	flags := args.GetCredentialsArgs.flags()
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "live",
+		Destination: &args.Live,
+		Usage:       `List the capabilities of the account in creds.json`,
+	})
+	return flags
+}
+
+// Capabilities implements the capabilities subcommand.
+func Capabilities(args CapabilitiesArgs) error {
+	return printCapabilities(os.Stdout, args)
+}
+
+func printCapabilities(w io.Writer, args CapabilitiesArgs) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !args.Live {
+		if _, ok := providers.DNSProviderTypes[args.Name]; !ok {
+			return fmt.Errorf("no such DNS service provider: %q", args.Name)
+		}
+		fmt.Fprintln(tw, "CAPABILITY\tDEFAULT")
+		for _, cap := range providers.Capabilities() {
+			fmt.Fprintf(tw, "%s\t%t\n", cap, providers.ProviderHasCapability(args.Name, cap))
+		}
+		return tw.Flush()
+	}
+
+	providerConfigs, err := credsfile.LoadProviderConfigs(args.CredsFile)
+	if err != nil {
+		return fmt.Errorf("failed Capabilities LoadProviderConfigs(%q): %w", args.CredsFile, err)
+	}
+	name, err := credsEntry(providerConfigs, args.Name)
+	if err != nil {
+		return err
+	}
+	pType := providerConfigs[name][providerTypeFieldName]
+
+	// Providers that only discover capabilities when asked are asked to.
+	config := map[string]string{}
+	for k, v := range providerConfigs[name] {
+		config[k] = v
+	}
+	config["discover-capabilities"] = "true"
+	prov, err := providers.CreateDNSProvider(pType, config, nil)
+	if err != nil {
+		return fmt.Errorf("failed Capabilities CDP: %w", err)
+	}
+	var discovered map[providers.Capability]bool
+	if d, ok := prov.(providers.CapabilityDiscoverer); ok {
+		if discovered, err = d.DiscoverCapabilities(); err != nil {
+			return fmt.Errorf("%s: could not discover the account's capabilities: %w", name, err)
+		}
+	}
+	overrides, err := providers.ParseCapabilityOverrides(config["_capabilities"])
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	fmt.Fprintf(tw, "# %s (%s)\n", name, pType)
+	fmt.Fprintln(tw, "CAPABILITY\tDEFAULT\tLIVE\tSOURCE")
+	for _, cap := range providers.Capabilities() {
+		source := ""
+		if _, ok := overrides[cap]; ok {
+			source = "creds.json"
+		} else if _, ok := discovered[cap]; ok {
+			source = "discovered"
+		}
+		fmt.Fprintf(tw, "%s\t%t\t%t\t%s\n", cap,
+			providers.ProviderHasCapability(pType, cap),
+			providers.ProviderHasCapabilityOverridden(pType, cap, discovered, overrides),
+			source)
+	}
+	return tw.Flush()
+}
+
+// credsEntry returns the key of the creds.json entry named name, or if
+// there is none, of the only entry whose TYPE is name.
+func credsEntry(providerConfigs map[string]map[string]string, name string) (string, error) {
+	if _, ok := providerConfigs[name]; ok {
+		return name, nil
+	}
+	var found []string
+	for k, config := range providerConfigs {
+		if config[providerTypeFieldName] == name {
+			found = append(found, k)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("creds.json has no entry %q, nor one with TYPE %q", name, name)
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("creds.json has several entries with TYPE %q; name one of them", name)
+	}
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+)
+
+// discoveringProvider discovers that its account can use DNSSEC, if
+// asked to discover capabilities.
+type discoveringProvider struct {
+	readsProvider
+	discover bool
+}
+
+func (p *discoveringProvider) DiscoverCapabilities() (map[providers.Capability]bool, error) {
+	if !p.discover {
+		return nil, nil
+	}
+	return map[providers.Capability]bool{providers.CanAutoDNSSEC: true}, nil
+}
+
+func init() {
+	providers.RegisterDomainServiceProviderType("CAPS_LIVE", providers.DspFuncs{
+		Initializer: func(m map[string]string, _ json.RawMessage) (providers.DNSServiceProvider, error) {
+			return &discoveringProvider{discover: m["discover-capabilities"] == "true"}, nil
+		},
+		RecordAuditor: func([]*models.RecordConfig) []error { return nil },
+	}, providers.DocumentationNotes{
+		providers.CanUseSRV: providers.Can(),
+		providers.CanUseCAA: providers.Can(),
+	}, providers.OverridableCapabilities{providers.CanAutoDNSSEC})
+}
+
+func TestCapabilitiesLive(t *testing.T) {
+	credsFile := filepath.Join(t.TempDir(), "creds.json")
+	if err := os.WriteFile(credsFile, []byte(`{
+  "live": {"TYPE": "CAPS_LIVE", "_capabilities": "CanUseCAA=false"}
+}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := func(args CapabilitiesArgs) map[string]string {
+		var buf bytes.Buffer
+		if err := printCapabilities(&buf, args); err != nil {
+			t.Fatal(err)
+		}
+		m := map[string]string{}
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if name, rest, ok := strings.Cut(line, " "); ok {
+				m[name] = strings.Join(strings.Fields(rest), " ")
+			}
+		}
+		return m
+	}
+
+	static := lines(CapabilitiesArgs{Name: "CAPS_LIVE"})
+	for cap, exp := range map[string]string{
+		"CanAutoDNSSEC": "false",
+		"CanUseCAA":     "true",
+		"CanUseSRV":     "true",
+	} {
+		if static[cap] != exp {
+			t.Errorf("static %s: got %q, expected %q", cap, static[cap], exp)
+		}
+	}
+
+	// Named by the creds.json key, or by the TYPE.
+	for _, name := range []string{"live", "CAPS_LIVE"} {
+		args := CapabilitiesArgs{Name: name, Live: true}
+		args.CredsFile = credsFile
+		live := lines(args)
+		for cap, exp := range map[string]string{
+			"CanAutoDNSSEC": "false true discovered",
+			"CanUseCAA":     "true false creds.json",
+			"CanUseSRV":     "true true",
+		} {
+			if live[cap] != exp {
+				t.Errorf("%s: live %s: got %q, expected %q", name, cap, live[cap], exp)
+			}
+		}
+	}
+}
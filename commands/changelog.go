@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+)
+
+// changelogWriter appends a JSON line to a file for each change that a
+// push ran (--changelog), so that there is a record of every change
+// DNSControl made.
+type changelogWriter struct {
+	w      io.Writer
+	now    func() time.Time
+	failed bool // a write failed, which was already reported
+}
+
+// changelogEntry is a line of the changelog.
+type changelogEntry struct {
+	Time     time.Time `json:"time"`
+	Domain   string    `json:"domain"`
+	Provider string    `json:"provider"`
+	Action   string    `json:"action,omitempty"` // CREATE, MODIFY or DELETE, if the change starts with one
+	Record   string    `json:"record"`           // the change, without the action
+	Outcome  string    `json:"outcome"`          // "ok", "failed" or "skipped"
+	Error    string    `json:"error,omitempty"`
+}
+
+func newChangelogWriter(w io.Writer) *changelogWriter {
+	return &changelogWriter{w: w, now: time.Now}
+}
+
+// Add writes the entries of a correction's result: one for each line of
+// its message, since a correction may make several changes.
+func (c *changelogWriter) Add(r correctionResult) {
+	outcome, errMsg := "ok", ""
+	switch {
+	case r.Skipped:
+		outcome = "skipped"
+	case r.Err != nil:
+		outcome, errMsg = "failed", r.Err.Error()
+	}
+
+	t := c.now().UTC()
+	for _, line := range strings.Split(r.Msg, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		e := changelogEntry{Time: t, Domain: r.Domain, Provider: r.Provider, Record: line, Outcome: outcome, Error: errMsg}
+		if action, rest, ok := strings.Cut(line, " "); ok {
+			switch action {
+			case "CREATE", "MODIFY", "DELETE":
+				e.Action, e.Record = action, rest
+			}
+		}
+		enc := json.NewEncoder(c.w)
+		enc.SetEscapeHTML(false) // keep "->" readable
+		if err := enc.Encode(e); err != nil && !c.failed {
+			c.failed = true
+			printer.Warnf("Could not write the changelog: %s\n", err)
+		}
+	}
+}
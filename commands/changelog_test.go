@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+)
+
+func TestChangelogPush(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake");
+D("example.com", REG, DnsProvider(DSP),
+	A("a", "192.0.2.1"),
+	A("b", "192.0.2.2")
+);
+`, "")
+	changelog := filepath.Join(filepath.Dir(args.JSFile), "changes.jsonl")
+	args.Changelog = changelog
+
+	// Each push appends to the changelog; previews don't write to it.
+	for _, push := range []bool{true, false, true} {
+		testProvider = &changesProvider{}
+		var buf bytes.Buffer
+		if err := run(args, push, printer.ConsolePrinter{Writer: &buf}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := os.ReadFile(changelog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []changelogEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var e changelogEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("%q: no time", line)
+		}
+		e.Time = time.Time{}
+		got = append(got, e)
+	}
+	entry := func(record string) changelogEntry {
+		return changelogEntry{Domain: "example.com", Provider: "fake", Action: "CREATE", Record: record, Outcome: "ok"}
+	}
+	exp := []changelogEntry{
+		entry("a.example.com"), entry("b.example.com"),
+		entry("a.example.com"), entry("b.example.com"),
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("got:\n%+v\nexpected:\n%+v", got, exp)
+	}
+}
+
+func TestChangelogEntries(t *testing.T) {
+	var buf bytes.Buffer
+	c := newChangelogWriter(&buf)
+	c.now = func() time.Time { return time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	c.Add(correctionResult{Domain: "example.com", Provider: "gcore", Msg: "CREATE A www.example.com 192.0.2.1 ttl=300\nDELETE A old.example.com 192.0.2.2 ttl=300"})
+	c.Add(correctionResult{Domain: "example.com", Provider: "gcore", Msg: "Enable DNSSEC", Err: errors.New("forbidden")})
+	c.Add(correctionResult{Domain: "example.com", Provider: "gcore", Msg: "MODIFY A www.example.com: (192.0.2.1 ttl=300) -> (192.0.2.3 ttl=300)", Skipped: true})
+
+	exp := `{"time":"2023-01-02T03:04:05Z","domain":"example.com","provider":"gcore","action":"CREATE","record":"A www.example.com 192.0.2.1 ttl=300","outcome":"ok"}
+{"time":"2023-01-02T03:04:05Z","domain":"example.com","provider":"gcore","action":"DELETE","record":"A old.example.com 192.0.2.2 ttl=300","outcome":"ok"}
+{"time":"2023-01-02T03:04:05Z","domain":"example.com","provider":"gcore","record":"Enable DNSSEC","outcome":"failed","error":"forbidden"}
+{"time":"2023-01-02T03:04:05Z","domain":"example.com","provider":"gcore","action":"MODIFY","record":"A www.example.com: (192.0.2.1 ttl=300) -> (192.0.2.3 ttl=300)","outcome":"skipped"}
+`
+	if buf.String() != exp {
+		t.Errorf("got:\n%s\nexpected:\n%s", buf.String(), exp)
+	}
+}
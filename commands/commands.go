@@ -9,6 +9,7 @@ import (
 
 	"github.com/StackExchange/dnscontrol/v3/models"
 	"github.com/StackExchange/dnscontrol/v3/pkg/js"
+	"github.com/StackExchange/dnscontrol/v3/pkg/normalize"
 	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
 	"github.com/urfave/cli/v2"
 )
@@ -57,6 +58,11 @@ func Run(v string) int {
 			Usage:       "Enable JS fetch(), dangerous on untrusted code!",
 			Destination: &js.EnableFetch,
 		},
+		&cli.BoolFlag{
+			Name:        "allow-exec",
+			Usage:       "Enable JS DATA_COMMAND(), dangerous on untrusted code!",
+			Destination: &js.EnableExec,
+		},
 	}
 	sort.Sort(cli.CommandsByName(commands))
 	app.Commands = commands
@@ -74,6 +80,7 @@ func Run(v string) int {
 type GetDNSConfigArgs struct {
 	ExecuteDSLArgs
 	JSONFile string
+	Env      string
 }
 
 func (args *GetDNSConfigArgs) flags() []cli.Flag {
@@ -89,6 +96,11 @@ func (args *GetDNSConfigArgs) flags() []cli.Flag {
 			Hidden:      true,
 			Usage:       "same as -ir. only here for backwards compatibility, hence hidden",
 		},
+		&cli.StringFlag{
+			Destination: &args.Env,
+			Name:        "env",
+			Usage:       "Only manage the domains and records that ENV() assigns to this environment",
+		},
 	)
 }
 
@@ -117,6 +129,7 @@ func GetDNSConfig(args GetDNSConfigArgs) (*models.DNSConfig, error) {
 		}
 	}
 
+	normalize.SelectEnvironment(cfg, args.Env)
 	return preloadProviders(cfg)
 }
 
@@ -257,6 +270,7 @@ func (args *FilterArgs) flags() []cli.Flag {
 		},
 		&cli.StringFlag{
 			Name:        "domains",
+			Aliases:     []string{"only"},
 			Destination: &args.Domains,
 			Usage:       `Comma separated list of domain names to include`,
 			Value:       "",
@@ -295,3 +309,43 @@ func (args *FilterArgs) shouldRunDomain(d string) bool {
 	}
 	return false
 }
+
+// filterDomains removes the domains that shouldn't run from cfg. Doing
+// this before the providers are initialized means that the providers
+// are only set up, and their zones only read, for the selected domains.
+// The domains whose records a selected domain imports with
+// IMPORT_TRANSFORM are kept, since normalization needs them; callers
+// still skip them with shouldRunDomain.
+func (args *FilterArgs) filterDomains(cfg *models.DNSConfig) {
+	keep := map[string]bool{} // by Name
+	var pending []*models.DomainConfig
+	for _, d := range cfg.Domains {
+		normalize.UpdateNameSplitHorizon(d)
+		if args.shouldRunDomain(d.UniqueName) {
+			pending = append(pending, d)
+		}
+	}
+	for len(pending) != 0 {
+		d := pending[0]
+		pending = pending[1:]
+		if keep[d.Name] {
+			continue
+		}
+		keep[d.Name] = true
+		for _, rec := range d.Records {
+			if rec.Type != "IMPORT_TRANSFORM" {
+				continue
+			}
+			if src := cfg.FindDomain(rec.GetTargetField()); src != nil {
+				pending = append(pending, src)
+			}
+		}
+	}
+	var domains []*models.DomainConfig
+	for _, d := range cfg.Domains {
+		if keep[d.Name] {
+			domains = append(domains, d)
+		}
+	}
+	cfg.Domains = domains
+}
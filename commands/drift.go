@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/credsfile"
+	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
+	"github.com/StackExchange/dnscontrol/v3/pkg/nameservers"
+	"github.com/StackExchange/dnscontrol/v3/pkg/normalize"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args DriftArgs
+	return &cli.Command{
+		Name:  "drift",
+		Usage: "report records at the DNS providers that are not in dnsconfig.js (read-only)",
+		Action: func(ctx *cli.Context) error {
+			return exit(Drift(args))
+		},
+		Flags: args.flags(),
+		Description: `Lists, for each domain and DNS provider, the record sets (a label and
+record type) that exist at the provider but are not declared anywhere
+in dnsconfig.js. These are usually leftovers from manual changes, or
+records kept by NO_PURGE. Records matched by IGNORE_NAME or
+IGNORE_TARGET are not reported. Nothing is changed.
+
+Providers that mark the records DNSControl writes (such as GCORE with
+stamp-managed) also say whether each record is "external", that is,
+not written by DNSControl.`,
+	}
+}())
+
+// DriftArgs contains all data/flags needed to run drift, independently of CLI.
+type DriftArgs struct {
+	GetDNSConfigArgs
+	GetCredentialsArgs
+	FilterArgs
+}
+
+func (args *DriftArgs) flags() []cli.Flag {
+	flags := args.GetDNSConfigArgs.flags()
+	flags = append(flags, args.GetCredentialsArgs.flags()...)
+	flags = append(flags, args.FilterArgs.flags()...)
+	return flags
+}
+
+// Drift implements the drift subcommand.
+func Drift(args DriftArgs) error {
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	args.filterDomains(cfg)
+	providerConfigs, err := credsfile.LoadProviderConfigs(args.CredsFile)
+	if err != nil {
+		return err
+	}
+	if _, err := InitializeProviders(cfg, providerConfigs, false); err != nil {
+		return err
+	}
+
+	errs := normalize.ValidateAndNormalizeConfig(cfg)
+	if PrintValidationErrors(errs) {
+		return fmt.Errorf("exiting due to validation errors")
+	}
+	return driftReport(cfg, args.FilterArgs, os.Stdout)
+}
+
+// driftReport writes the orphaned records of each domain in cfg to w.
+// cfg must have been normalized and its providers initialized.
+func driftReport(cfg *models.DNSConfig, filter FilterArgs, w io.Writer) error {
+	anyErrors := false
+	total := 0
+	for _, domain := range cfg.Domains {
+		if !filter.shouldRunDomain(domain.UniqueName) {
+			continue
+		}
+		fmt.Fprintf(w, "******************** Domain: %s\n", domain.UniqueName)
+
+		// The apex NS records are added by DNSControl, not dnsconfig.js.
+		nsList, err := nameservers.DetermineNameserversForProviders(domain, domain.DNSProviderInstances)
+		if err != nil {
+			return err
+		}
+		domain.Nameservers = nsList
+		nameservers.AddNSRecords(domain)
+
+		for _, provider := range domain.DNSProviderInstances {
+			if !filter.shouldRunProvider(provider.Name, domain) {
+				continue
+			}
+			fmt.Fprintf(w, "----- DNS Provider: %s\n", provider.Name)
+
+			existing, err := provider.Driver.GetZoneRecords(domain.Name)
+			if err != nil {
+				fmt.Fprintf(w, "ERROR: %s\n", err)
+				anyErrors = true
+				continue
+			}
+			models.PostProcessRecords(existing)
+
+			dc, err := domain.Copy()
+			if err != nil {
+				return err
+			}
+			orphans, err := orphanedRecords(dc, existing)
+			if err != nil {
+				fmt.Fprintf(w, "ERROR: %s\n", err)
+				anyErrors = true
+				continue
+			}
+			stamper, _ := provider.Driver.(providers.ManagedStamper)
+			for _, rec := range orphans {
+				fmt.Fprintf(w, "ORPHAN %s %s %s ttl=%d%s\n", rec.GetLabelFQDN(), rec.Type, rec.GetTargetCombined(), rec.TTL, managedSuffix(stamper, rec))
+			}
+			total += len(orphans)
+		}
+	}
+	fmt.Fprintf(w, "Done. %d orphaned records.\n", total)
+	if anyErrors {
+		return fmt.Errorf("completed with errors")
+	}
+	return nil
+}
+
+// managedSuffix says who wrote rec, if the provider marks the records
+// that DNSControl writes: " (external)" if DNSControl didn't, and
+// " (written by DNSControl at <time>)" if it did. It is "" otherwise.
+func managedSuffix(stamper providers.ManagedStamper, rec *models.RecordConfig) string {
+	if stamper == nil || !stamper.StampsManaged() {
+		return ""
+	}
+	if written := stamper.LastManaged(rec); written != "" {
+		return " (written by DNSControl at " + written + ")"
+	}
+	return " (external)"
+}
+
+// orphanedRecords returns the records in existing whose label and type
+// has no records in dc. Ignored records are not included.
+func orphanedRecords(dc *models.DomainConfig, existing models.Records) (models.Records, error) {
+	// Orphans are what a push would delete if NO_PURGE weren't set.
+	dc.KeepUnknown = false
+	_, _, toDelete, _, err := diff.New(dc).IncrementalDiff(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := dc.Records.GroupedByKey()
+	var orphans models.Records
+	for _, c := range toDelete {
+		if _, ok := desired[c.Existing.Key()]; !ok {
+			orphans = append(orphans, c.Existing)
+		}
+	}
+	return orphans, nil
+}
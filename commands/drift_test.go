@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// driftProvider is a DNS provider with a fixed set of existing records.
+type driftProvider struct {
+	existing models.Records
+}
+
+func (p *driftProvider) GetNameservers(string) ([]*models.Nameserver, error) {
+	return models.ToNameservers([]string{"ns1.example.net"})
+}
+func (p *driftProvider) GetZoneRecords(string) (models.Records, error) { return p.existing, nil }
+func (p *driftProvider) GetDomainCorrections(*models.DomainConfig) ([]*models.Correction, error) {
+	return nil, nil
+}
+
+func makeDriftRC(label, typ, target string) *models.RecordConfig {
+	rc := &models.RecordConfig{Type: typ, TTL: 300}
+	rc.SetLabel(label, "example.com")
+	rc.SetTarget(target)
+	return rc
+}
+
+func TestDriftReport(t *testing.T) {
+	provider := &driftProvider{
+		existing: models.Records{
+			makeDriftRC("@", "NS", "ns1.example.net."),
+			makeDriftRC("www", "A", "192.0.2.1"),
+			makeDriftRC("www", "A", "192.0.2.2"), // changed, not orphaned
+			makeDriftRC("old", "A", "192.0.2.9"), // orphaned
+			makeDriftRC("ignored", "A", "192.0.2.10"),
+		},
+	}
+	dc := &models.DomainConfig{
+		Name:       "example.com",
+		UniqueName: "example.com",
+		Records: models.Records{
+			makeDriftRC("www", "A", "192.0.2.1"),
+			makeDriftRC("www", "A", "192.0.2.3"),
+		},
+		IgnoredNames: []*models.IgnoreName{{Pattern: "ignored", Types: "*"}},
+		DNSProviderInstances: []*models.DNSProviderInstance{{
+			ProviderBase:        models.ProviderBase{Name: "fake", IsDefault: true},
+			Driver:              provider,
+			NumberOfNameservers: -1,
+		}},
+	}
+	cfg := &models.DNSConfig{Domains: []*models.DomainConfig{dc}}
+
+	var buf bytes.Buffer
+	if err := driftReport(cfg, FilterArgs{}, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var orphans []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "ORPHAN ") {
+			orphans = append(orphans, line)
+		}
+	}
+	if len(orphans) != 1 || orphans[0] != "ORPHAN old.example.com A 192.0.2.9 ttl=300" {
+		t.Errorf("expected only old.example.com to be orphaned, got:\n%s", buf.String())
+	}
+	// The config must not be modified by the report.
+	if len(provider.existing) != 5 {
+		t.Errorf("existing records were modified")
+	}
+}
+
+// stampProvider is a driftProvider which marks the records DNSControl
+// writes with the "written" metadata.
+type stampProvider struct {
+	driftProvider
+}
+
+func (p *stampProvider) StampsManaged() bool { return true }
+func (p *stampProvider) LastManaged(rc *models.RecordConfig) string {
+	return rc.Metadata["written"]
+}
+
+func TestDriftReportManaged(t *testing.T) {
+	written := makeDriftRC("old", "A", "192.0.2.9")
+	written.Metadata = map[string]string{"written": "2026-10-01T12:00:00Z"}
+	provider := &stampProvider{driftProvider{
+		existing: models.Records{
+			makeDriftRC("@", "NS", "ns1.example.net."),
+			makeDriftRC("www", "A", "192.0.2.1"),
+			written,                                 // removed from dnsconfig.js
+			makeDriftRC("manual", "A", "192.0.2.8"), // added elsewhere
+		},
+	}}
+	dc := &models.DomainConfig{
+		Name:       "example.com",
+		UniqueName: "example.com",
+		Records:    models.Records{makeDriftRC("www", "A", "192.0.2.1")},
+		DNSProviderInstances: []*models.DNSProviderInstance{{
+			ProviderBase:        models.ProviderBase{Name: "fake", IsDefault: true},
+			Driver:              provider,
+			NumberOfNameservers: -1,
+		}},
+	}
+	cfg := &models.DNSConfig{Domains: []*models.DomainConfig{dc}}
+
+	var buf bytes.Buffer
+	if err := driftReport(cfg, FilterArgs{}, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var orphans []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "ORPHAN ") {
+			orphans = append(orphans, line)
+		}
+	}
+	exp := []string{
+		"ORPHAN manual.example.com A 192.0.2.8 ttl=300 (external)",
+		"ORPHAN old.example.com A 192.0.2.9 ttl=300 (written by DNSControl at 2026-10-01T12:00:00Z)",
+	}
+	if strings.Join(orphans, "\n") != strings.Join(exp, "\n") {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(orphans, "\n"), strings.Join(exp, "\n"))
+	}
+}
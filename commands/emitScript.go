@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// scriptWriter writes corrections as a shell script of curl commands,
+// so that they can be reviewed or run by hand (--emit-script).
+type scriptWriter struct {
+	w *bufio.Writer
+}
+
+func newScriptWriter(w io.Writer) *scriptWriter {
+	s := &scriptWriter{w: bufio.NewWriter(w)}
+	fmt.Fprintf(s.w, "#!/bin/sh\n")
+	fmt.Fprintf(s.w, "# Generated by dnscontrol. Review each command before running it.\n")
+	fmt.Fprintf(s.w, "set -e\n")
+	return s
+}
+
+// Add writes the requests of corrections. Corrections that don't
+// describe their requests are written as comments to be done by hand.
+func (s *scriptWriter) Add(domain, provider string, corrections []*models.Correction) {
+	for i, c := range corrections {
+		fmt.Fprintf(s.w, "\n")
+		for _, line := range strings.Split(fmt.Sprintf("%s (%s) #%d: %s", domain, provider, i+1, c.Msg), "\n") {
+			fmt.Fprintf(s.w, "# %s\n", line)
+		}
+		if len(c.Requests) == 0 {
+			fmt.Fprintf(s.w, "# NOT INCLUDED: %s doesn't describe its requests; make this change by hand.\n", provider)
+			continue
+		}
+		for _, req := range c.Requests {
+			s.writeRequest(req)
+		}
+	}
+}
+
+func (s *scriptWriter) writeRequest(req models.HTTPRequest) {
+	fmt.Fprintf(s.w, "curl -sSf -X %s %s", req.Method, shellQuote(req.URL))
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		// Double quotes, so that environment variables are expanded.
+		fmt.Fprintf(s.w, " \\\n  -H %s", shellQuoteExpand(k+": "+req.Header[k]))
+	}
+	if req.Body != nil {
+		fmt.Fprintf(s.w, " \\\n  --data-binary %s", shellQuote(string(req.Body)))
+	}
+	fmt.Fprintf(s.w, "\n")
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (s *scriptWriter) Flush() error {
+	return s.w.Flush()
+}
+
+// shellQuote quotes s for sh, without expansions.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellQuoteExpand quotes s for sh, but expands variables in it.
+func shellQuoteExpand(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`").Replace(s) + `"`
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestScriptWriter(t *testing.T) {
+	var buf bytes.Buffer
+	s := newScriptWriter(&buf)
+	s.Add("example.com", "gcore", []*models.Correction{
+		{
+			Msg: "+ CREATE A www.example.com 192.0.2.1 ttl=300",
+			Requests: []models.HTTPRequest{{
+				Method: "POST",
+				URL:    "https://api.gcore.com/dns/v2/zones/example.com/www.example.com/A",
+				Header: map[string]string{"Authorization": "APIKey $GCORE_API_KEY", "Content-Type": "application/json"},
+				Body:   []byte(`{"ttl":300,"resource_records":[{"content":["192.0.2.1"]}]}`),
+			}},
+		},
+		{
+			Msg: "+ CREATE TXT example.com \"it's\" ttl=300\n- DELETE TXT example.com \"old\" ttl=300",
+		},
+		{
+			// The PTR record of www.example.com, in the reverse zone.
+			Msg: "PTR 1.2.0.192.in-addr.arpa www.example.com. (for 192.0.2.1, in 2.0.192.in-addr.arpa)",
+			Requests: []models.HTTPRequest{{
+				Method: "PUT",
+				URL:    "https://api.gcore.com/dns/v2/zones/2.0.192.in-addr.arpa/1.2.0.192.in-addr.arpa/PTR",
+				Header: map[string]string{"Authorization": "APIKey $GCORE_API_KEY", "Content-Type": "application/json"},
+				Body:   []byte(`{"ttl":300,"resource_records":[{"content":["www.example.com."]}]}`),
+			}},
+		},
+	})
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = `#!/bin/sh
+# Generated by dnscontrol. Review each command before running it.
+set -e
+
+# example.com (gcore) #1: + CREATE A www.example.com 192.0.2.1 ttl=300
+curl -sSf -X POST 'https://api.gcore.com/dns/v2/zones/example.com/www.example.com/A' \
+  -H "Authorization: APIKey $GCORE_API_KEY" \
+  -H "Content-Type: application/json" \
+  --data-binary '{"ttl":300,"resource_records":[{"content":["192.0.2.1"]}]}'
+
+# example.com (gcore) #2: + CREATE TXT example.com "it's" ttl=300
+# - DELETE TXT example.com "old" ttl=300
+# NOT INCLUDED: gcore doesn't describe its requests; make this change by hand.
+
+# example.com (gcore) #3: PTR 1.2.0.192.in-addr.arpa www.example.com. (for 192.0.2.1, in 2.0.192.in-addr.arpa)
+curl -sSf -X PUT 'https://api.gcore.com/dns/v2/zones/2.0.192.in-addr.arpa/1.2.0.192.in-addr.arpa/PTR' \
+  -H "Authorization: APIKey $GCORE_API_KEY" \
+  -H "Content-Type: application/json" \
+  --data-binary '{"ttl":300,"resource_records":[{"content":["www.example.com."]}]}'
+`
+	if buf.String() != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for _, tc := range []struct{ in, quoted, expand string }{
+		{`plain`, `'plain'`, `"plain"`},
+		{`it's`, `'it'\''s'`, `"it's"`},
+		{`say "$HI" \ ` + "`x`", `'say "$HI" \ ` + "`x`'", `"say \"$HI\" \\ \` + "`x\\`" + `"`},
+	} {
+		if got := shellQuote(tc.in); got != tc.quoted {
+			t.Errorf("shellQuote(%q) = %s, expected %s", tc.in, got, tc.quoted)
+		}
+		if got := shellQuoteExpand(tc.in); got != tc.expand {
+			t.Errorf("shellQuoteExpand(%q) = %s, expected %s", tc.in, got, tc.expand)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/normalize"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args FindArgs
+	return &cli.Command{
+		Name:  "find",
+		Usage: "Find the records in dnsconfig.js with a name, type or value. Do not access providers.",
+		Action: func(ctx *cli.Context) error {
+			if args.Query == (models.RecordQuery{}) {
+				return cli.Exit("At least one of --name, --type or --value is required", 1)
+			}
+			return exit(Find(args, os.Stdout))
+		},
+		Flags: args.flags(),
+		Description: `List the records of all domains in dnsconfig.js that match every
+filter given. --name and --value may be patterns, such as "*.example.com".
+Names and hostnames are compared without regard to case or a trailing
+dot, and IP addresses by their value.
+
+EXAMPLES:
+   dnscontrol find --value 203.0.113.10
+   dnscontrol find --type CNAME --value lb.example.net
+   dnscontrol find --name '*.example.com' --type MX`,
+	}
+}())
+
+// FindArgs contains all data/flags needed to run find, independently of CLI.
+type FindArgs struct {
+	GetDNSConfigArgs
+	Query models.RecordQuery
+}
+
+func (args *FindArgs) flags() []cli.Flag {
+	flags := args.GetDNSConfigArgs.flags()
+	flags = append(flags, &cli.StringFlag{
+		Name:        "name",
+		Destination: &args.Query.Name,
+		Usage:       `Fully qualified name, or a pattern such as *.example.com`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "type",
+		Destination: &args.Query.Type,
+		Usage:       `Record type, such as CNAME`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "value",
+		Destination: &args.Query.Value,
+		Usage:       `Target, such as an IP address or hostname, or a pattern`,
+	})
+	return flags
+}
+
+// Find implements the find subcommand. It writes the records that match
+// args.Query to w.
+func Find(args FindArgs, w io.Writer) error {
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	if PrintValidationErrors(normalize.ValidateAndNormalizeConfig(cfg)) {
+		return fmt.Errorf("exiting due to validation errors")
+	}
+
+	found := cfg.FindRecords(args.Query)
+	if len(found) == 0 {
+		return fmt.Errorf("no records found")
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DOMAIN\tNAME\tTYPE\tVALUE\tSOURCE")
+	for _, f := range found {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", f.Domain.UniqueName, f.Record.GetLabelFQDN(), f.Record.Type, f.Record.GetTargetCombined(), f.Record.Source)
+	}
+	return tw.Flush()
+}
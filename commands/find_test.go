@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestFind(t *testing.T) {
+	jsFile := filepath.Join(t.TempDir(), "dnsconfig.js")
+	if err := os.WriteFile(jsFile, []byte(`
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake", "PP_FAKE");
+D("example.com", REG, DnsProvider(DSP),
+	A("@", "203.0.113.10"),
+	CNAME("www", "lb.example.net.")
+);
+D("example.org", REG, DnsProvider(DSP),
+	A("shop", "203.0.113.10"),
+	A("mail", "203.0.113.11")
+);
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var args FindArgs
+	args.JSFile = jsFile
+	args.Query = models.RecordQuery{Value: "203.0.113.10"}
+	var buf bytes.Buffer
+	if err := Find(args, &buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 records, got:\n%s", buf.String())
+	}
+	for i, exp := range []string{"example.com example.com A 203.0.113.10", "example.org shop.example.org A 203.0.113.10"} {
+		if got := strings.Join(strings.Fields(lines[i+1])[:4], " "); got != exp {
+			t.Errorf("got %q, expected %q", got, exp)
+		}
+	}
+	if !strings.HasSuffix(lines[1], "dnsconfig.js:5") {
+		t.Errorf("expected %q to end with the source of the record", lines[1])
+	}
+
+	args.Query = models.RecordQuery{Type: "MX"}
+	if err := Find(args, &buf); err == nil {
+		t.Error("expected an error, as no records match")
+	}
+}
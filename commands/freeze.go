@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// frozen returns why push is refused at now by freeze, the value of
+// push --freeze, or "" if it isn't. freeze is "true", to refuse every
+// push, or a comma-separated list of windows, each the RFC 3339 times
+// START/END, to refuse pushes from START until END.
+func frozen(freeze string, now time.Time) (string, error) {
+	freeze = strings.TrimSpace(freeze)
+	switch freeze {
+	case "", "false":
+		return "", nil
+	case "true":
+		return "changes are frozen", nil
+	}
+	for _, window := range strings.Split(freeze, ",") {
+		s, e, ok := strings.Cut(strings.TrimSpace(window), "/")
+		if !ok {
+			return "", fmt.Errorf("--freeze window %q must be START/END", window)
+		}
+		start, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return "", fmt.Errorf("--freeze window %q: %w", window, err)
+		}
+		end, err := time.Parse(time.RFC3339, e)
+		if err != nil {
+			return "", fmt.Errorf("--freeze window %q: %w", window, err)
+		}
+		if !now.Before(start) && now.Before(end) {
+			return fmt.Sprintf("changes are frozen from %s until %s", s, e), nil
+		}
+	}
+	return "", nil
+}
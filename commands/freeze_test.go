@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrozen(t *testing.T) {
+	now := time.Date(2026, 12, 24, 12, 0, 0, 0, time.UTC)
+	const holidays = "2026-12-20T00:00:00Z/2027-01-04T00:00:00Z"
+	for _, tc := range []struct {
+		freeze string
+		reason string
+		err    bool
+	}{
+		{freeze: ""},
+		{freeze: "false"},
+		{freeze: "true", reason: "changes are frozen"},
+		{freeze: holidays, reason: "changes are frozen from 2026-12-20T00:00:00Z until 2027-01-04T00:00:00Z"},
+		{freeze: "2026-11-01T00:00:00Z/2026-11-02T00:00:00Z"},
+		{freeze: "2026-11-01T00:00:00Z/2026-11-02T00:00:00Z, " + holidays, reason: "changes are frozen from 2026-12-20T00:00:00Z until 2027-01-04T00:00:00Z"},
+		// The end is excluded.
+		{freeze: "2026-12-24T00:00:00Z/2026-12-24T12:00:00Z"},
+		{freeze: "2026-12-20", err: true},
+		{freeze: "2026-12-20/2027-01-04", err: true},
+	} {
+		reason, err := frozen(tc.freeze, now)
+		if (err != nil) != tc.err {
+			t.Errorf("%q: got error %v", tc.freeze, err)
+		}
+		if reason != tc.reason {
+			t.Errorf("%q: got %q, expected %q", tc.freeze, reason, tc.reason)
+		}
+	}
+}
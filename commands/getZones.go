@@ -3,8 +3,11 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
 	"github.com/StackExchange/dnscontrol/v3/pkg/credsfile"
@@ -53,6 +56,8 @@ FORMATS:
    --format=djs       js with disco commas (leading commas)
    --format=zone      BIND zonefile format
    --format=tsv       TAB separated value (useful for AWK)
+   --format=md        Markdown table (useful for documentation and reviews)
+   --format=json      JSON records by zone (a snapshot for preview --from-snapshot)
    --format=nameonly  Just print the zone names
 
 The columns in --format=tsv are:
@@ -65,6 +70,13 @@ The columns in --format=tsv are:
 
 The --ttl flag only applies to zone/js/djs formats.
 
+The --concurrency flag downloads several zones in parallel.
+
+The --meta flag puts all of the record's metadata in the last tsv
+column, such as "gcore_health=unhealthy". Zone-level metadata, such as
+DNSSEC parameters, is written before the zone's records as a line
+starting with "#".
+
 EXAMPLES:
    dnscontrol get-zones myr53 ROUTE53 example.com
    dnscontrol get-zones gmain GANDI_V5 example.com other.com
@@ -129,6 +141,8 @@ type GetZoneArgs struct {
 	OutputFormat       string   // Output format
 	OutputFile         string   // Filename to send output ("" means stdout)
 	DefaultTTL         int      // default TTL for providers where it is unknown
+	Concurrency        int      // number of zones to download at once
+	ShowMeta           bool     // include all record metadata in tsv output
 }
 
 func (args *GetZoneArgs) flags() []cli.Flag {
@@ -137,7 +151,7 @@ func (args *GetZoneArgs) flags() []cli.Flag {
 		Name:        "format",
 		Destination: &args.OutputFormat,
 		Value:       "zone",
-		Usage:       `Output format: js djs zone tsv nameonly`,
+		Usage:       `Output format: js djs zone tsv md json nameonly`,
 	})
 	flags = append(flags, &cli.StringFlag{
 		Name:        "out",
@@ -149,6 +163,17 @@ func (args *GetZoneArgs) flags() []cli.Flag {
 		Destination: &args.DefaultTTL,
 		Usage:       `Default TTL (0 picks the zone's most common TTL)`,
 	})
+	flags = append(flags, &cli.IntFlag{
+		Name:        "concurrency",
+		Destination: &args.Concurrency,
+		Value:       1,
+		Usage:       `Number of zones to download at once`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "meta",
+		Destination: &args.ShowMeta,
+		Usage:       `Include all provider metadata in the tsv properties column`,
+	})
 	return flags
 }
 
@@ -197,14 +222,31 @@ func GetZone(args GetZoneArgs) error {
 		return nil
 	}
 
-	// fetch all of the records
-	zoneRecs := make([]models.Records, len(zones))
+	// fetch all of the records. A zone that can't be read is reported
+	// and skipped, so that one bad zone doesn't prevent the others from
+	// being downloaded.
+	allRecs, errs := getZoneRecords(provider, zones, args.Concurrency)
+	var zoneRecs []models.Records
+	var readZones, failedZones []string
 	for i, zone := range zones {
-		recs, err := provider.GetZoneRecords(zone)
-		if err != nil {
-			return fmt.Errorf("failed GetZone gzr: %w", err)
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: failed to get zone %q: %s\n", zone, errs[i])
+			failedZones = append(failedZones, zone)
+			continue
+		}
+		readZones = append(readZones, zone)
+		zoneRecs = append(zoneRecs, allRecs[i])
+	}
+	zones = readZones
+
+	if args.OutputFormat == "json" {
+		if err := writeSnapshot(w, zones, zoneRecs); err != nil {
+			return err
 		}
-		zoneRecs[i] = recs
+		if len(failedZones) != 0 {
+			return fmt.Errorf("failed GetZone gzr: could not get %d zone(s): %s", len(failedZones), strings.Join(failedZones, ", "))
+		}
+		return nil
 	}
 
 	// Write the heading:
@@ -236,51 +278,25 @@ func GetZone(args GetZoneArgs) error {
 			fmt.Fprintln(w)
 
 		case "js", "djs":
-			sep := ",\n\t" // Commas at EOL
-			if args.OutputFormat == "djs" {
-				sep = "\n\t, " // Funky comma mode
-			}
-			fmt.Fprintf(w, `D("%s", REG_CHANGEME%s`, zoneName, sep)
-			var o []string
-			o = append(o, fmt.Sprintf("DnsProvider(%s)", dspVariableName))
-			defaultTTL := uint32(args.DefaultTTL)
-			if defaultTTL == 0 {
-				defaultTTL = prettyzone.MostCommonTTL(recs)
-			}
-			if defaultTTL != models.DefaultTTL && defaultTTL != 0 {
-				o = append(o, fmt.Sprintf("DefaultTTL(%d)", defaultTTL))
-			}
-			for _, rec := range recs {
-				if (rec.Type == "CNAME") && (rec.Name == "@") {
-					o = append(o, "// NOTE: CNAME at apex may require manual editing.")
-				}
-				o = append(o, formatDsl(zoneName, rec, defaultTTL))
-			}
-			out := strings.Join(o, sep)
-
-			// Joining with a comma between each item works great but
-			// makes comments look terrible.  Here we clean them up
-			// after the fact.
-			if args.OutputFormat == "djs" {
-				out = strings.ReplaceAll(out, "\n\t, //", "\n\t//, ") // Fix comments
-				out = strings.ReplaceAll(out,
-					"//,  NOTE: CNAME at apex may require manual editing.",
-					"// NOTE: CNAME at apex may require manual editing.",
-				)
-			} else {
-				out = strings.ReplaceAll(out,
-					"// NOTE: CNAME at apex may require manual editing.,",
-					"// NOTE: CNAME at apex may require manual editing.",
-				)
+			writeDomainJS(w, args.OutputFormat, zoneName, dspVariableName, recs, uint32(args.DefaultTTL))
+
+		case "md":
+			fmt.Fprintf(w, "## %s\n\n", zoneName)
+			if err := prettyzone.WriteMarkdownTable(w, recs, zoneName); err != nil {
+				return err
 			}
-			fmt.Fprint(w, out)
-			fmt.Fprint(w, "\n)\n")
+			fmt.Fprintln(w)
 
 		case "tsv":
+			if args.ShowMeta {
+				writeZoneMetaTSV(w, provider, zoneName)
+			}
 			for _, rec := range recs {
 
 				cfproxy := ""
-				if cp, ok := rec.Metadata["cloudflare_proxy"]; ok {
+				if args.ShowMeta {
+					cfproxy = formatMeta(rec.Metadata)
+				} else if cp, ok := rec.Metadata["cloudflare_proxy"]; ok {
 					if cp == "true" {
 						cfproxy = "\tcloudflare_proxy=true"
 					}
@@ -294,9 +310,40 @@ func GetZone(args GetZoneArgs) error {
 			return fmt.Errorf("format %q unknown", args.OutputFormat)
 		}
 	}
+
+	if len(failedZones) != 0 {
+		return fmt.Errorf("failed GetZone gzr: could not get %d zone(s): %s", len(failedZones), strings.Join(failedZones, ", "))
+	}
 	return nil
 }
 
+// getZoneRecords gets the records of each zone, reading up to
+// concurrency zones at a time. The results are in the same order as
+// zones.
+func getZoneRecords(provider providers.DNSServiceProvider, zones []string, concurrency int) ([]models.Records, []error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	recs := make([]models.Records, len(zones))
+	errs := make([]error, len(zones))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, zone := range zones {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, zone string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			recs[i], errs[i] = provider.GetZoneRecords(zone)
+		}(i, zone)
+	}
+	wg.Wait()
+	return recs, errs
+}
+
 // jsonQuoted returns a properly escaped JSON string (without quotes).
 func jsonQuoted(i string) string {
 	// https://stackoverflow.com/questions/51691901
@@ -307,6 +354,85 @@ func jsonQuoted(i string) string {
 	return string(b)
 }
 
+// writeDomainJS writes the D() statement for a zone in js or djs format.
+// A defaultTTL of 0 picks the zone's most common TTL.
+func writeDomainJS(w io.Writer, format, zoneName, dspVariableName string, recs models.Records, defaultTTL uint32) {
+	sep := ",\n\t" // Commas at EOL
+	if format == "djs" {
+		sep = "\n\t, " // Funky comma mode
+	}
+	fmt.Fprintf(w, `D("%s", REG_CHANGEME%s`, zoneName, sep)
+	var o []string
+	o = append(o, fmt.Sprintf("DnsProvider(%s)", dspVariableName))
+	if defaultTTL == 0 {
+		defaultTTL = prettyzone.MostCommonTTL(recs)
+	}
+	if defaultTTL != models.DefaultTTL && defaultTTL != 0 {
+		o = append(o, fmt.Sprintf("DefaultTTL(%d)", defaultTTL))
+	}
+	for _, rec := range recs {
+		if (rec.Type == "CNAME") && (rec.Name == "@") {
+			o = append(o, "// NOTE: CNAME at apex may require manual editing.")
+		}
+		o = append(o, formatDsl(zoneName, rec, defaultTTL))
+	}
+	out := strings.Join(o, sep)
+
+	// Joining with a comma between each item works great but
+	// makes comments look terrible.  Here we clean them up
+	// after the fact.
+	if format == "djs" {
+		out = strings.ReplaceAll(out, "\n\t, //", "\n\t//, ") // Fix comments
+		out = strings.ReplaceAll(out,
+			"//,  NOTE: CNAME at apex may require manual editing.",
+			"// NOTE: CNAME at apex may require manual editing.",
+		)
+	} else {
+		out = strings.ReplaceAll(out,
+			"// NOTE: CNAME at apex may require manual editing.,",
+			"// NOTE: CNAME at apex may require manual editing.",
+		)
+	}
+	fmt.Fprint(w, out)
+	fmt.Fprint(w, "\n)\n")
+}
+
+// writeZoneMetaTSV writes the zone-level metadata of zoneName, if the
+// provider has any, as a comment line. Errors are only reported since
+// the metadata is informational.
+func writeZoneMetaTSV(w io.Writer, provider providers.DNSServiceProvider, zoneName string) {
+	getter, ok := provider.(providers.ZoneMetadataGetter)
+	if !ok {
+		return
+	}
+	meta, err := getter.GetZoneMetadata(zoneName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: failed to get zone metadata of %q: %s\n", zoneName, err)
+		return
+	}
+	if len(meta) != 0 {
+		fmt.Fprintf(w, "# %s%s\n", zoneName, formatMeta(meta))
+	}
+}
+
+// formatMeta returns the tsv properties column for metadata m, or
+// "" if m is empty.
+func formatMeta(m map[string]string) string {
+	if len(m) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	props := make([]string, len(keys))
+	for i, k := range keys {
+		props[i] = k + "=" + m[k]
+	}
+	return "\t" + strings.Join(props, ",")
+}
+
 func formatDsl(zonename string, rec *models.RecordConfig, defaultTTL uint32) string {
 
 	target := rec.GetTargetCombined()
@@ -328,6 +454,10 @@ func formatDsl(zonename string, rec *models.RecordConfig, defaultTTL uint32) str
 	switch rec.Type { // #rtype_variations
 	case "CAA":
 		return makeCaa(rec, ttlop)
+	case "CDNSKEY":
+		target = fmt.Sprintf("%d, %d, %d, '%s'", rec.DnskeyFlags, rec.DnskeyProtocol, rec.DnskeyAlgorithm, rec.GetTargetField())
+	case "CDS":
+		target = fmt.Sprintf("%d, %d, %d, '%s'", rec.DsKeyTag, rec.DsAlgorithm, rec.DsDigestType, rec.DsDigest)
 	case "MX":
 		target = fmt.Sprintf("%d, '%s'", rec.MxPreference, rec.GetTargetField())
 	case "NAPTR":
@@ -339,8 +469,12 @@ func formatDsl(zonename string, rec *models.RecordConfig, defaultTTL uint32) str
 			jsonQuoted(rec.NaptrRegexp),      // regex
 			jsonQuoted(rec.GetTargetField()), // .
 		)
+	case "SMIMEA":
+		target = fmt.Sprintf("%d, %d, %d, '%s'", rec.SmimeaUsage, rec.SmimeaSelector, rec.SmimeaMatchingType, rec.GetTargetField())
 	case "SSHFP":
 		target = fmt.Sprintf("%d, %d, '%s'", rec.SshfpAlgorithm, rec.SshfpFingerprint, rec.GetTargetField())
+	case "SVCB", "HTTPS":
+		target = fmt.Sprintf("%d, '%s', %s", rec.SvcPriority, rec.GetTargetField(), jsonQuoted(rec.SvcParams))
 	case "SOA":
 		rec.Type = "//SOA"
 		target = fmt.Sprintf("'%s', '%s', %d, %d, %d, %d, %d", rec.GetTargetField(), rec.SoaMbox, rec.SoaSerial, rec.SoaRefresh, rec.SoaRetry, rec.SoaExpire, rec.SoaMinttl)
@@ -1,11 +1,18 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/providers"
 	_ "github.com/StackExchange/dnscontrol/v3/providers/_all"
 	"github.com/andreyvit/diff"
 )
@@ -76,3 +83,197 @@ func testFormat(t *testing.T, domain, format string) {
 		t.Errorf("testFormat mismatch (-got +want):\n%s", diff.LineDiff(g, w))
 	}
 }
+
+// partialProvider is a ZoneLister whose "bad.com" zone can't be read.
+// The records of "c.com", and the zone itself, have metadata.
+type partialProvider struct{}
+
+func (partialProvider) GetNameservers(string) ([]*models.Nameserver, error) { return nil, nil }
+func (partialProvider) GetDomainCorrections(*models.DomainConfig) ([]*models.Correction, error) {
+	return nil, nil
+}
+func (partialProvider) ListZones() ([]string, error) {
+	return []string{"a.com", "bad.com", "c.com"}, nil
+}
+func (partialProvider) GetZoneRecords(domain string) (models.Records, error) {
+	if domain == "bad.com" {
+		return nil, fmt.Errorf("server error")
+	}
+	rc := &models.RecordConfig{Type: "A", TTL: 300}
+	rc.SetLabel("www", domain)
+	rc.SetTarget("192.0.2.1")
+	if domain == "c.com" {
+		rc.Metadata = map[string]string{"gcore_health": "unhealthy", "example": "1"}
+	}
+	return models.Records{rc}, nil
+}
+
+func (partialProvider) GetZoneMetadata(domain string) (map[string]string, error) {
+	if domain != "c.com" {
+		return nil, nil
+	}
+	return map[string]string{"dnssec": "true", "nsec3_iterations": "0", "nsec3_salt": "ab"}, nil
+}
+
+func init() {
+	providers.RegisterDomainServiceProviderType("GZ_PARTIAL", providers.DspFuncs{
+		Initializer: func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+			return partialProvider{}, nil
+		},
+	})
+}
+
+func TestGetZoneAllPartialFailure(t *testing.T) {
+	dir := t.TempDir()
+	credsFile := filepath.Join(dir, "creds.json")
+	if err := os.WriteFile(credsFile, []byte(`{"partial": {"TYPE": "GZ_PARTIAL"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	outFile := filepath.Join(dir, "out.tsv")
+
+	gzargs := GetZoneArgs{
+		ZoneNames:    []string{"all"},
+		OutputFormat: "tsv",
+		OutputFile:   outFile,
+		CredName:     "partial",
+		ProviderName: "-",
+	}
+	gzargs.CredsFile = credsFile
+
+	err := GetZone(gzargs)
+	if err == nil || !strings.Contains(err.Error(), "bad.com") {
+		t.Errorf("expected an error naming bad.com, got %v", err)
+	}
+
+	got, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, zone := range []string{"a.com", "c.com"} {
+		if !strings.Contains(string(got), "www."+zone) {
+			t.Errorf("output is missing zone %s:\n%s", zone, got)
+		}
+	}
+}
+
+func TestGetZoneMeta(t *testing.T) {
+	dir := t.TempDir()
+	credsFile := filepath.Join(dir, "creds.json")
+	if err := os.WriteFile(credsFile, []byte(`{"partial": {"TYPE": "GZ_PARTIAL"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, meta := range []bool{false, true} {
+		outFile := filepath.Join(dir, "out.tsv")
+		gzargs := GetZoneArgs{
+			ZoneNames:    []string{"c.com"},
+			OutputFormat: "tsv",
+			OutputFile:   outFile,
+			CredName:     "partial",
+			ProviderName: "-",
+			ShowMeta:     meta,
+		}
+		gzargs.CredsFile = credsFile
+		if err := GetZone(gzargs); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := os.ReadFile(outFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp := "www.c.com\twww\t300\tIN\tA\t192.0.2.1\n"
+		if meta {
+			exp = "# c.com\tdnssec=true,nsec3_iterations=0,nsec3_salt=ab\n" +
+				"www.c.com\twww\t300\tIN\tA\t192.0.2.1\texample=1,gcore_health=unhealthy\n"
+		}
+		if string(got) != exp {
+			t.Errorf("meta=%v: got %q, expected %q", meta, got, exp)
+		}
+	}
+}
+
+// concurrentProvider is a ZoneLister which records how many
+// GetZoneRecords calls are in progress at once.
+type concurrentProvider struct {
+	inFlight, maxInFlight int32
+}
+
+var concurrentTestProvider = &concurrentProvider{}
+
+func (*concurrentProvider) GetNameservers(string) ([]*models.Nameserver, error) { return nil, nil }
+func (*concurrentProvider) GetDomainCorrections(*models.DomainConfig) ([]*models.Correction, error) {
+	return nil, nil
+}
+func (*concurrentProvider) ListZones() ([]string, error) {
+	var zones []string
+	for i := 0; i < 20; i++ {
+		zones = append(zones, fmt.Sprintf("zone%02d.com", i))
+	}
+	return zones, nil
+}
+func (p *concurrentProvider) GetZoneRecords(domain string) (models.Records, error) {
+	n := atomic.AddInt32(&p.inFlight, 1)
+	defer atomic.AddInt32(&p.inFlight, -1)
+	for {
+		max := atomic.LoadInt32(&p.maxInFlight)
+		if n <= max || atomic.CompareAndSwapInt32(&p.maxInFlight, max, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	rc := &models.RecordConfig{Type: "A", TTL: 300}
+	rc.SetLabel("www", domain)
+	rc.SetTarget("192.0.2.1")
+	return models.Records{rc}, nil
+}
+
+func init() {
+	providers.RegisterDomainServiceProviderType("GZ_CONCURRENT", providers.DspFuncs{
+		Initializer: func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+			return concurrentTestProvider, nil
+		},
+	})
+}
+
+func TestGetZoneAllConcurrency(t *testing.T) {
+	dir := t.TempDir()
+	credsFile := filepath.Join(dir, "creds.json")
+	if err := os.WriteFile(credsFile, []byte(`{"concurrent": {"TYPE": "GZ_CONCURRENT"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	outFile := filepath.Join(dir, "out.tsv")
+
+	gzargs := GetZoneArgs{
+		ZoneNames:    []string{"all"},
+		OutputFormat: "tsv",
+		OutputFile:   outFile,
+		CredName:     "concurrent",
+		ProviderName: "-",
+		Concurrency:  4,
+	}
+	gzargs.CredsFile = credsFile
+
+	if err := GetZone(gzargs); err != nil {
+		t.Fatal(err)
+	}
+	if max := atomic.LoadInt32(&concurrentTestProvider.maxInFlight); max > 4 {
+		t.Errorf("%d zones were read at once, expected at most 4", max)
+	}
+
+	got, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	zones, _ := concurrentTestProvider.ListZones()
+	if len(lines) != len(zones) {
+		t.Fatalf("got %d records, expected %d:\n%s", len(lines), len(zones), got)
+	}
+	for i, zone := range zones {
+		if !strings.HasPrefix(lines[i], "www."+zone+"\t") {
+			t.Errorf("line %d is %q, expected zone %s", i, lines[i], zone)
+		}
+	}
+}
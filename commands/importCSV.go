@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/StackExchange/dnscontrol/v3/pkg/csvimport"
+	"github.com/StackExchange/dnscontrol/v3/pkg/prettyzone"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args ImportCSVArgs
+	return &cli.Command{
+		Name:  "import-csv",
+		Usage: "converts a CSV file of records to dnsconfig.js (stand-alone)",
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() != 2 {
+				return cli.Exit("Arguments should be: zone csvfile (Ex: example.com records.csv)", 1)
+			}
+			args.ZoneName = ctx.Args().Get(0)
+			args.InputFile = ctx.Args().Get(1)
+			return exit(ImportCSV(args))
+		},
+		Flags:     args.flags(),
+		UsageText: "dnscontrol import-csv [command options] zone csvfile",
+		Description: `Convert a CSV file of records, such as one exported from a spreadsheet,
+to a draft dnsconfig.js. This is a stand-alone utility.
+
+The first row names the columns, in any order:
+   name      The label ("@" or empty for the apex), or a FQDN ending with "."
+   type      The record type (A, MX, TXT, ...)
+   ttl       The TTL (optional)
+   value     The target; for TXT the text itself, without quotes
+   priority  The MX preference or SRV priority (optional)
+   weight    The SRV weight (optional)
+   port      The SRV port (optional)
+
+EXAMPLES:
+   dnscontrol import-csv example.com records.csv
+   dnscontrol import-csv --format=djs --out=draft.js example.com records.csv`,
+	}
+}())
+
+// ImportCSVArgs contains all data/flags needed to run import-csv, independently of CLI.
+type ImportCSVArgs struct {
+	ZoneName     string // The zone the records belong to
+	InputFile    string // The CSV file to read
+	OutputFormat string // Output format
+	OutputFile   string // Filename to send output ("" means stdout)
+	DefaultTTL   int    // DefaultTTL() of the generated D()
+}
+
+func (args *ImportCSVArgs) flags() []cli.Flag {
+	var flags []cli.Flag
+	flags = append(flags, &cli.StringFlag{
+		Name:        "format",
+		Destination: &args.OutputFormat,
+		Value:       "js",
+		Usage:       `Output format: js djs`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "out",
+		Destination: &args.OutputFile,
+		Usage:       `Instead of stdout, write to this file`,
+	})
+	flags = append(flags, &cli.IntFlag{
+		Name:        "ttl",
+		Destination: &args.DefaultTTL,
+		Usage:       `Default TTL (0 picks the most common TTL)`,
+	})
+	return flags
+}
+
+// ImportCSV implements the import-csv subcommand.
+func ImportCSV(args ImportCSVArgs) error {
+	if args.OutputFormat != "js" && args.OutputFormat != "djs" {
+		return fmt.Errorf("format %q unknown", args.OutputFormat)
+	}
+
+	in, err := os.Open(args.InputFile)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	recs, err := csvimport.ParseRecords(in, args.ZoneName)
+	if err != nil {
+		return fmt.Errorf("%s: %w", args.InputFile, err)
+	}
+
+	w := os.Stdout
+	if args.OutputFile != "" {
+		if w, err = os.Create(args.OutputFile); err != nil {
+			return err
+		}
+		defer w.Close()
+	}
+
+	z := prettyzone.PrettySort(recs, args.ZoneName, 0, nil)
+	fmt.Fprintf(w, `var DSP_CHANGEME = NewDnsProvider("changeme");`+"\n")
+	fmt.Fprintf(w, `var REG_CHANGEME = NewRegistrar("none");`+"\n")
+	writeDomainJS(w, args.OutputFormat, args.ZoneName, "DSP_CHANGEME", z.Records, uint32(args.DefaultTTL))
+	return nil
+}
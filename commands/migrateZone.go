@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/StackExchange/dnscontrol/v3/pkg/credsfile"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+	"github.com/urfave/cli/v2"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args MigrateZoneArgs
+	return &cli.Command{
+		Name:  "migrate-zone",
+		Usage: "copies a zone's records to a zone with a new name (stand-alone)",
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() != 3 {
+				return cli.Exit("Arguments should be: credskey oldzone newzone (Ex: gcore example.com example.net)", 1)
+			}
+			args.CredName = ctx.Args().Get(0)
+			args.OldZone = ctx.Args().Get(1)
+			args.NewZone = ctx.Args().Get(2)
+			return exit(MigrateZone(args))
+		},
+		Flags:     args.flags(),
+		UsageText: "dnscontrol migrate-zone [command options] credkey oldzone newzone",
+		Description: `Create the zone newzone at a provider with the records of oldzone, for
+when a zone is renamed. Targets in oldzone (such as a CNAME to
+www.oldzone.) are changed to the same names in newzone. newzone must not
+exist yet. This is a stand-alone utility; update dnsconfig.js to the new
+name afterwards.
+
+Only some providers support this (GCORE).
+
+EXAMPLES:
+   dnscontrol migrate-zone gcore example.com example.net
+   dnscontrol migrate-zone --delete-old gcore example.com example.net`,
+	}
+}())
+
+// MigrateZoneArgs contains all data/flags needed to run migrate-zone, independently of CLI.
+type MigrateZoneArgs struct {
+	GetCredentialsArgs        // Args related to creds.json
+	CredName           string // key in creds.json
+	OldZone            string // The zone to copy the records of
+	NewZone            string // The zone to create
+	DeleteOld          bool   // Delete OldZone once its records are copied
+}
+
+func (args *MigrateZoneArgs) flags() []cli.Flag {
+	flags := args.GetCredentialsArgs.flags()
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "delete-old",
+		Destination: &args.DeleteOld,
+		Usage:       `Delete the old zone once its records are copied`,
+	})
+	return flags
+}
+
+// MigrateZone implements the migrate-zone subcommand.
+func MigrateZone(args MigrateZoneArgs) error {
+	providerConfigs, err := credsfile.LoadProviderConfigs(args.CredsFile)
+	if err != nil {
+		return fmt.Errorf("failed MigrateZone LoadProviderConfigs(%q): %w", args.CredsFile, err)
+	}
+	provider, err := providers.CreateDNSProvider("-", providerConfigs[args.CredName], nil)
+	if err != nil {
+		return fmt.Errorf("failed MigrateZone CDP: %w", err)
+	}
+	migrator, ok := provider.(providers.ZoneMigrator)
+	if !ok {
+		return fmt.Errorf("provider %s (%s) cannot migrate zones", args.CredName, providerConfigs[args.CredName]["TYPE"])
+	}
+	if err := migrator.MigrateZone(args.OldZone, args.NewZone, args.DeleteOld); err != nil {
+		return err
+	}
+	fmt.Printf("Copied the records of %s to %s\n", args.OldZone, args.NewZone)
+	if args.DeleteOld {
+		fmt.Printf("Deleted %s\n", args.OldZone)
+	}
+	return nil
+}
@@ -1,13 +1,19 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
 	"github.com/StackExchange/dnscontrol/v3/pkg/credsfile"
+	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
 	"github.com/StackExchange/dnscontrol/v3/pkg/nameservers"
 	"github.com/StackExchange/dnscontrol/v3/pkg/normalize"
 	"github.com/StackExchange/dnscontrol/v3/pkg/notifications"
@@ -38,6 +44,20 @@ type PreviewArgs struct {
 	WarnChanges bool
 	NoPopulate  bool
 	Full        bool
+	EmitScript  string
+	Diagnostics bool
+	ShowAll     bool
+	Explain     bool
+	CompareOnly bool
+
+	ContinueOnError bool
+	GroupByDomain   bool
+	TTLOverride     int
+	ParallelDomains int
+	Timeout         time.Duration
+	FromSnapshot    string
+
+	snapshot map[string]models.Records // read from FromSnapshot by run
 }
 
 func (args *PreviewArgs) flags() []cli.Flag {
@@ -64,6 +84,61 @@ func (args *PreviewArgs) flags() []cli.Flag {
 		Destination: &args.Full,
 		Usage:       `Add headings, providers names, notifications of no changes, etc`,
 	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "emit-script",
+		Destination: &args.EmitScript,
+		Usage:       `Instead of making the corrections, write them to this file as a shell script of API calls`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "diagnostics",
+		Destination: &args.Diagnostics,
+		Usage:       `After the run, print each provider's API usage, such as the requests made and the remaining quota`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "show-all",
+		Destination: &args.ShowAll,
+		Usage:       `Also list the records that are already as desired, marked as NO-OP`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "explain",
+		Destination: &args.Explain,
+		Usage:       `Before the corrections, explain why each record is created, updated, deleted or kept`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "compare-only",
+		Destination: &args.CompareOnly,
+		Usage:       `Only compare the providers with the configuration, and exit with status 2 if there are corrections. Providers that support it are made read-only`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "continue-on-error",
+		Destination: &args.ContinueOnError,
+		Usage:       `Continue with the other domains when one fails (such as when its zone can't be read), and list the failures at the end`,
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "group-by-domain",
+		Destination: &args.GroupByDomain,
+		Usage:       `Print each domain's output as an indented group, under a header with its number of corrections`,
+	})
+	flags = append(flags, &cli.IntFlag{
+		Name:        "ttl-override",
+		Destination: &args.TTLOverride,
+		Usage:       `Use this TTL for every record, instead of the TTLs in dnsconfig.js (0 means no override)`,
+	})
+	flags = append(flags, &cli.IntFlag{
+		Name:        "parallel-domains",
+		Destination: &args.ParallelDomains,
+		Usage:       `Read this many domains at once, if their providers support it (CanConcur); they are still printed and pushed one at a time`,
+	})
+	flags = append(flags, &cli.DurationFlag{
+		Name:        "timeout",
+		Destination: &args.Timeout,
+		Usage:       `Stop the run after this long (such as 10m), cancelling the API requests of providers that support it, and list the domains that were completed (0 means no limit)`,
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "from-snapshot",
+		Destination: &args.FromSnapshot,
+		Usage:       `Compare the configuration with the records in this file, written by get-zones --format=json, instead of reading them from the providers, which aren't contacted and need no credentials`,
+	})
 	return flags
 }
 
@@ -83,6 +158,11 @@ var _ = cmd(catMain, func() *cli.Command {
 type PushArgs struct {
 	PreviewArgs
 	Interactive bool
+	Verify      bool
+	MaxChanges  int
+	Force       bool
+	Changelog   string
+	Freeze      string
 }
 
 func (args *PushArgs) flags() []cli.Flag {
@@ -92,106 +172,274 @@ func (args *PushArgs) flags() []cli.Flag {
 		Destination: &args.Interactive,
 		Usage:       "Interactive. Confirm or Exclude each correction before they run",
 	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "verify",
+		Destination: &args.Verify,
+		Usage:       "After pushing, re-read the changed records to check they were applied (if the provider supports it)",
+	})
+	flags = append(flags, &cli.IntFlag{
+		Name:        "max-changes",
+		Destination: &args.MaxChanges,
+		Usage:       "Don't push a domain's corrections at a provider if there are more than this many (0 means no limit)",
+	})
+	flags = append(flags, &cli.BoolFlag{
+		Name:        "force",
+		Destination: &args.Force,
+		Usage:       "Push even if there are more corrections than --max-changes",
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "changelog",
+		Destination: &args.Changelog,
+		Usage:       "Append a JSON line for each change pushed (or that failed) to this file",
+	})
+	flags = append(flags, &cli.StringFlag{
+		Name:        "freeze",
+		Destination: &args.Freeze,
+		EnvVars:     []string{"DNSCONTROL_FREEZE"},
+		Usage:       "Refuse to push: \"true\", or during these comma-separated windows, each START/END in RFC 3339 (such as 2026-12-20T00:00:00Z/2027-01-04T00:00:00Z). Preview still works",
+	})
 	return flags
 }
 
+// errDrift is returned by preview --compare-only if there are
+// corrections. The command exits with status 2 for it, instead of 1.
+var errDrift = errors.New("the providers don't match the configuration")
+
+// tooManyChanges reports whether n corrections are more than
+// --max-changes allows, and if so prints why they won't be pushed.
+func (args *PushArgs) tooManyChanges(n int, out printer.CLI) bool {
+	if args.MaxChanges <= 0 || n <= args.MaxChanges || args.Force {
+		return false
+	}
+	out.Errorf("%d corrections is more than --max-changes=%d, not pushing them (use --force to override)\n", n, args.MaxChanges)
+	return true
+}
+
 // Preview implements the preview subcommand.
 func Preview(args PreviewArgs) error {
-	return run(args, false, false, printer.DefaultPrinter)
+	return run(PushArgs{PreviewArgs: args}, false, printer.DefaultPrinter)
 }
 
 // Push implements the push subcommand.
 func Push(args PushArgs) error {
-	return run(args.PreviewArgs, true, args.Interactive, printer.DefaultPrinter)
+	return run(args, true, printer.DefaultPrinter)
 }
 
-// run is the main routine common to preview/push
-func run(args PreviewArgs, push bool, interactive bool, out printer.CLI) error {
+// run is the main routine common to preview/push. The push-only
+// fields of args are ignored unless push is set.
+func run(args PushArgs, push bool, out printer.CLI) error {
 	// TODO: make truly CLI independent. Perhaps return results on a channel as they occur
 
 	// This is a hack until we have the new printer replacement.
 	printer.SkinnyReport = !args.Full
 
+	if push {
+		reason, err := frozen(args.Freeze, time.Now())
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return fmt.Errorf("push refused: %s (--freeze); preview still works", reason)
+		}
+	}
+
+	if args.GroupByDomain {
+		grouped := printer.NewGroupedPrinter(out)
+		defer grouped.Flush() // if the run is aborted
+		out = grouped
+	}
+
+	if args.FromSnapshot != "" {
+		switch {
+		case push:
+			return fmt.Errorf("--from-snapshot only works with preview")
+		case args.Explain, args.ShowAll:
+			return fmt.Errorf("--from-snapshot can't be used with --explain or --show-all, which read the providers")
+		}
+		snapshot, err := readSnapshot(args.FromSnapshot)
+		if err != nil {
+			return err
+		}
+		args.snapshot = snapshot
+	}
+
 	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
 	if err != nil {
 		return err
 	}
+	args.filterDomains(cfg)
 	providerConfigs, err := credsfile.LoadProviderConfigs(args.CredsFile)
 	if err != nil {
 		return err
 	}
+	if args.snapshot != nil {
+		for _, p := range cfg.DNSProviders {
+			if providerConfigs[p.Name] == nil {
+				providerConfigs[p.Name] = map[string]string{}
+			}
+			providerConfigs[p.Name]["_from_snapshot"] = "true"
+		}
+	}
 	notifier, err := InitializeProviders(cfg, providerConfigs, args.Notify)
 	if err != nil {
 		return err
 	}
 
+	// Before validation, so that the overridden TTLs are checked.
+	if args.TTLOverride < 0 {
+		return fmt.Errorf("--ttl-override must not be negative")
+	} else if args.TTLOverride != 0 {
+		normalize.OverrideTTLs(cfg, uint32(args.TTLOverride))
+	}
 	errs := normalize.ValidateAndNormalizeConfig(cfg)
 	if PrintValidationErrors(errs) {
 		return fmt.Errorf("exiting due to validation errors")
 	}
+	var script *scriptWriter
+	if args.EmitScript != "" {
+		f, err := os.OpenFile(args.EmitScript, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		script = newScriptWriter(f)
+		push = false // the script is run instead
+	}
+	if args.CompareOnly {
+		push = false
+		for _, domain := range cfg.Domains {
+			for _, provider := range domain.DNSProviderInstances {
+				if s, ok := provider.Driver.(providers.CompareOnlySetter); ok {
+					s.SetCompareOnly()
+				}
+			}
+		}
+	}
 	anyErrors := false
 	totalCorrections := 0
+	var summary correctionSummary
+	if args.Changelog != "" && push {
+		f, err := os.OpenFile(args.Changelog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		summary.changelog = newChangelogWriter(f)
+	}
+	var domainErrors []string // by --continue-on-error
+	// domainFailed handles an error that stops domain from being
+	// processed. The run is aborted unless --continue-on-error is set.
+	domainFailed := func(domain string, err error) error {
+		anyErrors = true
+		if !args.ContinueOnError {
+			return err
+		}
+		out.Errorf("%s: %s\n", domain, err)
+		domainErrors = append(domainErrors, fmt.Sprintf("%s: %s", domain, err))
+		return nil
+	}
+	// With --timeout, the providers that support it are cancelled at the
+	// deadline, and the domains after it aren't started.
+	ctx := context.Background()
+	if args.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, args.Timeout)
+		defer cancel()
+		setContext(ctx, cfg)
+	}
+	var started, completed []string
+	// finishDomain counts the domain last started as completed, unless
+	// the deadline passed while it ran.
+	finishDomain := func() {
+		if len(started) > len(completed) && ctx.Err() == nil {
+			completed = append(completed, started[len(started)-1])
+		}
+	}
+	// The domains that can be read in parallel are read first.
+	gathered := args.gatherDomains(cfg, push)
 DomainLoop:
 	for _, domain := range cfg.Domains {
 		if !args.shouldRunDomain(domain.UniqueName) {
 			continue
 		}
+		finishDomain()
+		if ctx.Err() != nil {
+			break
+		}
+		started = append(started, domain.UniqueName)
 		out.StartDomain(domain.UniqueName)
-		var providersWithExistingZone []*models.DNSProviderInstance
-		for _, provider := range domain.DNSProviderInstances {
-
-			if !args.NoPopulate {
-				// preview run: check if zone is already there, if not print a warning
-				if lister, ok := provider.Driver.(providers.ZoneLister); ok && !push {
-					zones, err := lister.ListZones()
-					if err != nil {
-						return err
-					}
-					if !slices.Contains(zones, domain.Name) {
-						out.Warnf("Domain '%s' does not exist in the '%s' profile and will be added automatically.\n", domain.Name, provider.Name)
-						continue // continue with next provider, as we can not determine corrections without an existing zone
-					}
-				} else if creator, ok := provider.Driver.(providers.DomainCreator); ok && push {
-					// this is the actual push, ensure domain exists at DSP
-					if err := creator.EnsureDomainExists(domain.Name); err != nil {
-						out.Warnf("Error creating domain: %s\n", err)
-						continue // continue with next provider, as we couldn't create this one
-					}
-				}
-			}
-			providersWithExistingZone = append(providersWithExistingZone, provider)
+		g := gathered[domain]
+		if g == nil {
+			g = args.gatherDomain(domain, push)
 		}
-
-		nsList, err := nameservers.DetermineNameserversForProviders(domain, providersWithExistingZone)
-		if err != nil {
-			return err
+		for _, w := range g.warnings {
+			out.Warnf("%s", w)
 		}
-		domain.Nameservers = nsList
-		nameservers.AddNSRecords(domain)
-
-		for _, provider := range providersWithExistingZone {
-			dc, err := domain.Copy()
-			if err != nil {
+		if g.fatal != nil {
+			return g.fatal
+		}
+		if g.err != nil {
+			if err := domainFailed(domain.UniqueName, g.err); err != nil {
 				return err
 			}
-			shouldrun := args.shouldRunProvider(provider.Name, dc)
-			out.StartDNSProvider(provider.Name, !shouldrun)
-			if !shouldrun {
+			continue
+		}
+
+		for _, pc := range g.providers {
+			provider := pc.provider
+			out.StartDNSProvider(provider.Name, pc.skip)
+			if pc.skip {
 				continue
 			}
 
-			/// This is where we should audit?
-
-			corrections, err := provider.Driver.GetDomainCorrections(dc)
+			corrections, err := pc.corrections, pc.err
 			out.EndProvider(len(corrections), err)
 			if err != nil {
+				// This has always continued with the next domain, so
+				// --continue-on-error only adds it to the list.
 				anyErrors = true
+				if args.ContinueOnError {
+					domainErrors = append(domainErrors, fmt.Sprintf("%s (%s): %s", domain.UniqueName, provider.Name, err))
+				}
 				continue DomainLoop
 			}
 			totalCorrections += len(corrections)
-			anyErrors = printOrRunCorrections(domain.Name, provider.Name, corrections, out, push, interactive, notifier) || anyErrors
+			if push && args.tooManyChanges(len(corrections), out) {
+				anyErrors = true
+				continue
+			}
+			if args.Explain {
+				if err := printExplanations(domain, provider.Driver, out); err != nil {
+					out.Errorf("Explaining the changes failed: %s\n", err)
+					anyErrors = true
+				}
+			}
+			var unchanged diff.Changeset
+			if args.ShowAll {
+				if unchanged, err = findUnchanged(domain, provider.Driver); err != nil {
+					out.Errorf("Listing unchanged records failed: %s\n", err)
+					anyErrors = true
+				}
+			}
+			anyErrors = printOrRunCorrections(domain.Name, provider.Name, corrections, out, push, args.Interactive, notifier, &summary) || anyErrors
+			printUnchanged(unchanged, out)
+			if v, ok := provider.Driver.(providers.PushVerifier); ok && push && args.Verify {
+				mismatches, err := v.VerifyPush(domain.Name)
+				if err != nil {
+					out.Errorf("Verify failed: %s\n", err)
+					anyErrors = true
+				}
+				for _, m := range mismatches {
+					out.Warnf("Verify: %s\n", m)
+					anyErrors = true
+				}
+			}
+			if script != nil {
+				script.Add(domain.Name, provider.Name, corrections)
+			}
 		}
-		run := args.shouldRunProvider(domain.RegistrarName, domain)
+		// A snapshot only has the zones' records, not the registrars'.
+		run := args.shouldRunProvider(domain.RegistrarName, domain) && args.snapshot == nil
 		out.StartRegistrar(domain.RegistrarName, !run)
 		if !run {
 			continue
@@ -211,22 +459,251 @@ DomainLoop:
 			continue
 		}
 		totalCorrections += len(corrections)
-		anyErrors = printOrRunCorrections(domain.Name, domain.RegistrarName, corrections, out, push, interactive, notifier) || anyErrors
+		if push && args.tooManyChanges(len(corrections), out) {
+			anyErrors = true
+			continue
+		}
+		anyErrors = printOrRunCorrections(domain.Name, domain.RegistrarName, corrections, out, push, args.Interactive, notifier, &summary) || anyErrors
+		if script != nil {
+			script.Add(domain.Name, domain.RegistrarName, corrections)
+		}
+	}
+	finishDomain()
+	if grouped, ok := out.(*printer.GroupedPrinter); ok {
+		grouped.Flush()
 	}
 	if os.Getenv("TEAMCITY_VERSION") != "" {
 		fmt.Fprintf(os.Stderr, "##teamcity[buildStatus status='SUCCESS' text='%d corrections']", totalCorrections)
 	}
 	notifier.Done()
 	out.Printf("Done. %d corrections.\n", totalCorrections)
+	if script != nil {
+		if err := script.Flush(); err != nil {
+			return err
+		}
+		out.Printf("Wrote the corrections to %s.\n", args.EmitScript)
+	}
+	if push && totalCorrections != 0 {
+		summary.Print(out)
+	}
+	if len(domainErrors) != 0 {
+		out.Printf("Failed domains: %d.\n", len(domainErrors))
+		for _, e := range domainErrors {
+			out.Printf("FAILED %s\n", e)
+		}
+	}
+	if args.Diagnostics {
+		printDiagnostics(cfg, out)
+	}
+	if ctx.Err() != nil {
+		printTimeout(cfg, args, completed, out)
+		return fmt.Errorf("timed out after %s", args.Timeout)
+	}
 	if anyErrors {
 		return fmt.Errorf("completed with errors")
 	}
+	if totalCorrections != 0 && args.CompareOnly {
+		return errDrift
+	}
 	if totalCorrections != 0 && args.WarnChanges {
 		return fmt.Errorf("there are pending changes")
 	}
 	return nil
 }
 
+// setContext makes the providers of cfg that support it make their API
+// requests with ctx.
+func setContext(ctx context.Context, cfg *models.DNSConfig) {
+	for _, domain := range cfg.Domains {
+		for _, provider := range domain.DNSProviderInstances {
+			if s, ok := provider.Driver.(providers.ContextSetter); ok {
+				s.SetContext(ctx)
+			}
+		}
+		if domain.RegistrarInstance != nil {
+			if s, ok := domain.RegistrarInstance.Driver.(providers.ContextSetter); ok {
+				s.SetContext(ctx)
+			}
+		}
+	}
+}
+
+// printTimeout lists which of the domains of the run were completed
+// before --timeout stopped it.
+func printTimeout(cfg *models.DNSConfig, args PushArgs, completed []string, out printer.CLI) {
+	var lines []string
+	for _, domain := range cfg.Domains {
+		if !args.shouldRunDomain(domain.UniqueName) {
+			continue
+		}
+		if slices.Contains(completed, domain.UniqueName) {
+			lines = append(lines, "COMPLETED "+domain.UniqueName)
+		} else {
+			lines = append(lines, "NOT COMPLETED "+domain.UniqueName)
+		}
+	}
+	out.Errorf("Timed out after %s. Completed domains: %d of %d.\n", args.Timeout, len(completed), len(lines))
+	for _, line := range lines {
+		out.Printf("%s\n", line)
+	}
+}
+
+// gatheredDomain is what gatherDomain found for a domain, for run to
+// print and push.
+type gatheredDomain struct {
+	warnings  []string // printed after the domain's heading
+	err       error    // stops the domain from being processed
+	fatal     error    // stops the run
+	providers []gatheredProvider
+}
+
+// gatheredProvider is the corrections of one of a domain's DNS providers.
+type gatheredProvider struct {
+	provider    *models.DNSProviderInstance
+	skip        bool // not selected by --providers
+	corrections []*models.Correction
+	err         error
+}
+
+// gatherDomain checks that domain's zones exist (or creates them, if
+// push is set), determines its nameservers, and gets the corrections of
+// each of its DNS providers. Nothing is printed, so that domains can be
+// gathered in parallel. As when they were printed as they happened, a
+// provider that fails stops the providers after it.
+func (args *PushArgs) gatherDomain(domain *models.DomainConfig, push bool) *gatheredDomain {
+	g := &gatheredDomain{}
+	var providersWithExistingZone []*models.DNSProviderInstance
+	for _, provider := range domain.DNSProviderInstances {
+
+		if !args.NoPopulate && args.snapshot == nil {
+			// preview run: check if zone is already there, if not print a warning
+			if lister, ok := provider.Driver.(providers.ZoneLister); ok && !push {
+				zones, err := lister.ListZones()
+				if err != nil {
+					g.err = err
+					return g
+				}
+				if !slices.Contains(zones, domain.Name) {
+					g.warnings = append(g.warnings, fmt.Sprintf("Domain '%s' does not exist in the '%s' profile and will be added automatically.\n", domain.Name, provider.Name))
+					continue // continue with next provider, as we can not determine corrections without an existing zone
+				}
+			} else if creator, ok := provider.Driver.(providers.DomainCreator); ok && push {
+				// this is the actual push, ensure domain exists at DSP
+				if err := creator.EnsureDomainExists(domain.Name); err != nil {
+					g.warnings = append(g.warnings, fmt.Sprintf("Error creating domain: %s\n", err))
+					continue // continue with next provider, as we couldn't create this one
+				}
+			}
+		}
+		providersWithExistingZone = append(providersWithExistingZone, provider)
+	}
+
+	nsList, err := nameservers.DetermineNameserversForProviders(domain, providersWithExistingZone)
+	if err != nil {
+		g.err = err
+		return g
+	}
+	domain.Nameservers = nsList
+	nameservers.AddNSRecords(domain)
+
+	for _, provider := range providersWithExistingZone {
+		dc, err := domain.Copy()
+		if err != nil {
+			g.fatal = err
+			return g
+		}
+		pc := gatheredProvider{provider: provider}
+		if !args.shouldRunProvider(provider.Name, dc) {
+			pc.skip = true
+			g.providers = append(g.providers, pc)
+			continue
+		}
+
+		/// This is where we should audit?
+
+		if args.snapshot != nil {
+			pc.corrections, pc.err = snapshotCorrections(dc, provider, args.snapshot)
+		} else {
+			pc.corrections, pc.err = provider.Driver.GetDomainCorrections(dc)
+		}
+		if pc.err == nil {
+			// Corrections may have to run after others (see After).
+			pc.corrections, pc.err = models.OrderCorrections(pc.corrections)
+		}
+		g.providers = append(g.providers, pc)
+		if pc.err != nil {
+			break
+		}
+	}
+	return g
+}
+
+// snapshotCorrections returns the corrections of provider for dc against
+// the records of dc's zone in snapshot, for preview --from-snapshot.
+func snapshotCorrections(dc *models.DomainConfig, provider *models.DNSProviderInstance, snapshot map[string]models.Records) ([]*models.Correction, error) {
+	s, ok := provider.Driver.(providers.SnapshotCorrector)
+	if !ok {
+		return nil, fmt.Errorf("%s can't be previewed from a snapshot", provider.Name)
+	}
+	existing, ok := snapshot[dc.Name]
+	if !ok {
+		return nil, fmt.Errorf("%s isn't in the snapshot", dc.Name)
+	}
+	// The snapshot is shared by the domain's providers.
+	copied := make(models.Records, len(existing))
+	for i, rc := range existing {
+		var err error
+		if copied[i], err = rc.Copy(); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetSnapshotCorrections(dc, copied)
+}
+
+// gatherDomains gathers, --parallel-domains at a time, the domains whose
+// DNS providers can all be used concurrently (CanConcur). The others
+// are gathered by run when it reaches them.
+func (args *PushArgs) gatherDomains(cfg *models.DNSConfig, push bool) map[*models.DomainConfig]*gatheredDomain {
+	gathered := map[*models.DomainConfig]*gatheredDomain{}
+	if args.ParallelDomains <= 1 {
+		return gathered
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, args.ParallelDomains)
+	for _, domain := range cfg.Domains {
+		if !args.shouldRunDomain(domain.UniqueName) || !canConcur(domain) {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(domain *models.DomainConfig) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			g := args.gatherDomain(domain, push)
+			mu.Lock()
+			gathered[domain] = g
+			mu.Unlock()
+		}(domain)
+	}
+	wg.Wait()
+	return gathered
+}
+
+// canConcur reports whether all of domain's DNS providers can be used
+// concurrently.
+func canConcur(domain *models.DomainConfig) bool {
+	for _, provider := range domain.DNSProviderInstances {
+		if !providers.ProviderHasCapability(provider.ProviderType, providers.CanConcur) {
+			return false
+		}
+	}
+	return true
+}
+
 // InitializeProviders takes (fully processed) configuration and instantiates all providers and returns them.
 func InitializeProviders(cfg *models.DNSConfig, providerConfigs map[string]map[string]string, notifyFlag bool) (notify notifications.Notifier, err error) {
 	var notificationCfg map[string]string
@@ -256,6 +733,7 @@ func InitializeProviders(cfg *models.DNSConfig, providerConfigs map[string]map[s
 
 	registrars := map[string]providers.Registrar{}
 	dnsProviders := map[string]providers.DNSServiceProvider{}
+	discoveredCaps := map[string]string{}
 	for _, d := range cfg.Domains {
 		if registrars[d.RegistrarName] == nil {
 			rCfg := cfg.RegistrarsByName[d.RegistrarName]
@@ -275,14 +753,36 @@ func InitializeProviders(cfg *models.DNSConfig, providerConfigs map[string]map[s
 					return nil, err
 				}
 				dnsProviders[pInst.Name] = prov
+				discoveredCaps[pInst.Name] = discoverCapabilities(pInst.Name, prov)
 			}
 			pInst.Driver = dnsProviders[pInst.Name]
 			pInst.IsDefault = !isNonDefault[pInst.Name]
+			// add "_capabilities":"CanAutoDNSSEC=true" to a provider to
+			// enable (or disable) capabilities for that provider.
+			pInst.CapabilityOverrides = providerConfigs[pInst.Name]["_capabilities"]
+			pInst.DiscoveredCaps = discoveredCaps[pInst.Name]
 		}
 	}
 	return
 }
 
+// discoverCapabilities returns the capabilities of prov's account as
+// capability overrides, or "" if the provider can't discover them. A
+// failure is only a warning, since the provider's advertised
+// capabilities are still usable.
+func discoverCapabilities(name string, prov providers.DNSServiceProvider) string {
+	d, ok := prov.(providers.CapabilityDiscoverer)
+	if !ok {
+		return ""
+	}
+	caps, err := d.DiscoverCapabilities()
+	if err != nil {
+		printer.Warnf("%s: could not discover the account's capabilities, using the defaults: %s\n", name, err)
+		return ""
+	}
+	return providers.FormatCapabilityOverrides(caps)
+}
+
 // providerTypeFieldName is the name of the field in creds.json that specifies the provider type id.
 const providerTypeFieldName = "TYPE"
 
@@ -477,7 +977,66 @@ func refineProviderType(credEntryName string, t string, credFields map[string]st
 
 }
 
-func printOrRunCorrections(domain string, provider string, corrections []*models.Correction, out printer.CLI, push bool, interactive bool, notifier notifications.Notifier) (anyErrors bool) {
+// diffZone returns the diff of domain with the provider's zone. For a
+// providers.DiffRecorder, it is the diff that the provider's corrections
+// were computed from, so it is called after they are computed. Other
+// providers' zones are read again and compared with the generic diff.
+func diffZone(domain *models.DomainConfig, driver models.DNSProvider) (unchanged, create, toDelete, modify diff.Changeset, err error) {
+	if r, ok := driver.(providers.DiffRecorder); ok {
+		if unchanged, create, toDelete, modify, ok = r.LastDiff(domain.Name); !ok {
+			return nil, nil, nil, nil, fmt.Errorf("%s has no corrections to compare with", domain.Name)
+		}
+		return unchanged, create, toDelete, modify, nil
+	}
+	existing, err := driver.GetZoneRecords(domain.Name)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	models.PostProcessRecords(existing)
+	dc, err := domain.Copy()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return diff.New(dc).IncrementalDiff(existing)
+}
+
+// findUnchanged returns the records of domain that the provider already
+// has as desired. It is called before the corrections run, so that the
+// records they change aren't listed.
+func findUnchanged(domain *models.DomainConfig, driver models.DNSProvider) (diff.Changeset, error) {
+	unchanged, _, _, _, err := diffZone(domain, driver)
+	return unchanged, err
+}
+
+// printUnchanged prints the unchanged records, marked as no-ops, so that
+// the whole desired state can be checked alongside the corrections.
+func printUnchanged(unchanged diff.Changeset, out printer.CLI) {
+	for _, c := range unchanged {
+		rec := c.Desired
+		out.Printf("NO-OP %s %s %s ttl=%d\n", rec.GetLabelFQDN(), rec.Type, rec.GetTargetCombined(), rec.TTL)
+	}
+}
+
+// printExplanations prints, for each record of domain and of the
+// provider's zone, what happens to it and why, as the provider's diff
+// sees it.
+func printExplanations(domain *models.DomainConfig, driver models.DNSProvider, out printer.CLI) error {
+	unchanged, create, toDelete, modify, err := diffZone(domain, driver)
+	if err != nil {
+		return err
+	}
+	all := append(append(append(create, toDelete...), modify...), unchanged...)
+	sort.SliceStable(all, func(i, j int) bool { return diff.ChangesetLess(all, i, j) })
+	for _, c := range all {
+		out.Printf("EXPLAIN %s\n", c.Explain())
+	}
+	return nil
+}
+
+// printOrRunCorrections prints the corrections and, if push is set,
+// runs them. A failed correction doesn't stop the following ones from
+// running; its result is recorded in summary instead.
+func printOrRunCorrections(domain string, provider string, corrections []*models.Correction, out printer.CLI, push bool, interactive bool, notifier notifications.Notifier, summary *correctionSummary) (anyErrors bool) {
 	anyErrors = false
 	if len(corrections) == 0 {
 		return false
@@ -486,7 +1045,10 @@ func printOrRunCorrections(domain string, provider string, corrections []*models
 		out.PrintCorrection(i, correction)
 		var err error
 		if push {
+			result := correctionResult{Domain: domain, Provider: provider, N: i + 1, Msg: correction.Msg}
 			if interactive && !out.PromptToRun() {
+				result.Skipped = true
+				summary.add(result)
 				continue
 			}
 			err = correction.F()
@@ -494,8 +1056,79 @@ func printOrRunCorrections(domain string, provider string, corrections []*models
 			if err != nil {
 				anyErrors = true
 			}
+			result.Err = err
+			summary.add(result)
 		}
 		notifier.Notify(domain, provider, correction.Msg, err, !push)
 	}
 	return anyErrors
 }
+
+// printDiagnostics prints the diagnostics of each provider that reports
+// them, in the order they are first used.
+func printDiagnostics(cfg *models.DNSConfig, out printer.Printer) {
+	seen := map[string]bool{}
+	for _, domain := range cfg.Domains {
+		for _, provider := range domain.DNSProviderInstances {
+			if seen[provider.Name] {
+				continue
+			}
+			seen[provider.Name] = true
+			d, ok := provider.Driver.(providers.DiagnosticsReporter)
+			if !ok {
+				continue
+			}
+			out.Printf("Diagnostics for %s:\n", provider.Name)
+			for _, line := range d.Diagnostics() {
+				out.Printf("    %s\n", line)
+			}
+		}
+	}
+}
+
+// correctionResult is the outcome of running a correction.
+type correctionResult struct {
+	Domain, Provider string
+	N                int // the number printed by PrintCorrection
+	Msg              string
+	Err              error // nil if it succeeded
+	Skipped          bool  // not run, because the user declined it
+}
+
+// correctionSummary collects the results of the corrections run by a
+// push, so that the failures can be listed together at the end.
+type correctionSummary struct {
+	results   []correctionResult
+	changelog *changelogWriter // nil unless --changelog is set
+}
+
+// add records the result of a correction.
+func (s *correctionSummary) add(r correctionResult) {
+	s.results = append(s.results, r)
+	if s.changelog != nil {
+		s.changelog.Add(r)
+	}
+}
+
+// Print writes the number of corrections that succeeded, failed and were
+// skipped, followed by each failure.
+func (s *correctionSummary) Print(out printer.Printer) {
+	var ok, failed, skipped int
+	for _, r := range s.results {
+		switch {
+		case r.Skipped:
+			skipped++
+		case r.Err != nil:
+			failed++
+		default:
+			ok++
+		}
+	}
+	out.Printf("Summary: %d succeeded, %d failed, %d skipped.\n", ok, failed, skipped)
+	for _, r := range s.results {
+		if r.Err != nil {
+			msg := strings.SplitN(r.Msg, "\n", 2)[0]
+			out.Printf("FAILED %s (%s) #%d: %s: %s\n", r.Domain, r.Provider, r.N, msg, r.Err)
+		}
+	}
+}
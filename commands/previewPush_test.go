@@ -1,8 +1,24 @@
 package commands
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
+	"github.com/StackExchange/dnscontrol/v3/pkg/notifications"
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+	"github.com/urfave/cli/v2"
 )
 
 func Test_refineProviderType(t *testing.T) {
@@ -56,3 +72,798 @@ func Test_refineProviderType(t *testing.T) {
 		})
 	}
 }
+
+// readsProvider records the zones whose corrections are computed.
+type readsProvider struct {
+	mu    sync.Mutex
+	zones []string
+}
+
+func (p *readsProvider) GetNameservers(string) ([]*models.Nameserver, error) { return nil, nil }
+func (p *readsProvider) GetZoneRecords(string) (models.Records, error)       { return nil, nil }
+func (p *readsProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.zones = append(p.zones, dc.Name)
+	return nil, nil
+}
+
+// The PP_FAKE provider type returns testProvider, and PP_FAKE_CONCUR,
+// which can be used concurrently, returns testConcurProvider.
+var (
+	testProvider       providers.DNSServiceProvider
+	testConcurProvider providers.DNSServiceProvider
+	testProviderNew    int               // number of providers created
+	testProviderCreds  map[string]string // of the provider created last
+)
+
+func init() {
+	fake := func(p *providers.DNSServiceProvider) providers.DspFuncs {
+		return providers.DspFuncs{
+			Initializer: func(m map[string]string, _ json.RawMessage) (providers.DNSServiceProvider, error) {
+				testProviderNew++
+				testProviderCreds = m
+				return *p, nil
+			},
+			RecordAuditor: func([]*models.RecordConfig) []error { return nil },
+		}
+	}
+	providers.RegisterDomainServiceProviderType("PP_FAKE", fake(&testProvider))
+	providers.RegisterDomainServiceProviderType("PP_FAKE_CONCUR", fake(&testConcurProvider),
+		providers.DocumentationNotes{providers.CanConcur: providers.Can()})
+}
+
+// testCreds is the creds.json that writeTestConfig writes by default.
+const testCreds = `{
+  "none": {"TYPE": "NONE"},
+  "fake": {"TYPE": "PP_FAKE"}
+}`
+
+// writeTestConfig writes js to dnsconfig.js and creds, or testCreds if
+// it is empty, to creds.json in a temporary directory. It returns the
+// arguments to run them without populating the zones.
+func writeTestConfig(t *testing.T, js, creds string) PushArgs {
+	t.Helper()
+	if creds == "" {
+		creds = testCreds
+	}
+	dir := t.TempDir()
+	var args PushArgs
+	args.JSFile = filepath.Join(dir, "dnsconfig.js")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+	args.NoPopulate = true
+	if err := os.WriteFile(args.JSFile, []byte(js), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(args.CredsFile, []byte(creds), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return args
+}
+
+func TestPreviewOnlyDomains(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake");
+var OTHER = NewDnsProvider("other");
+D("a.com", REG, DnsProvider(DSP), A("@", "192.0.2.1"));
+D("b.com", REG, DnsProvider(DSP), A("@", "192.0.2.2"));
+D("c.com", REG, DnsProvider(OTHER), A("@", "192.0.2.3"));
+`, `{
+  "none": {"TYPE": "NONE"},
+  "fake": {"TYPE": "PP_FAKE"},
+  "other": {"TYPE": "PP_FAKE"}
+}`)
+	args.Domains = "b.com"
+
+	reads := &readsProvider{}
+	testProvider, testProviderNew = reads, 0
+	if err := Preview(args.PreviewArgs); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(reads.zones, ","); got != "b.com" {
+		t.Errorf("read zones %q, expected only b.com", got)
+	}
+	// "other" is only used by c.com, so it isn't even set up.
+	if testProviderNew != 1 {
+		t.Errorf("created %d providers, expected 1", testProviderNew)
+	}
+}
+
+func TestPreviewOnlyDomainsImportTransform(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake");
+D("src.com", REG, DnsProvider(DSP), A("www", "192.0.2.1"));
+D("dst.com", REG, DnsProvider(DSP),
+  IMPORT_TRANSFORM([{low: "192.0.2.0", high: "192.0.2.255", newBase: "198.51.100.0"}], "src.com", 60));
+`, "")
+	args.Domains = "dst.com"
+
+	reads := &readsProvider{}
+	testProvider = reads
+	if err := Preview(args.PreviewArgs); err != nil {
+		t.Fatal(err)
+	}
+
+	// src.com is kept for IMPORT_TRANSFORM, but its zone isn't read.
+	if got := strings.Join(reads.zones, ","); got != "dst.com" {
+		t.Errorf("read zones %q, expected only dst.com", got)
+	}
+}
+
+func TestCorrectionSummary(t *testing.T) {
+	var ran []string
+	correction := func(msg string, err error) *models.Correction {
+		return &models.Correction{Msg: msg, F: func() error {
+			ran = append(ran, msg)
+			return err
+		}}
+	}
+	corrections := []*models.Correction{
+		correction("CREATE a", nil),
+		correction("CREATE b\nmore details", errors.New("server error")),
+		correction("CREATE c", nil),
+	}
+
+	var buf bytes.Buffer
+	out := printer.ConsolePrinter{Writer: &buf}
+	var summary correctionSummary
+	anyErrors := printOrRunCorrections("example.com", "fake", corrections, out, true, false, notifications.Init(nil), &summary)
+	if !anyErrors {
+		t.Error("expected anyErrors to be true")
+	}
+	// The failure doesn't stop the third correction.
+	if got := strings.Join(ran, ","); got != "CREATE a,CREATE b\nmore details,CREATE c" {
+		t.Errorf("ran %q, expected all three corrections", got)
+	}
+
+	buf.Reset()
+	summary.Print(out)
+	exp := "Summary: 2 succeeded, 1 failed, 0 skipped.\n" +
+		"FAILED example.com (fake) #2: CREATE b: server error\n"
+	if got := buf.String(); got != exp {
+		t.Errorf("got summary:\n%s\nexpected:\n%s", got, exp)
+	}
+}
+
+// zoneProvider is a DNS provider whose zone has the given records.
+type zoneProvider struct {
+	readsProvider
+	records models.Records
+}
+
+func (p *zoneProvider) GetZoneRecords(string) (models.Records, error) { return p.records, nil }
+
+// recorderProvider is a zoneProvider whose diff also compares the
+// "flatten" metadata, and which records it for LastDiff. It counts the
+// times its zone is read.
+type recorderProvider struct {
+	zoneProvider
+	zoneReads int
+	last      []diff.Changeset // unchanged, create, toDelete, modify
+}
+
+func (p *recorderProvider) GetZoneRecords(string) (models.Records, error) {
+	p.zoneReads++
+	return p.records, nil
+}
+
+func (p *recorderProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	existing, _ := p.GetZoneRecords(dc.Name)
+	unchanged, create, toDelete, modify, err := diff.New(dc, func(rc *models.RecordConfig) map[string]string {
+		return map[string]string{"flatten": rc.Metadata["flatten"]}
+	}).IncrementalDiff(existing)
+	p.last = []diff.Changeset{unchanged, create, toDelete, modify}
+	return nil, err
+}
+
+func (p *recorderProvider) LastDiff(string) (unchanged, create, toDelete, modify diff.Changeset, ok bool) {
+	if p.last == nil {
+		return nil, nil, nil, nil, false
+	}
+	return p.last[0], p.last[1], p.last[2], p.last[3], true
+}
+
+func TestPrintUnchanged(t *testing.T) {
+	rec := func(label, typ, target string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: typ, TTL: 300}
+		rc.SetLabel(label, "example.com")
+		if err := rc.SetTarget(target); err != nil {
+			t.Fatal(err)
+		}
+		return rc
+	}
+	flattened := rec("api", "CNAME", "app.example.net.")
+	flattened.Metadata = map[string]string{"flatten": "true"}
+	domain := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		rec("@", "A", "192.0.2.1"),   // unchanged
+		rec("www", "A", "192.0.2.2"), // changed
+		rec("new", "A", "192.0.2.3"), // created
+		flattened,                    // only the provider's metadata changed
+	}}
+	driver := &recorderProvider{zoneProvider: zoneProvider{records: models.Records{
+		rec("@", "A", "192.0.2.1"),
+		rec("www", "A", "192.0.2.9"),
+		rec("old", "A", "192.0.2.4"),
+		rec("api", "CNAME", "app.example.net."),
+	}}}
+
+	// The unchanged records come from the diff of the corrections.
+	if _, err := findUnchanged(domain, driver); err == nil {
+		t.Error("expected an error before the corrections are computed")
+	}
+	if _, err := driver.GetDomainCorrections(domain); err != nil {
+		t.Fatal(err)
+	}
+	unchanged, err := findUnchanged(domain, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if driver.zoneReads != 1 {
+		t.Errorf("read the zone %d times, expected only for the corrections", driver.zoneReads)
+	}
+	var buf bytes.Buffer
+	printUnchanged(unchanged, printer.ConsolePrinter{Writer: &buf})
+	exp := "NO-OP example.com A 192.0.2.1 ttl=300\n"
+	if got := buf.String(); got != exp {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, exp)
+	}
+}
+
+// applyProvider is a zoneProvider whose corrections add the missing
+// records to its zone.
+type applyProvider struct {
+	zoneProvider
+}
+
+func (p *applyProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	have := map[string]bool{}
+	for _, rc := range p.records {
+		have[rc.GetLabelFQDN()] = true
+	}
+	var corrections []*models.Correction
+	for _, rc := range dc.Records {
+		rc := rc
+		if !have[rc.GetLabelFQDN()] {
+			corrections = append(corrections, &models.Correction{
+				Msg: "CREATE " + rc.GetLabelFQDN(),
+				F:   func() error { p.records = append(p.records, rc); return nil },
+			})
+		}
+	}
+	return corrections, nil
+}
+
+func TestPushShowAll(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake");
+D("example.com", REG, DnsProvider(DSP), A("@", "192.0.2.1"), A("new", "192.0.2.2"));
+`, "")
+	args.ShowAll = true
+
+	apex := &models.RecordConfig{Type: "A", TTL: 300}
+	apex.SetLabel("@", "example.com")
+	if err := apex.SetTarget("192.0.2.1"); err != nil {
+		t.Fatal(err)
+	}
+	testProvider = &applyProvider{zoneProvider{records: models.Records{apex}}}
+
+	var buf bytes.Buffer
+	if err := run(args, true, printer.ConsolePrinter{Writer: &buf}); err != nil {
+		t.Fatal(err)
+	}
+	// new.example.com is created by the push, so it isn't a no-op.
+	got := buf.String()
+	if !strings.Contains(got, "NO-OP example.com A 192.0.2.1") || strings.Contains(got, "NO-OP new.example.com") {
+		t.Errorf("expected only example.com to be listed as a no-op, got:\n%s", got)
+	}
+}
+
+func TestPrintExplanations(t *testing.T) {
+	rec := func(label, typ, target string, ttl uint32) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: typ, TTL: ttl}
+		rc.SetLabel(label, "example.com")
+		if err := rc.SetTarget(target); err != nil {
+			t.Fatal(err)
+		}
+		return rc
+	}
+	domain := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		rec("@", "A", "192.0.2.1", 300),    // kept
+		rec("ttl", "A", "192.0.2.2", 600),  // TTL changed
+		rec("www", "A", "192.0.2.3", 300),  // value changed
+		rec("both", "A", "192.0.2.5", 600), // both changed
+		rec("new", "A", "192.0.2.4", 300),  // created
+	}}
+	driver := &zoneProvider{records: models.Records{
+		rec("@", "A", "192.0.2.1", 300),
+		rec("ttl", "A", "192.0.2.2", 300),
+		rec("www", "A", "192.0.2.9", 300),
+		rec("both", "A", "192.0.2.6", 300),
+		rec("old", "A", "192.0.2.8", 300),
+	}}
+
+	var buf bytes.Buffer
+	if err := printExplanations(domain, driver, printer.ConsolePrinter{Writer: &buf}); err != nil {
+		t.Fatal(err)
+	}
+	exp := `EXPLAIN both.example.com A 192.0.2.6 is updated because the TTL differs (300 -> 600) and the value differs (192.0.2.6 -> 192.0.2.5)
+EXPLAIN example.com A 192.0.2.1 is kept because it matches the configuration
+EXPLAIN new.example.com A 192.0.2.4 is created because it is in the configuration but not in the zone
+EXPLAIN old.example.com A 192.0.2.8 is deleted because it is in the zone but not in the configuration
+EXPLAIN ttl.example.com A 192.0.2.2 is updated because the TTL differs (300 -> 600)
+EXPLAIN www.example.com A 192.0.2.9 is updated because the value differs (192.0.2.9 -> 192.0.2.3)
+`
+	if got := buf.String(); got != exp {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, exp)
+	}
+}
+
+// nsFailProvider fails to get the nameservers of nsFailDomain.
+type nsFailProvider struct {
+	readsProvider
+}
+
+const nsFailDomain = "b.com"
+
+func (p *nsFailProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	if domain == nsFailDomain {
+		return nil, errors.New("zone unavailable")
+	}
+	return nil, nil
+}
+
+func TestContinueOnError(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake");
+D("a.com", REG, DnsProvider(DSP), A("@", "192.0.2.1"));
+D("b.com", REG, DnsProvider(DSP), A("@", "192.0.2.2"));
+D("c.com", REG, DnsProvider(DSP), A("@", "192.0.2.3"));
+`, "")
+
+	for _, cont := range []bool{false, true} {
+		nsFail := &nsFailProvider{}
+		testProvider = nsFail
+		args.ContinueOnError = cont
+
+		var buf bytes.Buffer
+		err := run(args, false, printer.ConsolePrinter{Writer: &buf})
+		if err == nil {
+			t.Fatalf("continue=%v: expected an error", cont)
+		}
+		exp := "a.com"
+		if cont {
+			exp = "a.com,c.com"
+			if !strings.Contains(buf.String(), "FAILED b.com: zone unavailable") {
+				t.Errorf("expected b.com to be listed as failed, got:\n%s", buf.String())
+			}
+		}
+		if got := strings.Join(nsFail.zones, ","); got != exp {
+			t.Errorf("continue=%v: read zones %q, expected %q", cont, got, exp)
+		}
+	}
+}
+
+// changesProvider returns a correction for each record of a domain.
+type changesProvider struct {
+	readsProvider
+	ran         int
+	compareOnly bool
+}
+
+func (p *changesProvider) SetCompareOnly() { p.compareOnly = true }
+
+func (p *changesProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	var corrections []*models.Correction
+	for _, rc := range dc.Records {
+		corrections = append(corrections, &models.Correction{
+			Msg: "CREATE " + rc.GetLabelFQDN(),
+			F:   func() error { p.ran++; return nil },
+		})
+	}
+	return corrections, nil
+}
+
+func TestMaxChanges(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake");
+D("example.com", REG, DnsProvider(DSP),
+	A("a", "192.0.2.1"),
+	A("b", "192.0.2.2"),
+	A("c", "192.0.2.3")
+);
+`, "")
+
+	for _, tc := range []struct {
+		max   int
+		force bool
+		ran   int
+	}{
+		{0, false, 3},
+		{3, false, 3},
+		{2, false, 0},
+		{2, true, 3},
+	} {
+		changes := &changesProvider{}
+		testProvider = changes
+		args.MaxChanges = tc.max
+		args.Force = tc.force
+
+		var buf bytes.Buffer
+		err := run(args, true, printer.ConsolePrinter{Writer: &buf})
+		if (err != nil) != (tc.ran == 0) {
+			t.Errorf("max=%d force=%v: got error %v", tc.max, tc.force, err)
+		}
+		if changes.ran != tc.ran {
+			t.Errorf("max=%d force=%v: ran %d corrections, expected %d", tc.max, tc.force, changes.ran, tc.ran)
+		}
+		if tc.ran == 0 && !strings.Contains(buf.String(), "more than --max-changes=2") {
+			t.Errorf("max=%d force=%v: expected the reason to be printed, got:\n%s", tc.max, tc.force, buf.String())
+		}
+	}
+}
+
+func TestCompareOnly(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake");
+D("example.com", REG, DnsProvider(DSP), A("a", "192.0.2.1"));
+`, "")
+	args.CompareOnly = true
+
+	changes := &changesProvider{}
+	testProvider = changes
+	var buf bytes.Buffer
+	err := run(args, true, printer.ConsolePrinter{Writer: &buf})
+	if !errors.Is(err, errDrift) {
+		t.Errorf("got error %v, expected %v", err, errDrift)
+	}
+	if code := exit(err).(cli.ExitCoder).ExitCode(); code != 2 {
+		t.Errorf("got exit status %d, expected 2", code)
+	}
+	if !changes.compareOnly {
+		t.Error("expected the provider to be made read-only")
+	}
+	if changes.ran != 0 {
+		t.Errorf("ran %d corrections, expected none", changes.ran)
+	}
+}
+
+func TestGroupByDomain(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake");
+D("a.com", REG, DnsProvider(DSP), A("x", "192.0.2.1"), A("y", "192.0.2.2"));
+D("b.com", REG, DnsProvider(DSP), A("z", "192.0.2.3"));
+`, "")
+	args.GroupByDomain = true
+
+	testProvider = &changesProvider{}
+	var buf bytes.Buffer
+	if err := run(args, false, printer.ConsolePrinter{Writer: &buf}); err != nil {
+		t.Fatal(err)
+	}
+	exp := `==> a.com: 2 corrections (fake: 2)
+    2 corrections
+    #1: CREATE x.a.com
+    #2: CREATE y.a.com
+    WARNING: No nameservers declared; skipping registrar. Add {no_ns:'true'} to force.
+==> b.com: 1 correction (fake: 1)
+    1 correction
+    #1: CREATE z.b.com
+    WARNING: No nameservers declared; skipping registrar. Add {no_ns:'true'} to force.
+Done. 3 corrections.
+`
+	if buf.String() != exp {
+		t.Errorf("got:\n%s\nexpected:\n%s", buf.String(), exp)
+	}
+}
+
+// writeBindCreds replaces the creds.json of args with one whose "bind"
+// provider keeps its zone files next to dnsconfig.js, and returns that
+// directory.
+func writeBindCreds(t *testing.T, args PushArgs) string {
+	t.Helper()
+	dir := filepath.Dir(args.JSFile)
+	if err := os.WriteFile(args.CredsFile, []byte(`{
+  "none": {"TYPE": "NONE"},
+  "bind": {"TYPE": "BIND", "directory": "`+filepath.ToSlash(dir)+`"}
+}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestCorrectionSource(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("bind");
+D("a.com", REG, DnsProvider(DSP),
+  A("x", "192.0.2.1"),
+  A("y", "192.0.2.2")
+);
+`, "")
+	dir := writeBindCreds(t, args)
+	if err := os.WriteFile(filepath.Join(dir, "a.com.zone"), []byte("x 300 IN A 192.0.2.1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := run(args, false, printer.ConsolePrinter{Writer: &buf}); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "CREATE A y.a.com 192.0.2.2 ttl=300 (" + args.JSFile + ":6)"; !strings.Contains(buf.String(), exp) {
+		t.Errorf("expected output to contain %q, got:\n%s", exp, buf.String())
+	}
+}
+
+func TestTTLOverride(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("bind");
+D("a.com", REG, DnsProvider(DSP),
+  A("x", "192.0.2.1"),
+  A("y", "192.0.2.2", TTL(3600)),
+  MX("@", 10, "mail.a.com.")
+);
+`, "")
+	dir := writeBindCreds(t, args)
+	if err := os.WriteFile(filepath.Join(dir, "a.com.zone"), []byte(
+		"x 300 IN A 192.0.2.1\ny 3600 IN A 192.0.2.2\n@ 300 IN MX 10 mail.a.com.\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	args.TTLOverride = 60
+
+	var buf bytes.Buffer
+	if err := run(args, false, printer.ConsolePrinter{Writer: &buf}); err != nil {
+		t.Fatal(err)
+	}
+	for _, exp := range []string{
+		"MODIFY A x.a.com: (192.0.2.1 ttl=300) -> (192.0.2.1 ttl=60)",
+		"MODIFY A y.a.com: (192.0.2.2 ttl=3600) -> (192.0.2.2 ttl=60)",
+		"MODIFY MX a.com: (10 mail.a.com. ttl=300) -> (10 mail.a.com. ttl=60)",
+	} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("expected output to contain %q, got:\n%s", exp, buf.String())
+		}
+	}
+}
+
+// concurProvider is a changesProvider that can be used concurrently. It
+// records the most domains it reads at once.
+type concurProvider struct {
+	changesProvider
+	mu      sync.Mutex
+	reading int
+	most    int
+}
+
+func (p *concurProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	p.mu.Lock()
+	if p.reading++; p.reading > p.most {
+		p.most = p.reading
+	}
+	p.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	p.mu.Lock()
+	p.reading--
+	p.mu.Unlock()
+	return p.changesProvider.GetDomainCorrections(dc)
+}
+
+func TestParallelDomains(t *testing.T) {
+	js := `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("concur");
+var SERIAL = NewDnsProvider("fake");
+D("serial.com", REG, DnsProvider(SERIAL), {"no_ns": "true"}, A("x", "192.0.2.1"));
+`
+	exp := []string{"serial.com"}
+	for _, d := range []string{"a", "b", "c", "d", "e", "f"} {
+		js += fmt.Sprintf("D(%q, REG, DnsProvider(DSP), {\"no_ns\": \"true\"}, A(\"x\", \"192.0.2.1\"));\n", d+".com")
+		exp = append(exp, d+".com")
+	}
+	args := writeTestConfig(t, js, `{
+  "none": {"TYPE": "NONE"},
+  "concur": {"TYPE": "PP_FAKE_CONCUR"},
+  "fake": {"TYPE": "PP_FAKE"}
+}`)
+	args.ParallelDomains = 3
+
+	concur, serial := &concurProvider{}, &changesProvider{}
+	testConcurProvider, testProvider = concur, serial
+	var buf bytes.Buffer
+	if err := run(args, true, printer.ConsolePrinter{Writer: &buf}); err != nil {
+		t.Fatal(err)
+	}
+	if concur.most < 2 || concur.most > 3 {
+		t.Errorf("read %d domains at once, expected 2 or 3", concur.most)
+	}
+	if concur.ran != 6 || serial.ran != 1 {
+		t.Errorf("ran %d and %d corrections, expected 6 and 1", concur.ran, serial.ran)
+	}
+	// The domains are printed, and their corrections run, in order.
+	var got []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "#1: CREATE x.") {
+			got = append(got, strings.TrimPrefix(line, "#1: CREATE x."))
+		}
+	}
+	if strings.Join(got, ",") != strings.Join(exp, ",") {
+		t.Errorf("got domains %v, expected %v; output:\n%s", got, exp, buf.String())
+	}
+}
+
+// slowProvider is a readsProvider that reads slowDomain until its
+// context is done.
+type slowProvider struct {
+	readsProvider
+	ctx context.Context
+}
+
+const slowDomain = "slow.com"
+
+func (p *slowProvider) SetContext(ctx context.Context) { p.ctx = ctx }
+
+func (p *slowProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	if dc.Name == slowDomain {
+		<-p.ctx.Done()
+		return nil, p.ctx.Err()
+	}
+	return p.readsProvider.GetDomainCorrections(dc)
+}
+
+func TestTimeout(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake");
+D("a.com", REG, DnsProvider(DSP), {"no_ns": "true"}, A("@", "192.0.2.1"));
+D("slow.com", REG, DnsProvider(DSP), {"no_ns": "true"}, A("@", "192.0.2.2"));
+D("c.com", REG, DnsProvider(DSP), {"no_ns": "true"}, A("@", "192.0.2.3"));
+`, "")
+	args.Timeout = 50 * time.Millisecond
+
+	slow := &slowProvider{ctx: context.Background()}
+	testProvider = slow
+	var buf bytes.Buffer
+	err := run(args, true, printer.ConsolePrinter{Writer: &buf})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("got error %v, expected a timeout", err)
+	}
+	if got := strings.Join(slow.zones, ","); got != "a.com" {
+		t.Errorf("read zones %q, expected a.com", got)
+	}
+	for _, exp := range []string{
+		"Completed domains: 1 of 3.",
+		"COMPLETED a.com\n",
+		"NOT COMPLETED slow.com\n",
+		"NOT COMPLETED c.com\n",
+	} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("expected output to contain %q, got:\n%s", exp, buf.String())
+		}
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake");
+D("example.com", REG, DnsProvider(DSP), A("a", "192.0.2.1"));
+`, "")
+	args.Freeze = "true"
+
+	changes := &changesProvider{}
+	testProvider = changes
+	var buf bytes.Buffer
+	err := run(args, true, printer.ConsolePrinter{Writer: &buf})
+	if err == nil || !strings.Contains(err.Error(), "push refused: changes are frozen") {
+		t.Errorf("got error %v, expected the push to be refused", err)
+	}
+	if changes.ran != 0 {
+		t.Errorf("ran %d corrections, expected none", changes.ran)
+	}
+
+	buf.Reset()
+	if err := run(args, false, printer.ConsolePrinter{Writer: &buf}); err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if !strings.Contains(buf.String(), "#1: CREATE a.example.com") {
+		t.Errorf("expected preview to list the correction, got:\n%s", buf.String())
+	}
+}
+
+// snapshotProvider compares a domain with a snapshot by its records'
+// names and targets, and fails if it is asked to read the zone.
+type snapshotProvider struct {
+	readsProvider
+}
+
+func (p *snapshotProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	return nil, fmt.Errorf("read %s instead of using the snapshot", dc.Name)
+}
+
+func (p *snapshotProvider) GetSnapshotCorrections(dc *models.DomainConfig, existing models.Records) ([]*models.Correction, error) {
+	have := map[string]bool{}
+	for _, rc := range existing {
+		have[rc.GetLabelFQDN()+" "+rc.GetTargetCombined()] = true
+	}
+	var corrections []*models.Correction
+	for _, rc := range dc.Records {
+		if key := rc.GetLabelFQDN() + " " + rc.GetTargetCombined(); have[key] {
+			delete(have, key)
+			continue
+		}
+		corrections = append(corrections, &models.Correction{Msg: "CREATE " + rc.GetLabelFQDN()})
+	}
+	for _, rc := range existing {
+		if have[rc.GetLabelFQDN()+" "+rc.GetTargetCombined()] {
+			corrections = append(corrections, &models.Correction{Msg: "DELETE " + rc.GetLabelFQDN()})
+		}
+	}
+	return corrections, nil
+}
+
+func TestFromSnapshot(t *testing.T) {
+	args := writeTestConfig(t, `
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake", "PP_FAKE");
+D("example.com", REG, DnsProvider(DSP),
+	A("@", "192.0.2.1"),
+	A("www", "192.0.2.2")
+);
+D("example.net", REG, DnsProvider(DSP));
+`, "")
+	// There is no creds.json: the provider isn't contacted.
+	if err := os.Remove(args.CredsFile); err != nil {
+		t.Fatal(err)
+	}
+	old := &models.RecordConfig{Type: "A"}
+	old.SetLabel("old", "example.com")
+	old.SetTarget("192.0.2.9")
+	apex := &models.RecordConfig{Type: "A"}
+	apex.SetLabel("@", "example.com")
+	apex.SetTarget("192.0.2.1")
+	snapshotFile := filepath.Join(filepath.Dir(args.JSFile), "snapshot.json")
+	f, err := os.Create(snapshotFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeSnapshot(f, []string{"example.com"}, []models.Records{{apex, old}}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	testProvider = &snapshotProvider{}
+	args.FromSnapshot = snapshotFile
+	args.Domains = "example.com"
+	var buf bytes.Buffer
+	if err := run(args, false, printer.ConsolePrinter{Writer: &buf}); err != nil {
+		t.Fatalf("%s\n%s", err, buf.String())
+	}
+	for _, exp := range []string{"CREATE www.example.com", "DELETE old.example.com", "Done. 2 corrections."} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("expected the output to contain %q, got:\n%s", exp, buf.String())
+		}
+	}
+	if testProviderCreds["_from_snapshot"] != "true" {
+		t.Errorf("got creds %v, expected _from_snapshot", testProviderCreds)
+	}
+
+	// A domain that isn't in the snapshot fails.
+	args.Domains = "example.net"
+	buf.Reset()
+	if err := run(args, false, printer.ConsolePrinter{Writer: &buf}); err == nil || !strings.Contains(buf.String(), "example.net isn't in the snapshot") {
+		t.Errorf("got error %v, expected example.net to be missing from the snapshot:\n%s", err, buf.String())
+	}
+
+	// And so does push.
+	if err := run(args, true, printer.ConsolePrinter{Writer: &buf}); err == nil || !strings.Contains(err.Error(), "only works with preview") {
+		t.Errorf("got error %v, expected push to be refused", err)
+	}
+}
@@ -2,7 +2,9 @@ package commands
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
@@ -28,6 +30,18 @@ var _ = cmd(catDebug, func() *cli.Command {
 // CheckArgs encapsulates the flags/arguments for the check command.
 type CheckArgs struct {
 	GetDNSConfigArgs
+	Format string
+}
+
+func (args *CheckArgs) flags() []cli.Flag {
+	flags := args.GetDNSConfigArgs.flags()
+	flags = append(flags, &cli.StringFlag{
+		Name:        "format",
+		Destination: &args.Format,
+		Value:       "text",
+		Usage:       `Output format: text json (a list of errors with their code, domain and record)`,
+	})
+	return flags
 }
 
 var _ = cmd(catDebug, func() *cli.Command {
@@ -40,6 +54,13 @@ var _ = cmd(catDebug, func() *cli.Command {
 		Name:  "check",
 		Usage: "Check and validate dnsconfig.js. Output to stdout.  Do not access providers.",
 		Action: func(c *cli.Context) error {
+			switch args.Format {
+			case "text":
+			case "json":
+				return exit(CheckJSON(args, os.Stdout))
+			default:
+				return cli.Exit(fmt.Sprintf("format %q unknown", args.Format), 1)
+			}
 
 			// Create a PrintIRArgs struct and copy our args to the
 			// appropriate fields.
@@ -67,6 +88,53 @@ var _ = cmd(catDebug, func() *cli.Command {
 	}
 }())
 
+// CheckJSON implements check --format=json. It writes the validation
+// errors and warnings to w as a JSON list.
+func CheckJSON(args CheckArgs, w io.Writer) error {
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	fatal, err := writeValidationErrorsJSON(w, normalize.ValidateAndNormalizeConfig(cfg))
+	if err != nil {
+		return err
+	}
+	if fatal {
+		return fmt.Errorf("exiting due to validation errors")
+	}
+	return nil
+}
+
+// jsonValidationError is an item of check --format=json's output.
+type jsonValidationError struct {
+	Severity string `json:"severity"` // "error" or "warning"
+	models.ValidationError
+}
+
+// writeValidationErrorsJSON writes errs to w as a JSON list. Errors that
+// aren't a *models.ValidationError have the code "invalid".
+func writeValidationErrorsJSON(w io.Writer, errs []error) (fatal bool, err error) {
+	items := []jsonValidationError{}
+	for _, e := range errs {
+		item := jsonValidationError{Severity: "error"}
+		if _, ok := e.(normalize.Warning); ok {
+			item.Severity = "warning"
+		} else {
+			fatal = true
+		}
+		var ve *models.ValidationError
+		if errors.As(e, &ve) {
+			item.ValidationError = *ve
+		} else {
+			item.ValidationError = models.ValidationError{Code: "invalid", Message: e.Error()}
+		}
+		items = append(items, item)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return fatal, enc.Encode(items)
+}
+
 // PrintIRArgs encapsulates the flags/arguments for the print-ir command.
 type PrintIRArgs struct {
 	GetDNSConfigArgs
@@ -157,6 +225,9 @@ func exit(err error) error {
 	if err == nil {
 		return nil
 	}
+	if errors.Is(err, errDrift) {
+		return cli.Exit(err, 2)
+	}
 	return cli.Exit(err, 1)
 }
 
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckJSON(t *testing.T) {
+	jsFile := filepath.Join(t.TempDir(), "dnsconfig.js")
+	if err := os.WriteFile(jsFile, []byte(`
+var REG = NewRegistrar("none");
+var DSP = NewDnsProvider("fake", "PP_FAKE");
+D("example.com", REG, DnsProvider(DSP),
+	TLSA("_443._tcp", 3, 1, 1, "abcdef0123456789")
+);
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var args CheckArgs
+	args.JSFile = jsFile
+	var buf bytes.Buffer
+	if err := CheckJSON(args, &buf); err == nil {
+		t.Error("expected an error")
+	}
+
+	var got []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("%s:\n%s", err, buf.String())
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 error, got:\n%s", buf.String())
+	}
+	for k, v := range map[string]string{
+		"severity": "error",
+		"code":     "unsupported-record-type",
+		"domain":   "example.com",
+		"record":   "_443._tcp.example.com TLSA",
+	} {
+		if got[0][k] != v {
+			t.Errorf("got %s %q, expected %q", k, got[0][k], v)
+		}
+	}
+}
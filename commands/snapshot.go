@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// A snapshot is the records of zones, saved by get-zones --format=json,
+// that preview --from-snapshot compares the configuration with instead of
+// the providers. It is a JSON object of each zone's records, by zone name.
+
+// writeSnapshot writes the records of zones, in the same order, to w as a
+// snapshot.
+func writeSnapshot(w io.Writer, zones []string, recs []models.Records) error {
+	snapshot := map[string]models.Records{}
+	for i, zone := range zones {
+		snapshot[zone] = recs[i]
+		if snapshot[zone] == nil {
+			snapshot[zone] = models.Records{}
+		}
+	}
+	bs, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", bs)
+	return err
+}
+
+// readSnapshot reads the snapshot in the file path.
+func readSnapshot(path string) (map[string]models.Records, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var zones map[string][]json.RawMessage
+	if err := json.Unmarshal(bs, &zones); err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", path, err)
+	}
+	snapshot := map[string]models.Records{}
+	for zone, raws := range zones {
+		recs := models.Records{}
+		for i, raw := range raws {
+			// RecordConfig.UnmarshalJSON panics if there's no type.
+			var typ struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(raw, &typ); err != nil || typ.Type == "" {
+				return nil, fmt.Errorf("snapshot %s: record %d of %s has no type", path, i+1, zone)
+			}
+			rc := &models.RecordConfig{}
+			if err := json.Unmarshal(raw, rc); err != nil {
+				return nil, fmt.Errorf("snapshot %s: record %d of %s: %w", path, i+1, zone, err)
+			}
+			rc.SetLabel(rc.Name, zone)
+			recs = append(recs, rc)
+		}
+		snapshot[zone] = recs
+	}
+	return snapshot, nil
+}
@@ -17,6 +17,7 @@ type DNSConfig struct {
 	RegistrarsByName   map[string]*RegistrarConfig   `json:"-"`
 	DNSProvidersByName map[string]*DNSProviderConfig `json:"-"`
 	SkipRecordAudit    bool                          `json:"skiprecordaudit,omitempty"`
+	TXTFragments       map[string]string             `json:"txt_fragments,omitempty"`
 }
 
 // FindDomain returns the *DomainConfig for domain query in config.
@@ -102,6 +103,28 @@ func NameserversToStrings(nss []*Nameserver) (s []string) {
 type Correction struct {
 	F   func() error `json:"-"`
 	Msg string
+
+	// Requests are the API requests that F sends, if the provider
+	// describes them. They are used by --emit-script.
+	Requests []HTTPRequest `json:"-"`
+
+	// IDs name the correction, so that other corrections can list it in
+	// After. A correction that makes several changes may have an ID for
+	// each of them.
+	IDs []string `json:"-"`
+	// After lists the IDs of the corrections that must run before this
+	// one. IDs that no other correction of the domain has are ignored.
+	After []string `json:"-"`
+}
+
+// HTTPRequest describes an API request made by a Correction. Header
+// values may refer to environment variables, such as $GCORE_API_KEY,
+// so that secrets aren't written to the script.
+type HTTPRequest struct {
+	Method string
+	URL    string
+	Header map[string]string
+	Body   []byte // nil if the request has no body
 }
 
 // DomainContainingFQDN finds the best domain from the dns config for the given record fqdn.
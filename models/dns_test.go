@@ -1,6 +1,11 @@
 package models
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+)
 
 func TestRR(t *testing.T) {
 	experiment := RecordConfig{
@@ -49,6 +54,51 @@ func TestRR(t *testing.T) {
 	}
 }
 
+func TestGenericType(t *testing.T) {
+	for rtype, exp := range map[string]bool{
+		"TYPE65534": true,
+		"TYPE1":     false, // A
+		"TYPE0":     false,
+		"TYPE65536": false,
+		"TYPE":      false,
+		"TXT":       false,
+	} {
+		if got := IsGenericType(rtype); got != exp {
+			t.Errorf("IsGenericType(%q) is %v, expected %v", rtype, got, exp)
+		}
+	}
+}
+
+func TestGenericRoundTrip(t *testing.T) {
+	rr, err := dns.NewRR(`foo.example.com. 300 IN TYPE65534 \# 5 0D2A5B0000`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := RRtoRC(rr, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.Type != "TYPE65534" || rc.GetTargetField() != `\# 5 0d2a5b0000` {
+		t.Errorf("got %s %q, expected TYPE65534 %q", rc.Type, rc.GetTargetField(), `\# 5 0d2a5b0000`)
+	}
+	if got, exp := rc.ToRR().String(), rr.String(); !strings.EqualFold(got, exp) {
+		t.Errorf("RR expected (%#v) got (%#v)", exp, got)
+	}
+
+	parsed := &RecordConfig{}
+	if err := parsed.PopulateFromString("TYPE65534", `\# 5 0D2A 5B0000`, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.GetTargetField() != rc.GetTargetField() {
+		t.Errorf("parsed %q, expected %q", parsed.GetTargetField(), rc.GetTargetField())
+	}
+	for _, data := range []string{"0d2a5b0000", `\# 4 0d2a5b0000`, `\# 5 xyz`} {
+		if err := (&RecordConfig{Type: "TYPE65534"}).SetTargetGeneric(data); err == nil {
+			t.Errorf("expected error for %q, got none", data)
+		}
+	}
+}
+
 func TestDowncase(t *testing.T) {
 	dc := DomainConfig{Records: Records{
 		&RecordConfig{Type: "MX", Name: "lower", target: "targetmx"},
@@ -54,6 +54,16 @@ func RRstoRCs(rrs []dns.RR, origin string) (Records, error) {
 	return rcs, nil
 }
 
+// AXFRtoRCs converts the records of a zone transfer (AXFR) to
+// []RecordConfigs. A transfer starts and ends with the zone's SOA (RFC
+// 5936, section 2.2), so the SOA at the end is dropped.
+func AXFRtoRCs(rrs []dns.RR, origin string) (Records, error) {
+	if n := len(rrs); n >= 2 && rrs[n-1].Header().Rrtype == dns.TypeSOA {
+		rrs = rrs[:n-1]
+	}
+	return RRstoRCs(rrs, origin)
+}
+
 // RRtoRC converts dns.RR to RecordConfig
 func RRtoRC(rr dns.RR, origin string) (RecordConfig, error) {
 	// Convert's dns.RR into our native data type (RecordConfig).
@@ -72,28 +82,48 @@ func RRtoRC(rr dns.RR, origin string) (RecordConfig, error) {
 		err = rc.SetTarget(v.AAAA.String())
 	case *dns.CAA:
 		err = rc.SetTargetCAA(v.Flag, v.Tag, v.Value)
+	case *dns.CDNSKEY:
+		err = rc.SetTargetCDNSKEY(v.Flags, v.Protocol, v.Algorithm, v.PublicKey)
+	case *dns.CDS:
+		err = rc.SetTargetCDS(v.KeyTag, v.Algorithm, v.DigestType, v.Digest)
 	case *dns.CNAME:
 		err = rc.SetTarget(v.Target)
+	case *dns.DNAME:
+		err = rc.SetTarget(v.Target)
 	case *dns.DS:
 		err = rc.SetTargetDS(v.KeyTag, v.Algorithm, v.DigestType, v.Digest)
 	case *dns.MX:
 		err = rc.SetTargetMX(v.Preference, v.Mx)
 	case *dns.NS:
 		err = rc.SetTarget(v.Ns)
+	case *dns.OPENPGPKEY:
+		err = rc.SetTargetOPENPGPKEY(v.PublicKey)
 	case *dns.PTR:
 		err = rc.SetTarget(v.Ptr)
 	case *dns.NAPTR:
 		err = rc.SetTargetNAPTR(v.Order, v.Preference, v.Flags, v.Service, v.Regexp, v.Replacement)
+	case *dns.SMIMEA:
+		err = rc.SetTargetSMIMEA(v.Usage, v.Selector, v.MatchingType, v.Certificate)
 	case *dns.SOA:
 		err = rc.SetTargetSOA(v.Ns, v.Mbox, v.Serial, v.Refresh, v.Retry, v.Expire, v.Minttl)
 	case *dns.SRV:
 		err = rc.SetTargetSRV(v.Priority, v.Weight, v.Port, v.Target)
 	case *dns.SSHFP:
 		err = rc.SetTargetSSHFP(v.Algorithm, v.Type, v.FingerPrint)
+	case *dns.SVCB:
+		err = rc.SetTargetSVCB(v.Priority, v.Target, v.Value)
+	case *dns.HTTPS:
+		err = rc.SetTargetSVCB(v.Priority, v.Target, v.Value)
 	case *dns.TLSA:
 		err = rc.SetTargetTLSA(v.Usage, v.Selector, v.MatchingType, v.Certificate)
 	case *dns.TXT:
 		err = rc.SetTargetTXTs(v.Txt)
+	case *dns.RFC3597:
+		rc.Type = fmt.Sprintf("TYPE%d", header.Rrtype)
+		if !IsGenericType(rc.Type) {
+			return *rc, fmt.Errorf("rrToRecord: %s has generic data, which is unsupported for known types (%v)", rc.Type, rr)
+		}
+		err = rc.SetTargetGeneric(fmt.Sprintf(`\# %d %s`, len(v.Rdata)/2, v.Rdata))
 	default:
 		return *rc, fmt.Errorf("rrToRecord: Unimplemented zone record type=%s (%v)", rc.Type, rr)
 	}
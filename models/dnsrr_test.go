@@ -0,0 +1,52 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestAXFRtoRCs(t *testing.T) {
+	// A zone transfer, as sent by a nameserver: the SOA comes first
+	// and last.
+	var rrs []dns.RR
+	for _, s := range []string{
+		"example.com. 3600 IN SOA ns1.example.com. hostmaster.example.com. 2026101501 7200 3600 1209600 300",
+		"example.com. 3600 IN NS ns1.example.com.",
+		"example.com. 300 IN MX 10 mail.example.com.",
+		"www.example.com. 300 IN A 192.0.2.1",
+		`txt.example.com. 300 IN TXT "v=spf1 -all" "second string"`,
+		"_sip._tcp.example.com. 300 IN SRV 10 20 5060 sip.example.com.",
+		"example.com. 3600 IN SOA ns1.example.com. hostmaster.example.com. 2026101501 7200 3600 1209600 300",
+	} {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rrs = append(rrs, rr)
+	}
+
+	recs, err := AXFRtoRCs(rrs, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rc := range recs {
+		got = append(got, rc.GetLabel()+" "+rc.Type+" "+rc.GetTargetCombined())
+	}
+	exp := []string{
+		"@ SOA ns1.example.com. hostmaster.example.com. 2026101501 7200 3600 1209600 300",
+		"@ NS ns1.example.com.",
+		"@ MX 10 mail.example.com.",
+		"www A 192.0.2.1",
+		`txt TXT "v=spf1 -all" "second string"`,
+		"_sip._tcp SRV 10 20 5060 sip.example.com.",
+	}
+	if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		t.Errorf("got records:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+	if recs[3].TTL != 300 || recs[0].TTL != 3600 {
+		t.Errorf("got TTLs %d and %d, expected 300 and 3600", recs[3].TTL, recs[0].TTL)
+	}
+}
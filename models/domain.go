@@ -22,6 +22,7 @@ type DomainConfig struct {
 	IgnoredNames   []*IgnoreName     `json:"ignored_names,omitempty"`
 	IgnoredTargets []*IgnoreTarget   `json:"ignored_targets,omitempty"`
 	AutoDNSSEC     string            `json:"auto_dnssec,omitempty"` // "", "on", "off"
+	Source         string            `json:"-"`                     // The file and line that declared the domain, such as "dnsconfig.js:3", if known.
 	//DNSSEC        bool              `json:"dnssec,omitempty"`
 
 	// These fields contain instantiated provider instances once everything is linked up.
@@ -77,7 +78,7 @@ func (dc *DomainConfig) Punycode() error {
 
 		// Set the target:
 		switch rec.Type { // #rtype_variations
-		case "ALIAS", "MX", "NS", "CNAME", "PTR", "SRV", "URL", "URL301", "FRAME", "R53_ALIAS", "NS1_URLFWD", "AKAMAICDN", "CLOUDNS_WR":
+		case "ALIAS", "MX", "NS", "CNAME", "DNAME", "HTTPS", "PTR", "SRV", "SVCB", "URL", "URL301", "FRAME", "R53_ALIAS", "NS1_URLFWD", "AKAMAICDN", "CLOUDNS_WR":
 			// These rtypes are hostnames, therefore need to be converted (unlike, for example, an AAAA record)
 			t, err := idna.ToASCII(rec.GetTargetField())
 			if err != nil {
@@ -86,9 +87,12 @@ func (dc *DomainConfig) Punycode() error {
 			rec.SetTarget(t)
 		case "CF_REDIRECT", "CF_TEMP_REDIRECT", "CF_WORKER_ROUTE":
 			rec.SetTarget(rec.GetTargetField())
-		case "A", "AAAA", "CAA", "DS", "NAPTR", "SOA", "SSHFP", "TXT", "TLSA", "AZURE_ALIAS":
+		case "A", "AAAA", "CAA", "CDNSKEY", "CDS", "DS", "NAPTR", "OPENPGPKEY", "SMIMEA", "SOA", "SSHFP", "TXT", "TLSA", "AZURE_ALIAS":
 			// Nothing to do.
 		default:
+			if IsGenericType(rec.Type) {
+				continue // The data is hex.
+			}
 			return fmt.Errorf("Punycode rtype %v unimplemented", rec.Type)
 		}
 	}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OrderCorrections returns corrections ordered so that each runs after
+// the corrections it lists in After. Otherwise they keep their order:
+// of the corrections that may run next, the first is chosen. It is an
+// error if corrections depend on each other in a cycle.
+func OrderCorrections(corrections []*Correction) ([]*Correction, error) {
+	byID := map[string][]int{}
+	for i, c := range corrections {
+		for _, id := range c.IDs {
+			byID[id] = append(byID[id], i)
+		}
+	}
+	// before[i] are the corrections that must run before corrections[i].
+	before := make([][]int, len(corrections))
+	ordered := true
+	for i, c := range corrections {
+		for _, id := range c.After {
+			for _, j := range byID[id] {
+				if j != i {
+					before[i] = append(before[i], j)
+					ordered = ordered && j < i
+				}
+			}
+		}
+	}
+	if ordered {
+		return corrections, nil
+	}
+
+	result := make([]*Correction, 0, len(corrections))
+	done := make([]bool, len(corrections))
+	for len(result) < len(corrections) {
+		next := -1
+		for i := range corrections {
+			if !done[i] && allDone(before[i], done) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var cycle []string
+			for i, c := range corrections {
+				if !done[i] {
+					cycle = append(cycle, fmt.Sprintf("%q", c.Msg))
+				}
+			}
+			return nil, fmt.Errorf("corrections depend on each other in a cycle: %s", strings.Join(cycle, ", "))
+		}
+		done[next] = true
+		result = append(result, corrections[next])
+	}
+	return result, nil
+}
+
+// allDone reports whether all of the corrections is are done.
+func allDone(is []int, done []bool) bool {
+	for _, i := range is {
+		if !done[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOrderCorrections(t *testing.T) {
+	msgs := func(corrections []*Correction) string {
+		var s []string
+		for _, c := range corrections {
+			s = append(s, c.Msg)
+		}
+		return strings.Join(s, ",")
+	}
+	for _, tc := range []struct {
+		name        string
+		corrections []*Correction
+		exp         string
+		err         bool
+	}{
+		{
+			name: "no hints",
+			corrections: []*Correction{
+				{Msg: "a"}, {Msg: "b"}, {Msg: "c"},
+			},
+			exp: "a,b,c",
+		},
+		{
+			name: "already ordered",
+			corrections: []*Correction{
+				{Msg: "a", IDs: []string{"a"}}, {Msg: "b", After: []string{"a"}},
+			},
+			exp: "a,b",
+		},
+		{
+			name: "moved after its prerequisites",
+			corrections: []*Correction{
+				{Msg: "ds", IDs: []string{"ds"}, After: []string{"dnskey", "ns"}},
+				{Msg: "other"},
+				{Msg: "dnskey", IDs: []string{"dnskey"}},
+				{Msg: "ns", IDs: []string{"ns"}},
+			},
+			exp: "other,dnskey,ns,ds",
+		},
+		{
+			name: "unknown and own IDs are ignored",
+			corrections: []*Correction{
+				{Msg: "a", IDs: []string{"a", "b"}, After: []string{"b", "missing"}},
+				{Msg: "c"},
+			},
+			exp: "a,c",
+		},
+		{
+			name: "cycle",
+			corrections: []*Correction{
+				{Msg: "a", IDs: []string{"a"}, After: []string{"b"}},
+				{Msg: "b", IDs: []string{"b"}, After: []string{"a"}},
+			},
+			err: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := OrderCorrections(tc.corrections)
+			if (err != nil) != tc.err {
+				t.Fatalf("got error %v", err)
+			}
+			if err == nil && msgs(got) != tc.exp {
+				t.Errorf("got %s, expected %s", msgs(got), tc.exp)
+			}
+		})
+	}
+}
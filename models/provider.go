@@ -30,4 +30,6 @@ type DNSProviderInstance struct {
 	ProviderBase
 	Driver              DNSProvider
 	NumberOfNameservers int
+	CapabilityOverrides string // From the "_capabilities" field in creds.json.
+	DiscoveredCaps      string // Capability overrides found by querying the provider's account.
 }
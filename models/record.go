@@ -22,6 +22,7 @@ import (
 //	  ANAME  // Technically not an official rtype yet.
 //	  CAA
 //	  CNAME
+//	  HTTPS
 //	  MX
 //	  NAPTR
 //	  NS
@@ -29,6 +30,7 @@ import (
 //	  SOA
 //	  SRV
 //	  SSHFP
+//	  SVCB
 //	  TLSA
 //	  TXT
 //	Pseudo-Types: (alphabetical)
@@ -92,37 +94,46 @@ type RecordConfig struct {
 	TTL       uint32            `json:"ttl,omitempty"`
 	Metadata  map[string]string `json:"meta,omitempty"`
 	Original  interface{}       `json:"-"` // Store pointer to provider-specific record object. Used in diffing.
+	Source    string            `json:"-"` // The file and line that declared the record, such as "dnsconfig.js:12", if known.
 
 	// If you add a field to this struct, also add it to the list on MarshalJSON.
-	MxPreference     uint16            `json:"mxpreference,omitempty"`
-	SrvPriority      uint16            `json:"srvpriority,omitempty"`
-	SrvWeight        uint16            `json:"srvweight,omitempty"`
-	SrvPort          uint16            `json:"srvport,omitempty"`
-	CaaTag           string            `json:"caatag,omitempty"`
-	CaaFlag          uint8             `json:"caaflag,omitempty"`
-	DsKeyTag         uint16            `json:"dskeytag,omitempty"`
-	DsAlgorithm      uint8             `json:"dsalgorithm,omitempty"`
-	DsDigestType     uint8             `json:"dsdigesttype,omitempty"`
-	DsDigest         string            `json:"dsdigest,omitempty"`
-	NaptrOrder       uint16            `json:"naptrorder,omitempty"`
-	NaptrPreference  uint16            `json:"naptrpreference,omitempty"`
-	NaptrFlags       string            `json:"naptrflags,omitempty"`
-	NaptrService     string            `json:"naptrservice,omitempty"`
-	NaptrRegexp      string            `json:"naptrregexp,omitempty"`
-	SshfpAlgorithm   uint8             `json:"sshfpalgorithm,omitempty"`
-	SshfpFingerprint uint8             `json:"sshfpfingerprint,omitempty"`
-	SoaMbox          string            `json:"soambox,omitempty"`
-	SoaSerial        uint32            `json:"soaserial,omitempty"`
-	SoaRefresh       uint32            `json:"soarefresh,omitempty"`
-	SoaRetry         uint32            `json:"soaretry,omitempty"`
-	SoaExpire        uint32            `json:"soaexpire,omitempty"`
-	SoaMinttl        uint32            `json:"soaminttl,omitempty"`
-	TlsaUsage        uint8             `json:"tlsausage,omitempty"`
-	TlsaSelector     uint8             `json:"tlsaselector,omitempty"`
-	TlsaMatchingType uint8             `json:"tlsamatchingtype,omitempty"`
-	TxtStrings       []string          `json:"txtstrings,omitempty"` // TxtStrings stores all strings (including the first). Target stores all the strings joined.
-	R53Alias         map[string]string `json:"r53_alias,omitempty"`
-	AzureAlias       map[string]string `json:"azure_alias,omitempty"`
+	MxPreference       uint16            `json:"mxpreference,omitempty"`
+	SrvPriority        uint16            `json:"srvpriority,omitempty"`
+	SrvWeight          uint16            `json:"srvweight,omitempty"`
+	SrvPort            uint16            `json:"srvport,omitempty"`
+	CaaTag             string            `json:"caatag,omitempty"`
+	CaaFlag            uint8             `json:"caaflag,omitempty"`
+	DsKeyTag           uint16            `json:"dskeytag,omitempty"`
+	DsAlgorithm        uint8             `json:"dsalgorithm,omitempty"`
+	DsDigestType       uint8             `json:"dsdigesttype,omitempty"`
+	DsDigest           string            `json:"dsdigest,omitempty"`
+	DnskeyFlags        uint16            `json:"dnskeyflags,omitempty"`
+	DnskeyProtocol     uint8             `json:"dnskeyprotocol,omitempty"`
+	DnskeyAlgorithm    uint8             `json:"dnskeyalgorithm,omitempty"`
+	NaptrOrder         uint16            `json:"naptrorder,omitempty"`
+	NaptrPreference    uint16            `json:"naptrpreference,omitempty"`
+	NaptrFlags         string            `json:"naptrflags,omitempty"`
+	NaptrService       string            `json:"naptrservice,omitempty"`
+	NaptrRegexp        string            `json:"naptrregexp,omitempty"`
+	SshfpAlgorithm     uint8             `json:"sshfpalgorithm,omitempty"`
+	SshfpFingerprint   uint8             `json:"sshfpfingerprint,omitempty"`
+	SmimeaUsage        uint8             `json:"smimeausage,omitempty"`
+	SmimeaSelector     uint8             `json:"smimeaselector,omitempty"`
+	SmimeaMatchingType uint8             `json:"smimeamatchingtype,omitempty"`
+	SoaMbox            string            `json:"soambox,omitempty"`
+	SoaSerial          uint32            `json:"soaserial,omitempty"`
+	SoaRefresh         uint32            `json:"soarefresh,omitempty"`
+	SoaRetry           uint32            `json:"soaretry,omitempty"`
+	SoaExpire          uint32            `json:"soaexpire,omitempty"`
+	SoaMinttl          uint32            `json:"soaminttl,omitempty"`
+	SvcPriority        uint16            `json:"svcpriority,omitempty"`
+	SvcParams          string            `json:"svcparams,omitempty"`
+	TlsaUsage          uint8             `json:"tlsausage,omitempty"`
+	TlsaSelector       uint8             `json:"tlsaselector,omitempty"`
+	TlsaMatchingType   uint8             `json:"tlsamatchingtype,omitempty"`
+	TxtStrings         []string          `json:"txtstrings,omitempty"` // TxtStrings stores all strings (including the first). Target stores all the strings joined.
+	R53Alias           map[string]string `json:"r53_alias,omitempty"`
+	AzureAlias         map[string]string `json:"azure_alias,omitempty"`
 }
 
 // MarshalJSON marshals RecordConfig.
@@ -154,36 +165,45 @@ func (rc *RecordConfig) UnmarshalJSON(b []byte) error {
 		TTL       uint32            `json:"ttl,omitempty"`
 		Metadata  map[string]string `json:"meta,omitempty"`
 		Original  interface{}       `json:"-"` // Store pointer to provider-specific record object. Used in diffing.
-
-		MxPreference     uint16            `json:"mxpreference,omitempty"`
-		SrvPriority      uint16            `json:"srvpriority,omitempty"`
-		SrvWeight        uint16            `json:"srvweight,omitempty"`
-		SrvPort          uint16            `json:"srvport,omitempty"`
-		CaaTag           string            `json:"caatag,omitempty"`
-		CaaFlag          uint8             `json:"caaflag,omitempty"`
-		DsKeyTag         uint16            `json:"dskeytag,omitempty"`
-		DsAlgorithm      uint8             `json:"dsalgorithm,omitempty"`
-		DsDigestType     uint8             `json:"dsdigesttype,omitempty"`
-		DsDigest         string            `json:"dsdigest,omitempty"`
-		NaptrOrder       uint16            `json:"naptrorder,omitempty"`
-		NaptrPreference  uint16            `json:"naptrpreference,omitempty"`
-		NaptrFlags       string            `json:"naptrflags,omitempty"`
-		NaptrService     string            `json:"naptrservice,omitempty"`
-		NaptrRegexp      string            `json:"naptrregexp,omitempty"`
-		SshfpAlgorithm   uint8             `json:"sshfpalgorithm,omitempty"`
-		SshfpFingerprint uint8             `json:"sshfpfingerprint,omitempty"`
-		SoaMbox          string            `json:"soambox,omitempty"`
-		SoaSerial        uint32            `json:"soaserial,omitempty"`
-		SoaRefresh       uint32            `json:"soarefresh,omitempty"`
-		SoaRetry         uint32            `json:"soaretry,omitempty"`
-		SoaExpire        uint32            `json:"soaexpire,omitempty"`
-		SoaMinttl        uint32            `json:"soaminttl,omitempty"`
-		TlsaUsage        uint8             `json:"tlsausage,omitempty"`
-		TlsaSelector     uint8             `json:"tlsaselector,omitempty"`
-		TlsaMatchingType uint8             `json:"tlsamatchingtype,omitempty"`
-		TxtStrings       []string          `json:"txtstrings,omitempty"` // TxtStrings stores all strings (including the first). Target stores only the first one.
-		R53Alias         map[string]string `json:"r53_alias,omitempty"`
-		AzureAlias       map[string]string `json:"azure_alias,omitempty"`
+		Source    string            `json:"source,omitempty"`
+
+		MxPreference       uint16            `json:"mxpreference,omitempty"`
+		SrvPriority        uint16            `json:"srvpriority,omitempty"`
+		SrvWeight          uint16            `json:"srvweight,omitempty"`
+		SrvPort            uint16            `json:"srvport,omitempty"`
+		CaaTag             string            `json:"caatag,omitempty"`
+		CaaFlag            uint8             `json:"caaflag,omitempty"`
+		DsKeyTag           uint16            `json:"dskeytag,omitempty"`
+		DsAlgorithm        uint8             `json:"dsalgorithm,omitempty"`
+		DsDigestType       uint8             `json:"dsdigesttype,omitempty"`
+		DsDigest           string            `json:"dsdigest,omitempty"`
+		DnskeyFlags        uint16            `json:"dnskeyflags,omitempty"`
+		DnskeyProtocol     uint8             `json:"dnskeyprotocol,omitempty"`
+		DnskeyAlgorithm    uint8             `json:"dnskeyalgorithm,omitempty"`
+		NaptrOrder         uint16            `json:"naptrorder,omitempty"`
+		NaptrPreference    uint16            `json:"naptrpreference,omitempty"`
+		NaptrFlags         string            `json:"naptrflags,omitempty"`
+		NaptrService       string            `json:"naptrservice,omitempty"`
+		NaptrRegexp        string            `json:"naptrregexp,omitempty"`
+		SshfpAlgorithm     uint8             `json:"sshfpalgorithm,omitempty"`
+		SshfpFingerprint   uint8             `json:"sshfpfingerprint,omitempty"`
+		SmimeaUsage        uint8             `json:"smimeausage,omitempty"`
+		SmimeaSelector     uint8             `json:"smimeaselector,omitempty"`
+		SmimeaMatchingType uint8             `json:"smimeamatchingtype,omitempty"`
+		SoaMbox            string            `json:"soambox,omitempty"`
+		SoaSerial          uint32            `json:"soaserial,omitempty"`
+		SoaRefresh         uint32            `json:"soarefresh,omitempty"`
+		SoaRetry           uint32            `json:"soaretry,omitempty"`
+		SoaExpire          uint32            `json:"soaexpire,omitempty"`
+		SoaMinttl          uint32            `json:"soaminttl,omitempty"`
+		SvcPriority        uint16            `json:"svcpriority,omitempty"`
+		SvcParams          string            `json:"svcparams,omitempty"`
+		TlsaUsage          uint8             `json:"tlsausage,omitempty"`
+		TlsaSelector       uint8             `json:"tlsaselector,omitempty"`
+		TlsaMatchingType   uint8             `json:"tlsamatchingtype,omitempty"`
+		TxtStrings         []string          `json:"txtstrings,omitempty"` // TxtStrings stores all strings (including the first). Target stores only the first one.
+		R53Alias           map[string]string `json:"r53_alias,omitempty"`
+		AzureAlias         map[string]string `json:"azure_alias,omitempty"`
 		// NB(tlim): If anyone can figure out how to do this without listing all
 		// the fields, please let us know!
 	}{}
@@ -334,6 +354,10 @@ func (rc *RecordConfig) ToDiffable(extraMaps ...map[string]string) string {
 // ToRR converts a RecordConfig to a dns.RR.
 func (rc *RecordConfig) ToRR() dns.RR {
 
+	if IsGenericType(rc.Type) {
+		return rc.genericToRR()
+	}
+
 	// Don't call this on fake types.
 	rdtype, ok := dns.StringToType[rc.Type]
 	if !ok {
@@ -360,11 +384,23 @@ func (rc *RecordConfig) ToRR() dns.RR {
 		rr.(*dns.AAAA).AAAA = rc.GetTargetIP()
 	case dns.TypeCNAME:
 		rr.(*dns.CNAME).Target = rc.GetTargetField()
+	case dns.TypeDNAME:
+		rr.(*dns.DNAME).Target = rc.GetTargetField()
 	case dns.TypeDS:
 		rr.(*dns.DS).Algorithm = rc.DsAlgorithm
 		rr.(*dns.DS).DigestType = rc.DsDigestType
 		rr.(*dns.DS).Digest = rc.DsDigest
 		rr.(*dns.DS).KeyTag = rc.DsKeyTag
+	case dns.TypeCDS:
+		rr.(*dns.CDS).Algorithm = rc.DsAlgorithm
+		rr.(*dns.CDS).DigestType = rc.DsDigestType
+		rr.(*dns.CDS).Digest = rc.DsDigest
+		rr.(*dns.CDS).KeyTag = rc.DsKeyTag
+	case dns.TypeCDNSKEY:
+		rr.(*dns.CDNSKEY).Flags = rc.DnskeyFlags
+		rr.(*dns.CDNSKEY).Protocol = rc.DnskeyProtocol
+		rr.(*dns.CDNSKEY).Algorithm = rc.DnskeyAlgorithm
+		rr.(*dns.CDNSKEY).PublicKey = rc.GetTargetField()
 	case dns.TypePTR:
 		rr.(*dns.PTR).Ptr = rc.GetTargetField()
 	case dns.TypeNAPTR:
@@ -379,6 +415,13 @@ func (rc *RecordConfig) ToRR() dns.RR {
 		rr.(*dns.MX).Mx = rc.GetTargetField()
 	case dns.TypeNS:
 		rr.(*dns.NS).Ns = rc.GetTargetField()
+	case dns.TypeOPENPGPKEY:
+		rr.(*dns.OPENPGPKEY).PublicKey = rc.GetTargetField()
+	case dns.TypeSMIMEA:
+		rr.(*dns.SMIMEA).Usage = rc.SmimeaUsage
+		rr.(*dns.SMIMEA).MatchingType = rc.SmimeaMatchingType
+		rr.(*dns.SMIMEA).Selector = rc.SmimeaSelector
+		rr.(*dns.SMIMEA).Certificate = rc.GetTargetField()
 	case dns.TypeSOA:
 		rr.(*dns.SOA).Ns = rc.GetTargetField()
 		rr.(*dns.SOA).Mbox = rc.SoaMbox
@@ -392,6 +435,14 @@ func (rc *RecordConfig) ToRR() dns.RR {
 		rr.(*dns.SRV).Weight = rc.SrvWeight
 		rr.(*dns.SRV).Port = rc.SrvPort
 		rr.(*dns.SRV).Target = rc.GetTargetField()
+	case dns.TypeSVCB:
+		rr.(*dns.SVCB).Priority = rc.SvcPriority
+		rr.(*dns.SVCB).Target = rc.GetTargetField()
+		rr.(*dns.SVCB).Value, _ = rc.GetSvcParams()
+	case dns.TypeHTTPS:
+		rr.(*dns.HTTPS).Priority = rc.SvcPriority
+		rr.(*dns.HTTPS).Target = rc.GetTargetField()
+		rr.(*dns.HTTPS).Value, _ = rc.GetSvcParams()
 	case dns.TypeSSHFP:
 		rr.(*dns.SSHFP).Algorithm = rc.SshfpAlgorithm
 		rr.(*dns.SSHFP).Type = rc.SshfpFingerprint
@@ -524,10 +575,10 @@ func downcase(recs []*RecordConfig) {
 		r.Name = strings.ToLower(r.Name)
 		r.NameFQDN = strings.ToLower(r.NameFQDN)
 		switch r.Type { // #rtype_variations
-		case "ANAME", "CNAME", "DS", "MX", "NS", "PTR", "NAPTR", "SRV", "TLSA", "AKAMAICDN":
+		case "ANAME", "CNAME", "CDS", "DNAME", "DS", "HTTPS", "SVCB", "MX", "NS", "PTR", "NAPTR", "SMIMEA", "SRV", "TLSA", "AKAMAICDN":
 			// These record types have a target that is case insensitive, so we downcase it.
 			r.target = strings.ToLower(r.target)
-		case "A", "AAAA", "ALIAS", "CAA", "IMPORT_TRANSFORM", "TXT", "SSHFP", "CF_REDIRECT", "CF_TEMP_REDIRECT", "CF_WORKER_ROUTE":
+		case "A", "AAAA", "ALIAS", "CAA", "CDNSKEY", "IMPORT_TRANSFORM", "OPENPGPKEY", "TXT", "SSHFP", "CF_REDIRECT", "CF_TEMP_REDIRECT", "CF_WORKER_ROUTE":
 			// These record types have a target that is case sensitive, or is an IP address. We leave them alone.
 			// Do nothing.
 		case "SOA":
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"net"
+	"path"
+	"strings"
+)
+
+// RecordQuery selects records for FindRecords. Each field that is set
+// must match. Name and Value may be patterns, as in path.Match, such as
+// "*.example.com". Names and hostnames are compared without regard to
+// case or a trailing dot, and IP addresses by the address they parse
+// to, so that "2001:db8::1" matches "2001:DB8:0::1".
+type RecordQuery struct {
+	Name  string // the record's name, fully qualified, such as "www.example.com"
+	Type  string // the record's type, such as "CNAME"
+	Value string // the record's target, such as "203.0.113.10" or "lb.example.net."
+}
+
+// FoundRecord is a record that FindRecords found, and the domain it is in.
+type FoundRecord struct {
+	Domain *DomainConfig
+	Record *RecordConfig
+}
+
+// FindRecords returns the records of all domains that match q, in the
+// order they are in config.
+func (config *DNSConfig) FindRecords(q RecordQuery) []FoundRecord {
+	var found []FoundRecord
+	for _, dc := range config.Domains {
+		for _, rc := range dc.Records {
+			if q.Matches(rc) {
+				found = append(found, FoundRecord{Domain: dc, Record: rc})
+			}
+		}
+	}
+	return found
+}
+
+// Matches reports whether rc matches q.
+func (q RecordQuery) Matches(rc *RecordConfig) bool {
+	if q.Type != "" && !strings.EqualFold(q.Type, rc.Type) {
+		return false
+	}
+	if q.Name != "" && !matchName(q.Name, rc.GetLabelFQDN()) {
+		return false
+	}
+	if q.Value != "" {
+		value := rc.GetTargetField()
+		if rc.Type == "TXT" {
+			value = rc.GetTargetTXTJoined()
+		}
+		if ip := net.ParseIP(q.Value); ip != nil {
+			return ip.Equal(net.ParseIP(value))
+		}
+		if !matchName(q.Value, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchName reports whether name matches pattern, without regard to case
+// or a trailing dot.
+func matchName(pattern, name string) bool {
+	pattern = strings.TrimSuffix(strings.ToLower(pattern), ".")
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	if ok, err := path.Match(pattern, name); err == nil && ok {
+		return true
+	}
+	return pattern == name
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"testing"
+)
+
+func searchConfig() *DNSConfig {
+	rc := func(domain, label, typ, target string) *RecordConfig {
+		r := &RecordConfig{Type: typ}
+		r.SetLabel(label, domain)
+		r.SetTarget(target)
+		return r
+	}
+	return &DNSConfig{Domains: []*DomainConfig{
+		{Name: "example.com", Records: Records{
+			rc("example.com", "@", "A", "203.0.113.10"),
+			rc("example.com", "www", "CNAME", "lb.example.net."),
+			rc("example.com", "v6", "AAAA", "2001:db8::10"),
+		}},
+		{Name: "example.org", Records: Records{
+			rc("example.org", "shop", "A", "203.0.113.10"),
+			rc("example.org", "cdn", "CNAME", "LB.example.net."),
+			rc("example.org", "static", "CNAME", "static.cdn.example.net."),
+			rc("example.org", "mail", "A", "203.0.113.100"),
+		}},
+	}}
+}
+
+func foundNames(found []FoundRecord) []string {
+	var names []string
+	for _, f := range found {
+		names = append(names, f.Record.GetLabelFQDN()+" "+f.Record.Type)
+	}
+	return names
+}
+
+func TestFindRecords(t *testing.T) {
+	config := searchConfig()
+	tests := []struct {
+		desc  string
+		query RecordQuery
+		exp   []string
+	}{
+		{"IP value across domains", RecordQuery{Value: "203.0.113.10"},
+			[]string{"example.com A", "shop.example.org A"}},
+		{"IPv6 value in another form", RecordQuery{Value: "2001:DB8:0::10"},
+			[]string{"v6.example.com AAAA"}},
+		{"target without a trailing dot, in any case", RecordQuery{Type: "cname", Value: "lb.example.net"},
+			[]string{"www.example.com CNAME", "cdn.example.org CNAME"}},
+		{"target pattern", RecordQuery{Value: "*.cdn.example.net"},
+			[]string{"static.example.org CNAME"}},
+		{"name pattern and type", RecordQuery{Name: "*.example.org", Type: "A"},
+			[]string{"shop.example.org A", "mail.example.org A"}},
+		{"no match", RecordQuery{Type: "MX"}, nil},
+	}
+	for _, tst := range tests {
+		t.Run(tst.desc, func(t *testing.T) {
+			got := foundNames(config.FindRecords(tst.query))
+			if len(got) != len(tst.exp) {
+				t.Fatalf("got %v, expected %v", got, tst.exp)
+			}
+			for i := range got {
+				if got[i] != tst.exp[i] {
+					t.Errorf("got %v, expected %v", got, tst.exp)
+					break
+				}
+			}
+		})
+	}
+}
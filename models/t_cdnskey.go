@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SetTargetCDNSKEY sets the CDNSKEY fields. The public key is base64,
+// which is normalized to remove whitespace. The key "AA==" with flags
+// 0, protocol 3 and algorithm 0 asks the parent to delete the DS
+// records (RFC 8078).
+func (rc *RecordConfig) SetTargetCDNSKEY(flags uint16, protocol, algorithm uint8, publickey string) error {
+	publickey = strings.Join(strings.Fields(publickey), "")
+	if _, err := base64.StdEncoding.DecodeString(publickey); err != nil {
+		return fmt.Errorf("CDNSKEY public key is not base64: %w", err)
+	}
+
+	rc.DnskeyFlags = flags
+	rc.DnskeyProtocol = protocol
+	rc.DnskeyAlgorithm = algorithm
+	rc.SetTarget(publickey)
+	if rc.Type == "" {
+		rc.Type = "CDNSKEY"
+	}
+	if rc.Type != "CDNSKEY" {
+		panic("assertion failed: SetTargetCDNSKEY called when .Type is not CDNSKEY")
+	}
+	return nil
+}
+
+// SetTargetCDNSKEYStrings is like SetTargetCDNSKEY but accepts strings.
+func (rc *RecordConfig) SetTargetCDNSKEYStrings(flags, protocol, algorithm, publickey string) (err error) {
+	var i64flags, i64protocol, i64algorithm uint64
+	if i64flags, err = strconv.ParseUint(flags, 10, 16); err == nil {
+		if i64protocol, err = strconv.ParseUint(protocol, 10, 8); err == nil {
+			if i64algorithm, err = strconv.ParseUint(algorithm, 10, 8); err == nil {
+				return rc.SetTargetCDNSKEY(uint16(i64flags), uint8(i64protocol), uint8(i64algorithm), publickey)
+			}
+		}
+	}
+	return fmt.Errorf("CDNSKEY has value that won't fit in field: %w", err)
+}
+
+// SetTargetCDNSKEYString is like SetTargetCDNSKEY but accepts one big string.
+// The public key may be split into several whitespace-separated parts.
+func (rc *RecordConfig) SetTargetCDNSKEYString(s string) error {
+	part := strings.Fields(s)
+	if len(part) < 4 {
+		return fmt.Errorf("CDNSKEY value does not contain 4 fields: (%#v)", s)
+	}
+	return rc.SetTargetCDNSKEYStrings(part[0], part[1], part[2], strings.Join(part[3:], ""))
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SetTargetCDS sets the CDS fields, which are the same as a DS record's.
+// The digest "00" with all other fields 0 asks the parent to delete the
+// DS records (RFC 8078).
+func (rc *RecordConfig) SetTargetCDS(keytag uint16, algorithm, digesttype uint8, digest string) error {
+	rc.DsKeyTag = keytag
+	rc.DsAlgorithm = algorithm
+	rc.DsDigestType = digesttype
+	rc.DsDigest = digest
+
+	if rc.Type == "" {
+		rc.Type = "CDS"
+	}
+	if rc.Type != "CDS" {
+		panic("assertion failed: SetTargetCDS called when .Type is not CDS")
+	}
+
+	return nil
+}
+
+// SetTargetCDSStrings is like SetTargetCDS but accepts strings.
+func (rc *RecordConfig) SetTargetCDSStrings(keytag, algorithm, digesttype, digest string) error {
+	u16keytag, err := strconv.ParseUint(keytag, 10, 16)
+	if err != nil {
+		return errors.Wrap(err, "CDS KeyTag can't fit in 16 bits")
+	}
+	u8algorithm, err := strconv.ParseUint(algorithm, 10, 8)
+	if err != nil {
+		return errors.Wrap(err, "CDS Algorithm can't fit in 8 bits")
+	}
+	u8digesttype, err := strconv.ParseUint(digesttype, 10, 8)
+	if err != nil {
+		return errors.Wrap(err, "CDS DigestType can't fit in 8 bits")
+	}
+
+	return rc.SetTargetCDS(uint16(u16keytag), uint8(u8algorithm), uint8(u8digesttype), digest)
+}
+
+// SetTargetCDSString is like SetTargetCDS but accepts one big string.
+func (rc *RecordConfig) SetTargetCDSString(s string) error {
+	part := strings.Fields(s)
+	if len(part) != 4 {
+		return errors.Errorf("CDS value does not contain 4 fields: (%#v)", s)
+	}
+	return rc.SetTargetCDSStrings(part[0], part[1], part[2], part[3])
+}
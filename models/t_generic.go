@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// IsGenericType returns true if rtype is an RFC 3597 generic type
+// (TYPEnnn) for a type that DNSControl doesn't otherwise know, such as
+// TYPE65534, which some DNSSEC signers use to track their state.
+// Records of these types store their data in the generic format
+// (`\# length hex`).
+func IsGenericType(rtype string) bool {
+	if !strings.HasPrefix(rtype, "TYPE") {
+		return false
+	}
+	n, err := strconv.ParseUint(rtype[len("TYPE"):], 10, 16)
+	if err != nil || n == 0 {
+		return false
+	}
+	_, known := dns.TypeToString[uint16(n)]
+	return !known
+}
+
+// SetTargetGeneric sets the data of a record of a generic type. The
+// data is in the RFC 3597 generic format (`\# length hex`, where the
+// hex may be split by whitespace), and is stored with lowercase hex
+// and no whitespace so that the diff is stable.
+func (rc *RecordConfig) SetTargetGeneric(data string) error {
+	if !IsGenericType(rc.Type) {
+		panic("assertion failed: SetTargetGeneric called when .Type is not a generic type")
+	}
+
+	fields := strings.Fields(data)
+	if len(fields) < 2 || fields[0] != `\#` {
+		return errors.Errorf("%s data %q is not in the generic format (\\# length hex)", rc.Type, data)
+	}
+	length, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return errors.Wrapf(err, "%s generic length is invalid", rc.Type)
+	}
+	rdata, err := hex.DecodeString(strings.Join(fields[2:], ""))
+	if err != nil {
+		return errors.Wrapf(err, "%s generic data is not hex", rc.Type)
+	}
+	if len(rdata) != length {
+		return errors.Errorf("%s generic data is %d bytes, expected %d", rc.Type, len(rdata), length)
+	}
+	if length == 0 {
+		return rc.SetTarget(`\# 0`)
+	}
+	return rc.SetTarget(fmt.Sprintf(`\# %d %s`, length, hex.EncodeToString(rdata)))
+}
+
+// genericToRR is ToRR for records of a generic type.
+func (rc *RecordConfig) genericToRR() dns.RR {
+	n, _ := strconv.ParseUint(rc.Type[len("TYPE"):], 10, 16)
+	rr := &dns.RFC3597{
+		Hdr: dns.RR_Header{
+			Name:   rc.NameFQDN + ".",
+			Rrtype: uint16(n),
+			Class:  dns.ClassINET,
+			Ttl:    rc.TTL,
+		},
+	}
+	if rc.TTL == 0 {
+		rr.Hdr.Ttl = DefaultTTL
+	}
+	if fields := strings.Fields(rc.GetTargetField()); len(fields) > 2 {
+		rr.Rdata = fields[2]
+	}
+	return rr
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SetTargetOPENPGPKEY sets the OPENPGPKEY public key. The key may be
+// given in base64 (the zonefile presentation format, whitespace is
+// ignored) or in the RFC 3597 generic format (`\# length hex`). It
+// is stored as canonical base64 so that the diff is stable no matter
+// how the key was formatted.
+func (rc *RecordConfig) SetTargetOPENPGPKEY(key string) error {
+	if rc.Type == "" {
+		rc.Type = "OPENPGPKEY"
+	}
+	if rc.Type != "OPENPGPKEY" {
+		panic("assertion failed: SetTargetOPENPGPKEY called when .Type is not OPENPGPKEY")
+	}
+
+	data, err := decodeOPENPGPKEY(key)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return errors.Errorf("OPENPGPKEY key is empty")
+	}
+	return rc.SetTarget(base64.StdEncoding.EncodeToString(data))
+}
+
+// decodeOPENPGPKEY decodes a base64 or RFC 3597 OPENPGPKEY key.
+func decodeOPENPGPKEY(key string) ([]byte, error) {
+	fields := strings.Fields(key)
+	if len(fields) >= 2 && fields[0] == `\#` {
+		length, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, errors.Wrap(err, "OPENPGPKEY generic length is invalid")
+		}
+		data, err := hex.DecodeString(strings.Join(fields[2:], ""))
+		if err != nil {
+			return nil, errors.Wrap(err, "OPENPGPKEY generic data is not hex")
+		}
+		if len(data) != length {
+			return nil, errors.Errorf("OPENPGPKEY generic data is %d bytes, expected %d", len(data), length)
+		}
+		return data, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.Join(fields, ""))
+	if err != nil {
+		return nil, errors.Wrap(err, "OPENPGPKEY key is not base64")
+	}
+	return data, nil
+}
@@ -49,16 +49,24 @@ func (rc *RecordConfig) PopulateFromString(rtype, contents, origin string) error
 			return fmt.Errorf("invalid IP in AAAA record: %s", contents)
 		}
 		return rc.SetTargetIP(ip) // Reformat to canonical form.
-	case "AKAMAICDN", "ALIAS", "ANAME", "CNAME", "NS", "PTR":
+	case "AKAMAICDN", "ALIAS", "ANAME", "CNAME", "DNAME", "NS", "PTR":
 		return rc.SetTarget(contents)
 	case "CAA":
 		return rc.SetTargetCAAString(contents)
+	case "CDNSKEY":
+		return rc.SetTargetCDNSKEYString(contents)
+	case "CDS":
+		return rc.SetTargetCDSString(contents)
 	case "DS":
 		return rc.SetTargetDSString(contents)
 	case "MX":
 		return rc.SetTargetMXString(contents)
 	case "NAPTR":
 		return rc.SetTargetNAPTRString(contents)
+	case "OPENPGPKEY":
+		return rc.SetTargetOPENPGPKEY(contents)
+	case "SMIMEA":
+		return rc.SetTargetSMIMEAString(contents)
 	case "SOA":
 		return rc.SetTargetSOAString(contents)
 	case "SPF", "TXT":
@@ -67,9 +75,14 @@ func (rc *RecordConfig) PopulateFromString(rtype, contents, origin string) error
 		return rc.SetTargetSRVString(contents)
 	case "SSHFP":
 		return rc.SetTargetSSHFPString(contents)
+	case "SVCB", "HTTPS":
+		return rc.SetTargetSVCBString(contents)
 	case "TLSA":
 		return rc.SetTargetTLSAString(contents)
 	default:
+		if IsGenericType(rtype) {
+			return rc.SetTargetGeneric(contents)
+		}
 		return fmt.Errorf("unknown rtype (%s) when parsing (%s) domain=(%s)",
 			rtype, contents, origin)
 	}
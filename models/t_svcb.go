@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// SetTargetSVCB sets the SVCB (or HTTPS) fields. A priority of 0 is
+// AliasMode, in which target is an alias and there are no SvcParams;
+// otherwise it is ServiceMode. The SvcParams are sorted by key, as they
+// are on the wire, so that the order they are written in doesn't matter.
+func (rc *RecordConfig) SetTargetSVCB(priority uint16, target string, params []dns.SVCBKeyValue) error {
+	rc.SvcPriority = priority
+	rc.SvcParams = formatSvcParams(params)
+	rc.SetTarget(target)
+	if rc.Type == "" {
+		rc.Type = "SVCB"
+	}
+	if rc.Type != "SVCB" && rc.Type != "HTTPS" {
+		panic("assertion failed: SetTargetSVCB called when .Type is not SVCB or HTTPS")
+	}
+	return nil
+}
+
+// SetTargetSVCBStrings is like SetTargetSVCB but accepts strings. The
+// SvcParams are as in a zonefile, such as `alpn="h2,h3" port=443`.
+func (rc *RecordConfig) SetTargetSVCBStrings(priority, target, params string) error {
+	i64priority, err := strconv.ParseUint(priority, 10, 16)
+	if err != nil {
+		return fmt.Errorf("SVCB priority does not fit in 16 bits: %w", err)
+	}
+	values, err := parseSvcParams(params)
+	if err != nil {
+		return err
+	}
+	return rc.SetTargetSVCB(uint16(i64priority), target, values)
+}
+
+// SetTargetSVCBString is like SetTargetSVCB but accepts one big string.
+func (rc *RecordConfig) SetTargetSVCBString(s string) error {
+	part := strings.Fields(s)
+	if len(part) < 2 {
+		return fmt.Errorf("SVCB value does not contain at least 2 fields: (%#v)", s)
+	}
+	// The SvcParams may contain quoted spaces, so they are the rest of
+	// s rather than the remaining fields.
+	rest := strings.TrimSpace(s)
+	for i := 0; i < 2; i++ {
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, part[i]))
+	}
+	return rc.SetTargetSVCBStrings(part[0], part[1], rest)
+}
+
+// GetSvcParams returns the SvcParams of an SVCB or HTTPS record.
+func (rc *RecordConfig) GetSvcParams() ([]dns.SVCBKeyValue, error) {
+	return parseSvcParams(rc.SvcParams)
+}
+
+// parseSvcParams parses SvcParams as in a zonefile, sorted by key.
+func parseSvcParams(s string) ([]dns.SVCBKeyValue, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	rr, err := dns.NewRR(". 0 IN SVCB 1 . " + s)
+	if err != nil {
+		return nil, fmt.Errorf("SVCB parameters %q are invalid: %w", s, err)
+	}
+	values := rr.(*dns.SVCB).Value
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Key() < values[j].Key() })
+	return values, nil
+}
+
+// formatSvcParams returns the SvcParams as in a zonefile, sorted by key.
+func formatSvcParams(params []dns.SVCBKeyValue) string {
+	params = append([]dns.SVCBKeyValue(nil), params...)
+	sort.SliceStable(params, func(i, j int) bool { return params[i].Key() < params[j].Key() })
+	parts := make([]string, len(params))
+	for i, kv := range params {
+		parts[i] = kv.Key().String() + `="` + kv.String() + `"`
+	}
+	return strings.Join(parts, " ")
+}
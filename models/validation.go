@@ -0,0 +1,26 @@
+package models
+
+// ValidationError is an error found while validating a configuration,
+// with fields that tools can use instead of parsing the message.
+type ValidationError struct {
+	Code    string `json:"code"`             // Identifies the kind of error, such as "unsupported-record-type"
+	Domain  string `json:"domain,omitempty"` // The domain, if the error is about one
+	Record  string `json:"record,omitempty"` // The record's FQDN and type (ex: "www.example.com A"), if the error is about one
+	Source  string `json:"source,omitempty"` // The file and line that declared the record (ex: "dnsconfig.js:12"), if known
+	Message string `json:"message"`          // The error as printed
+}
+
+// Validation error codes.
+const (
+	ErrCodeUnsupportedRecordType = "unsupported-record-type" // A provider can't use a record type
+	ErrCodeRejectedRecord        = "rejected-record"         // A provider's AuditRecords rejected a record
+)
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// RecordIdentity is the record's FQDN and type, as used by ValidationError.
+func RecordIdentity(rc *RecordConfig) string {
+	return rc.GetLabelFQDN() + " " + rc.Type
+}
@@ -58,11 +58,14 @@ func LoadProviderConfigs(fname string) (map[string]map[string]string, error) {
 		}
 	}
 
-	s := string(dat)
-	r := JsonConfigReader.New(strings.NewReader(s))
-	err = json.NewDecoder(r).Decode(&results)
-	if err != nil {
-		return nil, fmt.Errorf("failed parsing provider credentials file %v: %v", fname, err)
+	// A missing file is read as empty, and has no credentials.
+	if len(dat) != 0 {
+		s := string(dat)
+		r := JsonConfigReader.New(strings.NewReader(s))
+		err = json.NewDecoder(r).Decode(&results)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing provider credentials file %v: %v", fname, err)
+		}
 	}
 	if err = replaceEnvVars(results); err != nil {
 		return nil, err
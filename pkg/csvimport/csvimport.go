@@ -0,0 +1,190 @@
+// Package csvimport reads DNS records from CSV files, such as those
+// exported from a spreadsheet.
+package csvimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// The first row of the CSV names the columns, in any order and case.
+// name, type and value are required. Lines starting with # are ignored.
+//
+//	name      The label ("@" or empty for the apex), or a FQDN ending with "."
+//	type      The record type (A, MX, TXT, ...)
+//	ttl       The TTL; models.DefaultTTL if empty
+//	value     The target. For TXT, the text itself, without quotes.
+//	          For other types, anything that is valid in a zonefile.
+//	priority  The MX preference or SRV priority
+//	weight    The SRV weight
+//	port      The SRV port
+var columns = []string{"name", "type", "ttl", "value", "priority", "weight", "port"}
+
+// ParseRecords reads the records of the zone origin from r. If any rows
+// are invalid, it returns an error listing each of them by line number.
+func ParseRecords(r io.Reader, origin string) (models.Records, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("empty CSV file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	index, err := parseHeader(header)
+	if err != nil {
+		line, _ := cr.FieldPos(0)
+		return nil, fmt.Errorf("line %d: %w", line, err)
+	}
+
+	var recs models.Records
+	var errs []string
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		get := func(col string) string {
+			if i, ok := index[col]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		rc, err := parseRow(get, origin)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("line %d: %s", line, err))
+			continue
+		}
+		recs = append(recs, rc)
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+	return recs, nil
+}
+
+// parseHeader returns the index of each column in header.
+func parseHeader(header []string) (map[string]int, error) {
+	index := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !isColumn(name) {
+			return nil, fmt.Errorf("unknown column %q (known columns are %s)", name, strings.Join(columns, ", "))
+		}
+		if _, ok := index[name]; ok {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		index[name] = i
+	}
+	for _, name := range []string{"name", "type", "value"} {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+	return index, nil
+}
+
+func isColumn(name string) bool {
+	for _, c := range columns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRow converts a row of the CSV to a record. get returns the
+// value of a column, or "" if it is empty or absent.
+func parseRow(get func(string) string, origin string) (*models.RecordConfig, error) {
+	rc := &models.RecordConfig{TTL: models.DefaultTTL}
+
+	name, typ, value := get("name"), strings.ToUpper(get("type")), get("value")
+	if typ == "" {
+		return nil, fmt.Errorf("missing type")
+	}
+	if value == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+	switch {
+	case name == "":
+		rc.SetLabel("@", origin)
+	case strings.HasSuffix(name, "."):
+		if name != origin+"." && !strings.HasSuffix(name, "."+origin+".") {
+			return nil, fmt.Errorf("name %q is not in zone %s", name, origin)
+		}
+		rc.SetLabelFromFQDN(name, origin)
+	default:
+		rc.SetLabel(name, origin)
+	}
+
+	if ttl := get("ttl"); ttl != "" {
+		n, err := strconv.ParseUint(ttl, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ttl %q", ttl)
+		}
+		rc.TTL = uint32(n)
+	}
+
+	priority, err := parseUint16(get, "priority")
+	if err != nil {
+		return nil, err
+	}
+	weight, err := parseUint16(get, "weight")
+	if err != nil {
+		return nil, err
+	}
+	port, err := parseUint16(get, "port")
+	if err != nil {
+		return nil, err
+	}
+
+	rc.Type = typ
+	switch {
+	case typ == "TXT":
+		err = rc.SetTargetTXT(value)
+	case typ == "MX" && priority != nil:
+		err = rc.SetTargetMX(*priority, value)
+	case typ == "SRV" && priority != nil:
+		if weight == nil || port == nil {
+			return nil, fmt.Errorf("SRV needs priority, weight and port")
+		}
+		err = rc.SetTargetSRV(*priority, *weight, *port, value)
+	default:
+		rc.Type = ""
+		err = rc.PopulateFromString(typ, value, origin)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", typ, value, err)
+	}
+	return rc, nil
+}
+
+// parseUint16 returns the value of the column col, or nil if it is empty.
+func parseUint16(get func(string) string, col string) (*uint16, error) {
+	s := get(col)
+	if s == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q", col, s)
+	}
+	v := uint16(n)
+	return &v, nil
+}
@@ -0,0 +1,73 @@
+package csvimport
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRecords(t *testing.T) {
+	const data = `Name,Type,TTL,Value,Priority
+# Mail
+@,MX,3600,mx1.example.com.,10
+,MX,,mx2.example.com.,20
+@,TXT,,"v=spf1 include:_spf.example.com ~all",
+www,A,300,192.0.2.1,
+www.example.com.,A,300,192.0.2.2,
+_dmarc,TXT,,"v=DMARC1; p=reject",
+`
+	recs, err := ParseRecords(strings.NewReader(data), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, rc := range recs {
+		got = append(got, rc.GetLabel()+" "+rc.Type+" "+rc.ToDiffable())
+	}
+	exp := []string{
+		"@ MX 10 mx1.example.com. ttl=3600",
+		"@ MX 20 mx2.example.com. ttl=300",
+		`@ TXT "v=spf1 include:_spf.example.com ~all" ttl=300`,
+		"www A 192.0.2.1 ttl=300",
+		"www A 192.0.2.2 ttl=300",
+		`_dmarc TXT "v=DMARC1; p=reject" ttl=300`,
+	}
+	if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+}
+
+func TestParseRecordsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		errs []string
+	}{
+		{"name,type\n", []string{`line 1: missing column "value"`}},
+		{"name,type,value,comment\n", []string{`line 1: unknown column "comment"`}},
+		{
+			"name,type,ttl,value,priority\n" +
+				"www,A,300,192.0.2.1,\n" +
+				"www,A,300,not-an-ip,\n" +
+				"@,MX,300,mx.example.com.,high\n" +
+				"www,A,forever,192.0.2.1,\n" +
+				"www.example.net.,A,300,192.0.2.1,\n",
+			[]string{
+				"line 3: A not-an-ip:",
+				`line 4: invalid priority "high"`,
+				`line 5: invalid ttl "forever"`,
+				`line 6: name "www.example.net." is not in zone example.com`,
+			},
+		},
+	} {
+		_, err := ParseRecords(strings.NewReader(tc.data), "example.com")
+		if err == nil {
+			t.Errorf("%q: expected error, got none", tc.data)
+			continue
+		}
+		for _, e := range tc.errs {
+			if !strings.Contains(err.Error(), e) {
+				t.Errorf("%q: error %q doesn't contain %q", tc.data, err, e)
+			}
+		}
+	}
+}
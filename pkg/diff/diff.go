@@ -2,8 +2,10 @@ package diff
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"sort"
+	"strings"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
 	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
@@ -40,6 +42,8 @@ func New(dc *models.DomainConfig, extraValues ...func(*models.RecordConfig) map[
 
 		// compile IGNORE_TARGET glob patterns
 		compiledIgnoredTargets: compileIgnoredTargets(dc.IgnoredTargets),
+
+		ignoredFields: parseIgnoredFields(dc.Metadata["diff_ignore"]),
 	}
 }
 
@@ -56,6 +60,10 @@ type differ struct {
 
 	compiledIgnoredNames   []ignoredName
 	compiledIgnoredTargets []glob.Glob
+
+	// ignoredFields are the fields whose differences don't change a
+	// record: "ttl", or keys of the extraValues maps.
+	ignoredFields map[string]bool
 }
 
 // get normalized content for record. target, ttl, mxprio, and specified metadata
@@ -71,6 +79,50 @@ func (d *differ) content(r *models.RecordConfig) string {
 	return r.ToDiffable(allMaps...)
 }
 
+// comparable is like content, but leaves out the ignored fields. Two
+// records with the same comparable content don't need to be changed.
+func (d *differ) comparable(r *models.RecordConfig) string {
+	if len(d.ignoredFields) == 0 {
+		return d.content(r)
+	}
+	if d.ignoredFields["ttl"] {
+		c := *r
+		c.TTL = 0
+		r = &c
+	}
+	var allMaps []map[string]string
+	for _, f := range d.extraValues {
+		allMaps = append(allMaps, d.withoutIgnored(f(r)))
+	}
+	return r.ToDiffable(allMaps...)
+}
+
+// withoutIgnored returns m without the keys in ignoredFields.
+func (d *differ) withoutIgnored(m map[string]string) map[string]string {
+	if len(d.ignoredFields) == 0 || m == nil {
+		return m
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		if !d.ignoredFields[k] {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// parseIgnoredFields parses the comma-separated list of fields in the
+// diff_ignore domain metadata.
+func parseIgnoredFields(s string) map[string]bool {
+	fields := map[string]bool{}
+	for _, f := range spaceCommaTokenizerRegexp.Split(s, -1) {
+		if f != "" {
+			fields[f] = true
+		}
+	}
+	return fields
+}
+
 func apexException(rec *models.RecordConfig) bool {
 	// Providers often add NS and SOA records at the apex. These
 	// should not be included in certain checks.
@@ -88,6 +140,17 @@ func ignoreNameException(rec *models.RecordConfig) bool {
 	return ok
 }
 
+// unmatched returns the records that aren't matched, in order.
+func unmatched(recs []*models.RecordConfig, matched []bool) []*models.RecordConfig {
+	var result []*models.RecordConfig
+	for i, rec := range recs {
+		if !matched[i] {
+			result = append(result, rec)
+		}
+	}
+	return result
+}
+
 func (d *differ) IncrementalDiff(existing []*models.RecordConfig) (unchanged, create, toDelete, modify Changeset, err error) {
 	unchanged = Changeset{}
 	create = Changeset{}
@@ -152,18 +215,28 @@ func (d *differ) IncrementalDiff(existing []*models.RecordConfig) (unchanged, cr
 		desiredRecords := desiredByNameAndType[key]
 
 		// Very first, get rid of any identical records. Easy.
+		// Each record's content is only computed once, so that this
+		// stays fast for record sets with hundreds of records.
+		desiredIndexes := map[string][]int{} // by content, in order
+		for j, de := range desiredRecords {
+			c := d.comparable(de)
+			desiredIndexes[c] = append(desiredIndexes[c], j)
+		}
+		existingMatched := make([]bool, len(existingRecords))
+		desiredMatched := make([]bool, len(desiredRecords))
 		for i := len(existingRecords) - 1; i >= 0; i-- {
 			ex := existingRecords[i]
-			for j, de := range desiredRecords {
-				if d.content(de) != d.content(ex) {
-					continue
-				}
-				unchanged = append(unchanged, Correlation{d, ex, de})
-				existingRecords = existingRecords[:i+copy(existingRecords[i:], existingRecords[i+1:])]
-				desiredRecords = desiredRecords[:j+copy(desiredRecords[j:], desiredRecords[j+1:])]
-				break
+			c := d.comparable(ex)
+			js := desiredIndexes[c]
+			if len(js) == 0 {
+				continue
 			}
+			unchanged = append(unchanged, Correlation{d, ex, desiredRecords[js[0]]})
+			existingMatched[i], desiredMatched[js[0]] = true, true
+			desiredIndexes[c] = js[1:]
 		}
+		existingRecords = unmatched(existingRecords, existingMatched)
+		desiredRecords = unmatched(desiredRecords, desiredMatched)
 
 		// Next, match by target. This will give the most natural modifications.
 		for i := len(existingRecords) - 1; i >= 0; i-- {
@@ -184,7 +257,7 @@ func (d *differ) IncrementalDiff(existing []*models.RecordConfig) (unchanged, cr
 		existingLookup := map[string]*models.RecordConfig{}
 		// build index based on normalized content data
 		for _, ex := range existingRecords {
-			normalized := d.content(ex)
+			normalized := d.comparable(ex)
 			//fmt.Printf("DEBUG: normalized: %v\n", normalized)
 			// NB(tlim): Commenting this out. If the provider is returning
 			// records that are exact duplicates, that's bad and against the
@@ -200,7 +273,7 @@ func (d *differ) IncrementalDiff(existing []*models.RecordConfig) (unchanged, cr
 			existingLookup[normalized] = ex
 		}
 		for _, de := range desiredRecords {
-			normalized := d.content(de)
+			normalized := d.comparable(de)
 			if desiredLookup[normalized] != nil {
 				return nil, nil, nil, nil, fmt.Errorf("DUPLICATE D_RECORD FOUND: %s %s", key, normalized)
 			}
@@ -334,14 +407,96 @@ func DebugKeyMapMap(note string, m map[models.RecordKey][]string) {
 	}
 }
 
+// String describes the correction, followed by the file and line that
+// declared the desired record, if known.
 func (c Correlation) String() string {
 	if c.Existing == nil {
-		return fmt.Sprintf("CREATE %s %s %s", c.Desired.Type, c.Desired.GetLabelFQDN(), c.d.content(c.Desired))
+		return fmt.Sprintf("CREATE %s %s %s", c.Desired.Type, c.Desired.GetLabelFQDN(), c.d.content(c.Desired)) + sourceSuffix(c.Desired)
 	}
 	if c.Desired == nil {
 		return fmt.Sprintf("DELETE %s %s %s", c.Existing.Type, c.Existing.GetLabelFQDN(), c.d.content(c.Existing))
 	}
-	return fmt.Sprintf("MODIFY %s %s: (%s) -> (%s)", c.Existing.Type, c.Existing.GetLabelFQDN(), c.d.content(c.Existing), c.d.content(c.Desired))
+	return fmt.Sprintf("MODIFY %s %s: (%s) -> (%s)", c.Existing.Type, c.Existing.GetLabelFQDN(), c.d.content(c.Existing), c.d.content(c.Desired)) + caseOnlySuffix(c.Existing, c.Desired) + sourceSuffix(c.Desired)
+}
+
+// caseOnlySuffix explains a change to a TXT record whose text only
+// differs in case, which looks like no change at all. TXT records are
+// compared exactly, since their text is case-sensitive.
+func caseOnlySuffix(existing, desired *models.RecordConfig) string {
+	if existing.Type != "TXT" && existing.Type != "SPF" {
+		return ""
+	}
+	e, d := strings.Join(existing.TxtStrings, ""), strings.Join(desired.TxtStrings, "")
+	if e == d || !strings.EqualFold(e, d) {
+		return ""
+	}
+	return " (only the case of the text differs; TXT records are case-sensitive)"
+}
+
+// sourceSuffix is " (file:line)" if the record's source is known.
+func sourceSuffix(rc *models.RecordConfig) string {
+	if rc.Source == "" {
+		return ""
+	}
+	return " (" + rc.Source + ")"
+}
+
+// Reasons returns tags describing why the record changed: "added" or
+// "removed" if it was created or deleted, otherwise one or more of
+// "ttl", "target" and "meta" (the differ's extra values).
+func (c Correlation) Reasons() []string {
+	if c.Existing == nil {
+		return []string{"added"}
+	}
+	if c.Desired == nil {
+		return []string{"removed"}
+	}
+	var reasons []string
+	if c.Existing.TTL != c.Desired.TTL && !c.d.ignoredFields["ttl"] {
+		reasons = append(reasons, "ttl")
+	}
+	existing, desired := *c.Existing, *c.Desired
+	existing.TTL, desired.TTL = 0, 0
+	if existing.ToDiffable() != desired.ToDiffable() {
+		reasons = append(reasons, "target")
+	}
+	for _, f := range c.d.extraValues {
+		if !reflect.DeepEqual(c.d.withoutIgnored(f(c.Existing)), c.d.withoutIgnored(f(c.Desired))) {
+			reasons = append(reasons, "meta")
+			break
+		}
+	}
+	return reasons
+}
+
+// Explain describes in a sentence what happens to the record and why,
+// such as "www.example.com A 192.0.2.1 is updated because the TTL
+// differs (300 -> 600)".
+func (c Correlation) Explain() string {
+	describe := func(rc *models.RecordConfig) string {
+		return fmt.Sprintf("%s %s %s", rc.GetLabelFQDN(), rc.Type, rc.GetTargetCombined())
+	}
+	if c.Existing == nil {
+		return describe(c.Desired) + " is created because it is in the configuration but not in the zone"
+	}
+	if c.Desired == nil {
+		return describe(c.Existing) + " is deleted because it is in the zone but not in the configuration"
+	}
+	var why []string
+	for _, reason := range c.Reasons() {
+		switch reason {
+		case "ttl":
+			why = append(why, fmt.Sprintf("the TTL differs (%d -> %d)", c.Existing.TTL, c.Desired.TTL))
+		case "target":
+			why = append(why, fmt.Sprintf("the value differs (%s -> %s)", c.Existing.GetTargetCombined(), c.Desired.GetTargetCombined()))
+		case "meta":
+			why = append(why, "the provider-specific settings differ")
+		}
+	}
+	if len(why) == 0 {
+		return describe(c.Desired) + " is kept because it matches the configuration"
+	}
+	return describe(c.Existing) + " is updated because " + strings.Join(why, " and ")
 }
 
 func sortedKeys(m map[string]*models.RecordConfig) []string {
@@ -372,3 +372,89 @@ func TestCaas(t *testing.T) {
 
 	checkLengthsFull(t, existing, desired, 3, 0, 0, 0, false, nil, nil)
 }
+
+func TestReasons(t *testing.T) {
+	getMeta := func(r *models.RecordConfig) map[string]string {
+		return map[string]string{"k": r.Metadata["k"]}
+	}
+	for _, tc := range []struct {
+		existing, desired string
+		meta              bool
+		reasons           string
+	}{
+		{"www A 1 1.1.1.1", "www A 10 1.1.1.1", false, "ttl"},
+		{"www A 1 1.1.1.1", "www A 1 2.2.2.2", false, "target"},
+		{"www A 1 1.1.1.1", "www A 10 2.2.2.2", false, "ttl,target"},
+		{"www A 1 1.1.1.1", "www A 1 1.1.1.1", true, "meta"},
+		{"", "www A 1 1.1.1.1", false, "added"},
+		{"www A 1 1.1.1.1", "", false, "removed"},
+	} {
+		var existing, desired []*models.RecordConfig
+		if tc.existing != "" {
+			existing = append(existing, myRecord(tc.existing))
+		}
+		if tc.desired != "" {
+			desired = append(desired, myRecord(tc.desired))
+			if tc.meta {
+				desired[0].Metadata["k"] = "changed"
+			}
+		}
+		dc := &models.DomainConfig{Name: "example.com", Records: desired}
+		_, cre, del, mod, err := New(dc, getMeta).IncrementalDiff(existing)
+		if err != nil {
+			t.Fatal(err)
+		}
+		changes := append(append(cre, del...), mod...)
+		if len(changes) != 1 {
+			t.Fatalf("%s -> %s: got %d changes, expected 1", tc.existing, tc.desired, len(changes))
+		}
+		if got := strings.Join(changes[0].Reasons(), ","); got != tc.reasons {
+			t.Errorf("%s -> %s: got reasons %q, expected %q", tc.existing, tc.desired, got, tc.reasons)
+		}
+	}
+}
+
+func TestIgnoredFields(t *testing.T) {
+	getMeta := func(r *models.RecordConfig) map[string]string {
+		return map[string]string{"k": r.Metadata["k"], "id": r.Metadata["id"]}
+	}
+	for _, tc := range []struct {
+		ignore            string
+		existing, desired string
+		existingID        string
+		changes           int
+	}{
+		{"", "www A 1 1.1.1.1", "www A 10 1.1.1.1", "", 1},
+		{"ttl", "www A 1 1.1.1.1", "www A 10 1.1.1.1", "", 0},
+		{"ttl", "www A 1 1.1.1.1", "www A 10 2.2.2.2", "", 1},
+		{"", "www A 1 1.1.1.1", "www A 1 1.1.1.1", "123", 1},
+		{"id", "www A 1 1.1.1.1", "www A 1 1.1.1.1", "123", 0},
+		{"ttl, id", "www A 1 1.1.1.1", "www A 10 1.1.1.1", "123", 0},
+		{"k", "www A 1 1.1.1.1", "www A 1 1.1.1.1", "123", 1},
+	} {
+		existing := myRecord(tc.existing)
+		existing.Metadata["id"] = tc.existingID
+		desired := myRecord(tc.desired)
+		dc := &models.DomainConfig{
+			Name:     "example.com",
+			Records:  []*models.RecordConfig{desired},
+			Metadata: map[string]string{"diff_ignore": tc.ignore},
+		}
+		_, cre, del, mod, err := New(dc, getMeta).IncrementalDiff([]*models.RecordConfig{existing})
+		if err != nil {
+			t.Fatal(err)
+		}
+		changes := append(append(cre, del...), mod...)
+		if len(changes) != tc.changes {
+			t.Errorf("diff_ignore=%q: %s (id=%s) -> %s: got %d changes, expected %d", tc.ignore, tc.existing, tc.existingID, tc.desired, len(changes), tc.changes)
+			continue
+		}
+		for _, c := range changes {
+			for _, reason := range c.Reasons() {
+				if (reason == "ttl" && tc.ignore == "ttl") || (reason == "meta" && tc.ignore == "id") {
+					t.Errorf("diff_ignore=%q: got ignored reason %q", tc.ignore, reason)
+				}
+			}
+		}
+	}
+}
@@ -10,17 +10,24 @@ var conf = {
     dns_providers: [],
     domains: [],
     domain_names: [],
+    txt_fragments: {},
 };
 
 var defaultArgs = [];
 
+// Assertions are checked by _checkAssertions() once dnsconfig.js has
+// run, so that they also see the records added by D_EXTEND().
+var assertions = [];
+
 function initialize() {
     conf = {
         registrars: [],
         dns_providers: [],
         domains: [],
+        txt_fragments: {},
     };
     defaultArgs = [];
+    assertions = [];
 }
 
 function _isDomain(d) {
@@ -91,6 +98,8 @@ function oldNewDnsProvider(name, type, meta) {
 function newDomain(name, registrar) {
     return {
         name: name,
+        // _sourceLocation is only defined by pkg/js, not by other users of helpers.js.
+        source: typeof _sourceLocation === 'function' ? _sourceLocation() : '',
         subdomain: '',
         registrar: registrar,
         meta: {},
@@ -197,6 +206,36 @@ function DEFAULTS() {
     }
 }
 
+// TXT_FRAGMENT(name, value) defines text that TXT records can include
+// as {{name}}. The value may be a string, or a list of strings to be
+// joined with ' '. References are expanded when the config is
+// normalized, so a fragment may be defined after the records using it.
+function TXT_FRAGMENT(name, value) {
+    if (!_.isString(name) || !/^[^{}\s]+$/.test(name)) {
+        throw 'TXT_FRAGMENT name must be a string without spaces or braces';
+    }
+    if (_.isArray(value)) {
+        value = value.join(' ');
+    }
+    if (!_.isString(value)) {
+        throw 'TXT_FRAGMENT value must be a string or a list of strings';
+    }
+    if (conf.txt_fragments[name] !== undefined) {
+        throw 'TXT_FRAGMENT ' + name + ' is already defined';
+    }
+    conf.txt_fragments[name] = value;
+}
+
+// ENV(name, ...): Only manage a domain or record when dnscontrol is run
+// with --env set to one of the given environments.
+function ENV() {
+    var envs = _.flatten(arguments);
+    if (envs.length === 0) {
+        throw 'ENV needs at least one environment name';
+    }
+    return { env: envs.join(',') };
+}
+
 // TTL(v): Set the TTL for a DNS record.
 function TTL(v) {
     if (_.isString(v)) {
@@ -207,23 +246,27 @@ function TTL(v) {
     };
 }
 
+// stringToDuration converts a duration such as "300", "5m" or "1h30m"
+// to seconds.
 function stringToDuration(v) {
-    var matches = v.match(/^(\d+)([smhdwny]?)$/);
-    if (matches == null) {
+    if (!v.match(/^(\d+|(\d+[smhdwny])+)$/)) {
         throw v + ' is not a valid duration string';
     }
-    unit = 's';
-    if (matches[2]) {
-        unit = matches[2];
-    }
-    v = parseInt(matches[1]);
     var u = { s: 1, m: 60, h: 3600 };
     u['d'] = u.h * 24;
     u['w'] = u.d * 7;
     u['n'] = u.d * 30;
     u['y'] = u.d * 365;
-    v *= u[unit];
-    return v;
+    var total = 0;
+    var re = /(\d+)([smhdwny]?)/g;
+    var matches;
+    while ((matches = re.exec(v)) !== null) {
+        total += parseInt(matches[1]) * u[matches[2] || 's'];
+    }
+    if (total > 2147483647) {
+        throw v + ' is longer than the maximum TTL (2147483647 seconds)';
+    }
+    return total;
 }
 
 // DefaultTTL(v): Set the default TTL for the domain.
@@ -385,6 +428,46 @@ var DS = recordBuilder("DS", {
     },
 });
 
+// CDS(name, keytag, algorithm, digestype, digest)
+var CDS = recordBuilder("CDS", {
+    args: [
+        ['name', _.isString],
+        ['keytag', _.isNumber],
+        ['algorithm', _.isNumber],
+        ['digesttype', _.isNumber],
+        ['digest', _.isString]
+    ],
+    transform: function(record, args, modifiers) {
+        record.name = args.name;
+        record.dskeytag = args.keytag;
+        record.dsalgorithm = args.algorithm;
+        record.dsdigesttype = args.digesttype;
+        record.dsdigest = args.digest;
+        record.target = args.target;
+    },
+});
+
+// CDNSKEY(name, flags, protocol, algorithm, publickey)
+var CDNSKEY = recordBuilder("CDNSKEY", {
+    args: [
+        ['name', _.isString],
+        ['flags', _.isNumber],
+        ['protocol', _.isNumber],
+        ['algorithm', _.isNumber],
+        ['target', _.isString], // recordBuilder needs a "target" argument
+    ],
+    transform: function(record, args, modifiers) {
+        record.name = args.name;
+        record.dnskeyflags = args.flags;
+        record.dnskeyprotocol = args.protocol;
+        record.dnskeyalgorithm = args.algorithm;
+        record.target = args.target;
+    },
+});
+
+// DNAME(name,target, recordModifiers...)
+var DNAME = recordBuilder('DNAME');
+
 // PTR(name,target, recordModifiers...)
 var PTR = recordBuilder('PTR');
 
@@ -442,6 +525,11 @@ var SRV = recordBuilder('SRV', {
         ['target', _.isString],
     ],
     transform: function(record, args, modifiers) {
+        _.each(['priority', 'weight', 'port'], function(f) {
+            if (args[f] % 1 !== 0 || args[f] < 0 || args[f] > 65535) {
+                throw 'SRV ' + f + ' must be between 0 and 65535, got ' + args[f];
+            }
+        });
         record.name = args.name;
         record.srvpriority = args.priority;
         record.srvweight = args.weight;
@@ -450,6 +538,27 @@ var SRV = recordBuilder('SRV', {
     },
 });
 
+// OPENPGPKEY(name,key, recordModifiers...)
+var OPENPGPKEY = recordBuilder('OPENPGPKEY');
+
+// SMIMEA(name,usage,selector,matchingtype,certificate, recordModifiers...)
+var SMIMEA = recordBuilder('SMIMEA', {
+    args: [
+        ['name', _.isString],
+        ['usage', _.isNumber],
+        ['selector', _.isNumber],
+        ['matchingtype', _.isNumber],
+        ['target', _.isString], // recordBuilder needs a "target" argument
+    ],
+    transform: function(record, args, modifiers) {
+        record.name = args.name;
+        record.smimeausage = args.usage;
+        record.smimeaselector = args.selector;
+        record.smimeamatchingtype = args.matchingtype;
+        record.target = args.target;
+    },
+});
+
 // SSHFP(name,algorithm,type,value, recordModifiers...)
 var SSHFP = recordBuilder('SSHFP', {
     args: [
@@ -466,6 +575,31 @@ var SSHFP = recordBuilder('SSHFP', {
     },
 });
 
+// svcbBuilder returns the builder of SVCB and HTTPS records:
+// SVCB(name, priority, target, params, recordModifiers...)
+function svcbBuilder(type) {
+    return recordBuilder(type, {
+        args: [
+            ['name', _.isString],
+            ['priority', _.isNumber],
+            ['target', _.isString],
+            ['params', _.isString],
+        ],
+        transform: function(record, args, modifiers) {
+            if (args.priority % 1 !== 0 || args.priority < 0 || args.priority > 65535) {
+                throw type + ' priority must be between 0 and 65535, got ' + args.priority;
+            }
+            record.name = args.name;
+            record.svcpriority = args.priority;
+            record.target = args.target;
+            record.svcparams = args.params;
+        },
+    });
+}
+
+var SVCB = svcbBuilder('SVCB');
+var HTTPS = svcbBuilder('HTTPS');
+
 // name, usage, selector, matchingtype, certificate
 var TLSA = recordBuilder('TLSA', {
     args: [
@@ -632,6 +766,121 @@ function NO_PURGE(d) {
     d.KeepUnknown = true;
 }
 
+// _assertion(description, check) returns a domain modifier that adds
+// an assertion about the domain. check(d) returns '' if it holds for
+// the domain d, and otherwise what is wrong.
+function _assertion(description, check) {
+    // _sourceLocation is only defined by pkg/js, not by other users of helpers.js.
+    var source =
+        typeof _sourceLocation === 'function' ? _sourceLocation() : '';
+    return function(d) {
+        assertions.push({
+            domain: d,
+            description: description,
+            source: source,
+            check: check,
+        });
+    };
+}
+
+// _assertionLabel returns name relative to the domain, in lower case,
+// with '@' for the apex.
+function _assertionLabel(name, domain) {
+    name = name.toLowerCase();
+    domain = domain.toLowerCase();
+    if (name === domain + '.') {
+        return '@';
+    }
+    if (name.substr(-(domain.length + 2)) === '.' + domain + '.') {
+        return name.substr(0, name.length - domain.length - 2);
+    }
+    return name;
+}
+
+// _assertionTarget returns target in lower case, without a trailing dot.
+function _assertionTarget(target) {
+    return String(target).toLowerCase().replace(/\.$/, '');
+}
+
+// _assertionRecords returns the records of type at name in the domain d.
+function _assertionRecords(d, name, type) {
+    var label = _assertionLabel(name, d.name);
+    return _.filter(d.records, function(r) {
+        return r.type === type && _assertionLabel(r.name, d.name) === label;
+    });
+}
+
+// ASSERT_COUNT(name, type, count): the domain must have exactly count
+// records of type at name.
+function ASSERT_COUNT(name, type, count) {
+    if (!_.isString(name) || !_.isString(type) || !_.isNumber(count)) {
+        throw 'ASSERT_COUNT needs a name, a type and a count';
+    }
+    return _assertion(
+        'ASSERT_COUNT(' + JSON.stringify(name) + ', ' + JSON.stringify(type) + ', ' + count + ')',
+        function(d) {
+            var found = _assertionRecords(d, name, type);
+            if (found.length === count) {
+                return '';
+            }
+            return 'there are ' + found.length + ' ' + type + ' records at ' + name;
+        }
+    );
+}
+
+// ASSERT_INCLUDES(name, type, target): one of the records of type at
+// name must have target.
+function ASSERT_INCLUDES(name, type, target) {
+    if (!_.isString(name) || !_.isString(type) || !_.isString(target)) {
+        throw 'ASSERT_INCLUDES needs a name, a type and a target';
+    }
+    return _assertion(
+        'ASSERT_INCLUDES(' + JSON.stringify(name) + ', ' + JSON.stringify(type) + ', ' + JSON.stringify(target) + ')',
+        function(d) {
+            var found = _assertionRecords(d, name, type);
+            for (var i = 0; i < found.length; i++) {
+                if (_assertionTarget(found[i].target) === _assertionTarget(target)) {
+                    return '';
+                }
+            }
+            var targets = _.map(found, function(r) {
+                return r.target;
+            });
+            return 'the ' + type + ' records at ' + name + ' are [' + targets.join(', ') + ']';
+        }
+    );
+}
+
+// ASSERT(description, check): check(d) must return true for the
+// domain d, as it is once dnsconfig.js has run.
+function ASSERT(description, check) {
+    if (!_.isString(description) || !_.isFunction(check)) {
+        throw 'ASSERT needs a description and a function';
+    }
+    return _assertion('ASSERT(' + JSON.stringify(description) + ')', function(d) {
+        return check(d) ? '' : 'the check returned false';
+    });
+}
+
+// _checkAssertions checks the assertions, and throws an error listing
+// the ones that don't hold, if any. It is run once dnsconfig.js has run.
+function _checkAssertions() {
+    var failed = [];
+    for (var i = 0; i < assertions.length; i++) {
+        var a = assertions[i];
+        var msg = a.check(a.domain);
+        if (msg) {
+            failed.push(
+                a.domain.name + ': ' + a.description + ' failed: ' + msg +
+                    (a.source ? ' (' + a.source + ')' : '')
+            );
+        }
+    }
+    if (failed.length) {
+        throw failed.join('\n');
+    }
+}
+
 // AUTODNSSEC
 // Permitted values are:
 // ""  Do not modify the setting (the default)
@@ -707,6 +956,9 @@ function recordBuilder(type, opts) {
     return function() {
         var parsedArgs = {};
         var modifiers = [];
+        // _sourceLocation is only defined by pkg/js, not by other users of helpers.js.
+        var source =
+            typeof _sourceLocation === 'function' ? _sourceLocation() : '';
 
         if (arguments.length < opts.args.length) {
             var argumentsList = opts.args
@@ -753,6 +1005,10 @@ function recordBuilder(type, opts) {
                 ttl: d.defaultTTL,
             };
 
+            if (source) {
+                record.source = source;
+            }
+
             opts.applyModifier(record, modifiers);
             opts.transform(record, parsedArgs, modifiers);
 
@@ -1,11 +1,14 @@
 package js
 
 import (
+	"bufio"
+	"bytes"
 	_ "embed" // Used to embed helpers.js in the binary.
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -35,6 +38,9 @@ var currentDirectory string
 // EnableFetch sets whether to enable fetch() in JS execution environment
 var EnableFetch bool = false
 
+// EnableExec sets whether to enable DATA_COMMAND() in JS execution environment
+var EnableExec bool = false
+
 // ExecuteJavascript accepts a javascript file and runs it, returning the resulting dnsConfig.
 func ExecuteJavascript(file string, devMode bool, variables map[string]string) (*models.DNSConfig, error) {
 	script, err := os.ReadFile(file)
@@ -66,6 +72,9 @@ func ExecuteJavascript(file string, devMode bool, variables map[string]string) (
 	vm.Set("REV", reverse)
 	vm.Set("glob", listFiles) // used for require_glob()
 	vm.Set("PANIC", jsPanic)
+	vm.Set("DATA", data)
+	vm.Set("DATA_COMMAND", dataCommand)
+	vm.Set("_sourceLocation", sourceLocation) // used by recordBuilder()
 
 	// add cli variables to otto
 	for key, value := range variables {
@@ -74,12 +83,20 @@ func ExecuteJavascript(file string, devMode bool, variables map[string]string) (
 
 	helperJs := GetHelpers(devMode)
 	// run helper script to prime vm and initialize variables
-	if err := l.Eval(helperJs); err != nil {
+	helperScript, err := vm.Compile(helpersJsFileName, helperJs)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.Eval(helperScript); err != nil {
 		return nil, err
 	}
 
-	// run user script
-	if err := l.Eval(script); err != nil {
+	// run user script, named so that records know where they came from
+	userScript, err := vm.Compile(file, script)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.Eval(userScript); err != nil {
 		return nil, err
 	}
 
@@ -88,6 +105,11 @@ func ExecuteJavascript(file string, devMode bool, variables map[string]string) (
 		return nil, err
 	}
 
+	// check the assertions, now that all the records are known
+	if _, err := vm.Run(`_checkAssertions()`); err != nil {
+		return nil, err
+	}
+
 	// export conf as string and unmarshal
 	value, err := vm.Run(`JSON.stringify(conf)`)
 	if err != nil {
@@ -101,6 +123,18 @@ func ExecuteJavascript(file string, devMode bool, variables map[string]string) (
 	if err = json.Unmarshal([]byte(str), conf); err != nil {
 		return nil, err
 	}
+	// The domains' sources aren't part of DomainConfig's JSON.
+	var sources struct {
+		Domains []struct {
+			Source string `json:"source"`
+		} `json:"domains"`
+	}
+	if err = json.Unmarshal([]byte(str), &sources); err != nil {
+		return nil, err
+	}
+	for i, d := range sources.Domains {
+		conf.Domains[i].Source = d.Source
+	}
 	return conf, nil
 }
 
@@ -153,7 +187,10 @@ func require(call otto.FunctionCall) otto.Value {
 		cmd := fmt.Sprintf(`JSON.parse(JSON.stringify(%s))`, string(data))
 		value, err = call.Otto.Run(cmd)
 	} else {
-		_, err = call.Otto.Run(string(data))
+		var script *otto.Script
+		if script, err = call.Otto.Compile(relFile, data); err == nil {
+			_, err = call.Otto.Run(script)
+		}
 	}
 
 	if err != nil {
@@ -166,6 +203,29 @@ func require(call otto.FunctionCall) otto.Value {
 	return value
 }
 
+// sourceLocation returns the file and line, such as "dnsconfig.js:12",
+// of the innermost caller outside of helpers.js, or "" if unknown.
+func sourceLocation(call otto.FunctionCall) otto.Value {
+	for _, frame := range call.Otto.ContextSkip(10, true).Stacktrace {
+		// A frame is "path:line:column", optionally as "callee (...)".
+		if i := strings.LastIndex(frame, " ("); i != -1 && strings.HasSuffix(frame, ")") {
+			frame = frame[i+2 : len(frame)-1]
+		}
+		parts := strings.Split(frame, ":")
+		if len(parts) < 3 {
+			continue
+		}
+		path := strings.Join(parts[:len(parts)-2], ":")
+		if path == helpersJsFileName || strings.HasPrefix(path, "<") {
+			continue
+		}
+		v, _ := otto.ToValue(path + ":" + parts[len(parts)-2])
+		return v
+	}
+	v, _ := otto.ToValue("")
+	return v
+}
+
 func listFiles(call otto.FunctionCall) otto.Value {
 	// Check amount of arguments provided
 	if !(len(call.ArgumentList) >= 1 && len(call.ArgumentList) <= 3) {
@@ -254,6 +314,81 @@ func listFiles(call otto.FunctionCall) otto.Value {
 	return value
 }
 
+// data returns the values in a file, one per line. The file is relative
+// to the current file, like require().
+func data(call otto.FunctionCall) otto.Value {
+	if len(call.ArgumentList) != 1 {
+		throw(call.Otto, "DATA takes exactly one argument")
+	}
+	file := call.Argument(0).String()
+	relFile := filepath.ToSlash(filepath.Join(currentDirectory, file))
+	if filepath.IsAbs(file) {
+		relFile = file
+	}
+	printer.Debugf("DATA: %s (%s)\n", file, relFile)
+	b, err := os.ReadFile(relFile)
+	if err != nil {
+		throw(call.Otto, fmt.Sprintf("DATA: data source %s is unavailable: %v", file, err))
+	}
+	return dataValues(call.Otto, "DATA", file, b)
+}
+
+// dataCommand returns the values that a command writes, one per line.
+// It is only enabled by --allow-exec.
+func dataCommand(call otto.FunctionCall) otto.Value {
+	if len(call.ArgumentList) < 1 {
+		throw(call.Otto, "DATA_COMMAND requires at least one argument: the command")
+	}
+	args := make([]string, len(call.ArgumentList))
+	for i, arg := range call.ArgumentList {
+		args[i] = arg.String()
+	}
+	source := strings.Join(args, " ")
+	if !EnableExec {
+		throw(call.Otto, fmt.Sprintf("DATA_COMMAND: %s was not run; commands are only run with --allow-exec", source))
+	}
+	printer.Debugf("DATA_COMMAND: %s\n", source)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = currentDirectory
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	b, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		throw(call.Otto, fmt.Sprintf("DATA_COMMAND: data source %s is unavailable: %v", source, err))
+	}
+	return dataValues(call.Otto, "DATA_COMMAND", source, b)
+}
+
+// dataValues returns the lines of b as an array, leaving out blank lines
+// and comments (lines starting with #). A source without values is an
+// error, so that a source that is broken doesn't remove every record
+// made from it.
+func dataValues(vm *otto.Otto, fn, source string, b []byte) otto.Value {
+	values := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		values = append(values, line)
+	}
+	if err := scanner.Err(); err != nil {
+		throw(vm, fmt.Sprintf("%s: data source %s is unavailable: %v", fn, source, err))
+	}
+	if len(values) == 0 {
+		throw(vm, fmt.Sprintf("%s: data source %s has no values", fn, source))
+	}
+	v, err := vm.ToValue(values)
+	if err != nil {
+		throw(vm, fmt.Sprintf("converting value failed: %v", err.Error()))
+	}
+	return v
+}
+
 func jsPanic(call otto.FunctionCall) otto.Value {
 	if len(call.ArgumentList) != 1 {
 		throw(call.Otto, "PANIC takes exactly one argument")
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"unicode"
 
@@ -91,9 +92,11 @@ func TestParsedFiles(t *testing.T) {
 
 			// For each domain, if there is a zone file, test against it:
 
-			errs := normalize.ValidateAndNormalizeConfig(conf)
-			if len(errs) != 0 {
-				t.Fatal(errs[0])
+			// Warnings don't stop a run, so only errors fail the test.
+			for _, err := range normalize.ValidateAndNormalizeConfig(conf) {
+				if _, ok := err.(normalize.Warning); !ok {
+					t.Fatal(err)
+				}
 			}
 
 			var dCount int
@@ -134,6 +137,8 @@ func TestErrors(t *testing.T) {
 	tests := []struct{ desc, text string }{
 		{"old dsp style", `D("foo.com","reg","dsp")`},
 		{"MX no priority", `D("foo.com","reg",MX("@","test."))`},
+		{"TXT_FRAGMENT twice", `TXT_FRAGMENT("a","x");TXT_FRAGMENT("a","y")`},
+		{"TXT_FRAGMENT with space", `TXT_FRAGMENT("a b","x")`},
 		{"MX reversed", `D("foo.com","reg",MX("@","test.", 5))`},
 		{"CF_REDIRECT With comma", `D("foo.com","reg",CF_REDIRECT("foo.com,","baaa"))`},
 		{"CF_TEMP_REDIRECT With comma", `D("foo.com","reg",CF_TEMP_REDIRECT("foo.com","baa,a"))`},
@@ -141,6 +146,13 @@ func TestErrors(t *testing.T) {
 		{"Bad cidr", `D(reverse("foo.com"), "reg")`},
 		{"Dup domains", `D("example.org", "reg"); D("example.org", "reg")`},
 		{"Bad NAMESERVER", `D("example.com","reg", NAMESERVER("@","ns1.foo.com."))`},
+		{"TTL unknown unit", `D("foo.com","reg",A("@","1.2.3.4",TTL("1x")))`},
+		{"TTL unit first", `D("foo.com","reg",A("@","1.2.3.4",TTL("h1")))`},
+		{"TTL mixed units and seconds", `D("foo.com","reg",A("@","1.2.3.4",TTL("1h30")))`},
+		{"TTL too long", `D("foo.com","reg",A("@","1.2.3.4",TTL("100y")))`},
+		{"DefaultTTL invalid", `D("foo.com","reg",DefaultTTL("soon"))`},
+		{"SRV port out of range", `D("foo.com","reg",SRV("_sip._tcp",10,60,70000,"sip.foo.com."))`},
+		{"SRV negative weight", `D("foo.com","reg",SRV("_sip._tcp",10,-1,5060,"sip.foo.com."))`},
 	}
 	for _, tst := range tests {
 		t.Run(tst.desc, func(t *testing.T) {
@@ -151,3 +163,85 @@ func TestErrors(t *testing.T) {
 
 	}
 }
+
+func TestAssertionErrors(t *testing.T) {
+	tests := []struct{ desc, text, exp string }{
+		{"count", `D("foo.com","reg",ASSERT_COUNT("@","A",1),A("@","1.2.3.4"),A("@","1.2.3.5"))`,
+			`foo.com: ASSERT_COUNT("@", "A", 1) failed: there are 2 A records at @`},
+		{"count with D_EXTEND", `D("foo.com","reg",ASSERT_COUNT("@","A",1),A("@","1.2.3.4"));D_EXTEND("foo.com",A("@","1.2.3.5"))`,
+			`foo.com: ASSERT_COUNT("@", "A", 1) failed: there are 2 A records at @`},
+		{"includes", `D("foo.com","reg",ASSERT_INCLUDES("@","MX","relay.example.net."),MX("@",10,"mx.foo.com."))`,
+			`foo.com: ASSERT_INCLUDES("@", "MX", "relay.example.net.") failed: the MX records at @ are [mx.foo.com.]`},
+		{"function", `D("foo.com","reg",ASSERT("has records",function(d){return d.records.length>0}))`,
+			`foo.com: ASSERT("has records") failed: the check returned false`},
+		{"defaults", `DEFAULTS(ASSERT_COUNT("@","A",1));D("foo.com","reg",A("@","1.2.3.4"));D("bar.com","reg")`,
+			`bar.com: ASSERT_COUNT("@", "A", 1) failed: there are 0 A records at @`},
+		{"arguments", `D("foo.com","reg",ASSERT_COUNT("@","A"))`, "ASSERT_COUNT needs a name, a type and a count"},
+	}
+	dir := t.TempDir()
+	for _, tst := range tests {
+		t.Run(tst.desc, func(t *testing.T) {
+			file := filepath.Join(dir, "dnsconfig.js")
+			if err := os.WriteFile(file, []byte(tst.text), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ExecuteJavascript(file, true, nil)
+			if err == nil {
+				t.Fatal("Expected error but found none")
+			}
+			if !strings.Contains(err.Error(), tst.exp) {
+				t.Errorf("expected an error containing %q, got %q", tst.exp, err)
+			}
+		})
+	}
+}
+
+func TestDataErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "empty.txt"), []byte("# no addresses yet\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct{ desc, text, exp string }{
+		{"missing file", `DATA("missing.txt")`, "DATA: data source missing.txt is unavailable"},
+		{"no values", `DATA("empty.txt")`, "DATA: data source empty.txt has no values"},
+		{"command not allowed", `DATA_COMMAND("echo", "192.0.2.1")`, "DATA_COMMAND: echo 192.0.2.1 was not run; commands are only run with --allow-exec"},
+	}
+	for _, tst := range tests {
+		t.Run(tst.desc, func(t *testing.T) {
+			file := filepath.Join(dir, "dnsconfig.js")
+			if err := os.WriteFile(file, []byte(tst.text), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ExecuteJavascript(file, true, nil)
+			if err == nil {
+				t.Fatal("Expected error but found none")
+			}
+			if !strings.Contains(err.Error(), tst.exp) {
+				t.Errorf("expected an error containing %q, got %q", tst.exp, err)
+			}
+		})
+	}
+}
+
+func TestSources(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "dnsconfig.js")
+	if err := os.WriteFile(file, []byte(`var REG = NewRegistrar("none");
+
+D("example.com", REG,
+  A("www", "192.0.2.1")
+);
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf, err := ExecuteJavascript(file, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc := conf.Domains[0]
+	if exp := file + ":3"; dc.Source != exp {
+		t.Errorf("got domain source %q, expected %q", dc.Source, exp)
+	}
+	if exp := file + ":4"; dc.Records[0].Source != exp {
+		t.Errorf("got record source %q, expected %q", dc.Records[0].Source, exp)
+	}
+}
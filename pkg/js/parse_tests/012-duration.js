@@ -3,5 +3,8 @@ D("foo.com","none",
     A("a","1.2.3.5", TTL("300")),
     A("b","1.2.3.6", TTL("3m")),
     A("c","1.2.3.7", TTL("3h")),
-    A("d","1.2.3.8", TTL("3d"))
+    A("d","1.2.3.8", TTL("3d")),
+    A("e","1.2.3.9", TTL("1h")),
+    A("f","1.2.3.10", TTL("24h")),
+    A("g","1.2.3.11", TTL("1h30m"))
 );
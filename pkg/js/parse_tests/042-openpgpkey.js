@@ -0,0 +1,4 @@
+D("foo.com","none",
+    OPENPGPKEY("c93f1e400f26708f98cb19d936620da35eec8f72e57f9eec01c1afd6._openpgpkey","AQIDBAUGBwgJCgsMDQ4PEBESExQV FhcYGRobHB0eHyAhIiMkJSYn"),
+    OPENPGPKEY("generic._openpgpkey","\\# 39 0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f2021222324252627")
+);
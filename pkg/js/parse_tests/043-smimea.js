@@ -0,0 +1,3 @@
+D("foo.com","none",
+    SMIMEA("c93f1e400f26708f98cb19d936620da35eec8f72e57f9eec01c1afd6._smimecert",3,1,1,"0C72AC70B745AC19998811B131D662C9 AC69DBDBE7CB23E5B514B56664C5D3D6")
+);
@@ -0,0 +1,10 @@
+TXT_FRAGMENT("spf_includes", ["include:_spf.google.com", "include:mailgun.org"]);
+
+D("foo.com","none",
+    TXT("@","v=spf1 {{spf_includes}} -all"),
+    TXT("sub","v=spf1 a {{ spf_includes }} ~all")
+);
+
+D("bar.com","none",
+    TXT("@","v=spf1 {{spf_includes}} -all")
+);
@@ -0,0 +1,10 @@
+D("foo.com","none",
+    A("@","1.2.3.4"),
+    A("api","10.0.0.1",ENV("staging")),
+    A("api","192.0.2.1",ENV("prod")),
+    CNAME("debug","debug.internal.",ENV("dev","staging"))
+);
+
+D("staging.foo.com","none",ENV("staging"),
+    A("@","10.0.0.2")
+);
@@ -0,0 +1,6 @@
+D("foo.com","none",
+    CDS("@",2371,13,2,"1F987CC6583E92DF0890718C42C5F6D4ED4C4E2C6D9E2F2F4E7C1B5BC1A2E0F3"),
+    CDNSKEY("@",257,3,13,"mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ=="),
+    CDS("sub",0,0,0,"00"),
+    CDNSKEY("sub",0,3,0,"AA==")
+);
@@ -0,0 +1,4 @@
+D("foo.com","none",
+    DNAME("old","new.foo.com."),
+    DNAME("legacy","example.net.")
+);
@@ -0,0 +1,5 @@
+D("foo.com","none",
+    HTTPS("@",1,".",'port=8443 alpn="h2,h3"'),
+    HTTPS("www",0,"cdn.example.net.",""),
+    SVCB("_dns",1,"dns.foo.com.",'alpn="dot" port=853')
+);
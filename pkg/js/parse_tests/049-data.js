@@ -0,0 +1,4 @@
+D("foo.com","none",
+    DATA("049-data/lb-ips.txt").map(function(ip) { return A("lb", ip); }),
+    A("@", DATA("049-data/lb-ips.txt")[0])
+);
@@ -0,0 +1,15 @@
+D("foo.com","none",
+    ASSERT_COUNT("@", "A", 1),
+    ASSERT_INCLUDES("@", "MX", "relay.example.net"),
+    ASSERT_COUNT("www.sub", "A", 1),
+    ASSERT("there is a CNAME for www", function(d) {
+        return d.records.some(function(r) { return r.type === "CNAME" && r.name === "www"; });
+    }),
+    A("@", "1.2.3.4"),
+    MX("@", 10, "relay.example.net."),
+    MX("@", 20, "mx.foo.com."),
+    CNAME("www", "foo.com.")
+);
+D_EXTEND("sub.foo.com",
+    A("www", "1.2.3.5")
+);
@@ -0,0 +1,223 @@
+// Package k8simport reads DNS records from a snapshot of Kubernetes
+// services and ingresses, such as the output of
+// "kubectl get services,ingresses --all-namespaces -o json".
+package k8simport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// The annotations read from services and ingresses, which are the same
+// as those of external-dns, so that existing manifests work unchanged.
+const (
+	// AnnotationHostname is a comma-separated list of the hostnames of
+	// a service or ingress.
+	AnnotationHostname = "external-dns.alpha.kubernetes.io/hostname"
+	// AnnotationTTL is the TTL, in seconds, of the records of a service
+	// or ingress.
+	AnnotationTTL = "external-dns.alpha.kubernetes.io/ttl"
+	// AnnotationTarget is a comma-separated list of IP addresses or a
+	// hostname to use instead of those of the load balancer.
+	AnnotationTarget = "external-dns.alpha.kubernetes.io/target"
+)
+
+// MetaSource is set on each record to the service or ingress it is for,
+// such as "Service default/web".
+const MetaSource = "k8s_source"
+
+// list is the subset of a Kubernetes List of services and ingresses
+// that is read.
+type list struct {
+	Items []object `json:"items"`
+}
+
+type object struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		Rules []struct {
+			Host string `json:"host"`
+		} `json:"rules"` // of an ingress
+	} `json:"spec"`
+	Status struct {
+		LoadBalancer struct {
+			Ingress []struct {
+				IP       string `json:"ip"`
+				Hostname string `json:"hostname"`
+			} `json:"ingress"`
+		} `json:"loadBalancer"`
+	} `json:"status"`
+}
+
+// source returns the object's kind and name, such as
+// "Service default/web", for messages.
+func (o *object) source() string {
+	return fmt.Sprintf("%s %s/%s", o.Kind, o.Metadata.Namespace, o.Metadata.Name)
+}
+
+// hostnames returns the hostnames of the object, from AnnotationHostname
+// and, for an ingress, its rules, in lower case and without duplicates.
+func (o *object) hostnames() []string {
+	var names []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, name := range strings.Split(o.Metadata.Annotations[AnnotationHostname], ",") {
+		add(name)
+	}
+	if o.Kind == "Ingress" {
+		for _, rule := range o.Spec.Rules {
+			add(rule.Host)
+		}
+	}
+	return names
+}
+
+// targets returns the IP addresses and the hostnames that the object's
+// hostnames point to.
+func (o *object) targets() (ips, hosts []string) {
+	if v := o.Metadata.Annotations[AnnotationTarget]; v != "" {
+		for _, target := range strings.Split(v, ",") {
+			target = strings.TrimSpace(target)
+			if net.ParseIP(target) != nil {
+				ips = append(ips, target)
+			} else if target != "" {
+				hosts = append(hosts, target)
+			}
+		}
+		return ips, hosts
+	}
+	for _, lb := range o.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			ips = append(ips, lb.IP)
+		}
+		if lb.Hostname != "" {
+			hosts = append(hosts, lb.Hostname)
+		}
+	}
+	return ips, hosts
+}
+
+// ParseRecords reads the records of the zone origin from r, a snapshot
+// of services and ingresses. Each hostname of an object in the zone gets
+// an A or AAAA record for each IP address of its load balancer, or a
+// CNAME record if the load balancer has a hostname instead. Hostnames
+// in other zones, and objects with no hostnames or no load balancer
+// yet, are skipped. Records that more than one object would create are
+// only created once. If any objects are invalid, it returns an error
+// listing each of them.
+func ParseRecords(r io.Reader, origin string) (models.Records, error) {
+	var snapshot list
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	origin = strings.ToLower(strings.TrimSuffix(origin, "."))
+
+	var recs models.Records
+	var errs []string
+	seen := map[string]bool{}     // the records added, by name, type and target
+	cnames := map[string]string{} // the source of the CNAME at each name
+	addrs := map[string]string{}  // the source of the first A or AAAA record at each name
+	for _, o := range snapshot.Items {
+		if o.Kind != "Service" && o.Kind != "Ingress" {
+			continue
+		}
+		ttl := models.DefaultTTL
+		if v, ok := o.Metadata.Annotations[AnnotationTTL]; ok {
+			n, err := strconv.ParseUint(v, 10, 32)
+			if err != nil || n == 0 {
+				errs = append(errs, fmt.Sprintf("%s: invalid %s %q", o.source(), AnnotationTTL, v))
+				continue
+			}
+			ttl = uint32(n)
+		}
+		ips, hosts := o.targets()
+		if len(ips) != 0 && len(hosts) != 0 {
+			errs = append(errs, fmt.Sprintf("%s: has both IP addresses and hostnames as targets", o.source()))
+			continue
+		}
+		if len(hosts) > 1 {
+			errs = append(errs, fmt.Sprintf("%s: has more than one hostname as a target (%s), but a CNAME can only have one", o.source(), strings.Join(hosts, ", ")))
+			continue
+		}
+
+		for _, name := range o.hostnames() {
+			if name != origin && !strings.HasSuffix(name, "."+origin) {
+				continue
+			}
+			add := func(typ, target string) error {
+				key := name + " " + typ + " " + target
+				if seen[key] {
+					return nil
+				}
+				rc := &models.RecordConfig{TTL: ttl, Metadata: map[string]string{MetaSource: o.source()}}
+				rc.SetLabelFromFQDN(name, origin)
+				if err := rc.PopulateFromString(typ, target, origin); err != nil {
+					return fmt.Errorf("%s %s: %w", typ, target, err)
+				}
+				seen[key] = true
+				recs = append(recs, rc)
+				return nil
+			}
+			var err error
+			if len(hosts) == 1 {
+				if src, ok := addrs[name]; ok {
+					err = fmt.Errorf("%s can't be a CNAME, as %s gives it addresses", name, src)
+				} else if src, ok := cnames[name]; ok && !seen[name+" CNAME "+dotted(hosts[0])] {
+					err = fmt.Errorf("%s already has a CNAME from %s", name, src)
+				} else {
+					cnames[name] = o.source()
+					err = add("CNAME", dotted(hosts[0]))
+				}
+			}
+			for _, ip := range ips {
+				if src, ok := cnames[name]; ok {
+					err = fmt.Errorf("%s can't have addresses, as %s gives it a CNAME", name, src)
+					break
+				}
+				if _, ok := addrs[name]; !ok {
+					addrs[name] = o.source()
+				}
+				typ := "A"
+				if net.ParseIP(ip).To4() == nil {
+					typ = "AAAA"
+				}
+				if err = add(typ, ip); err != nil {
+					break
+				}
+			}
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", o.source(), err))
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+	sort.SliceStable(recs, func(i, j int) bool { return recs[i].GetLabel() < recs[j].GetLabel() })
+	return recs, nil
+}
+
+// dotted returns name as a FQDN ending with a dot.
+func dotted(name string) string {
+	return strings.TrimSuffix(name, ".") + "."
+}
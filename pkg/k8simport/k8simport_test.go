@@ -0,0 +1,131 @@
+package k8simport
+
+import (
+	"strings"
+	"testing"
+)
+
+const snapshot = `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {
+      "kind": "Service",
+      "metadata": {
+        "name": "web",
+        "namespace": "default",
+        "annotations": {
+          "external-dns.alpha.kubernetes.io/hostname": "www.example.com, Example.com.,www.example.net",
+          "external-dns.alpha.kubernetes.io/ttl": "60"
+        }
+      },
+      "status": {"loadBalancer": {"ingress": [{"ip": "192.0.2.1"}, {"ip": "2001:db8::1"}]}}
+    },
+    {
+      "kind": "Service",
+      "metadata": {
+        "name": "web-canary",
+        "namespace": "default",
+        "annotations": {"external-dns.alpha.kubernetes.io/hostname": "www.example.com"}
+      },
+      "status": {"loadBalancer": {"ingress": [{"ip": "192.0.2.2"}, {"ip": "192.0.2.1"}]}}
+    },
+    {
+      "kind": "Ingress",
+      "metadata": {"name": "shop", "namespace": "store"},
+      "spec": {"rules": [{"host": "shop.example.com"}, {"host": "cart.example.com"}, {}]},
+      "status": {"loadBalancer": {"ingress": [{"hostname": "lb-123.elb.example.net"}]}}
+    },
+    {
+      "kind": "Service",
+      "metadata": {
+        "name": "api",
+        "namespace": "store",
+        "annotations": {
+          "external-dns.alpha.kubernetes.io/hostname": "api.example.com",
+          "external-dns.alpha.kubernetes.io/target": "198.51.100.7"
+        }
+      },
+      "status": {"loadBalancer": {"ingress": [{"ip": "10.0.0.7"}]}}
+    },
+    {
+      "kind": "Service",
+      "metadata": {"name": "internal", "namespace": "default"},
+      "status": {"loadBalancer": {"ingress": [{"ip": "10.0.0.8"}]}}
+    },
+    {
+      "kind": "Service",
+      "metadata": {
+        "name": "pending",
+        "namespace": "default",
+        "annotations": {"external-dns.alpha.kubernetes.io/hostname": "pending.example.com"}
+      }
+    },
+    {"kind": "ConfigMap", "metadata": {"name": "settings", "namespace": "default"}}
+  ]
+}`
+
+func TestParseRecords(t *testing.T) {
+	recs, err := ParseRecords(strings.NewReader(snapshot), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, rc := range recs {
+		got = append(got, rc.GetLabel()+" "+rc.Type+" "+rc.ToDiffable()+" "+rc.Metadata[MetaSource])
+	}
+	exp := []string{
+		"@ A 192.0.2.1 ttl=60 Service default/web",
+		"@ AAAA 2001:db8::1 ttl=60 Service default/web",
+		"api A 198.51.100.7 ttl=300 Service store/api",
+		"cart CNAME lb-123.elb.example.net. ttl=300 Ingress store/shop",
+		"shop CNAME lb-123.elb.example.net. ttl=300 Ingress store/shop",
+		"www A 192.0.2.1 ttl=60 Service default/web",
+		"www AAAA 2001:db8::1 ttl=60 Service default/web",
+		"www A 192.0.2.2 ttl=300 Service default/web-canary",
+	}
+	if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+}
+
+func TestParseRecordsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		items string
+		errs  []string
+	}{
+		{
+			`{"kind": "Service", "metadata": {"name": "a", "namespace": "ns", "annotations": {"external-dns.alpha.kubernetes.io/hostname": "a.example.com", "external-dns.alpha.kubernetes.io/ttl": "soon"}}}`,
+			[]string{`Service ns/a: invalid external-dns.alpha.kubernetes.io/ttl "soon"`},
+		},
+		{
+			`{"kind": "Service", "metadata": {"name": "a", "namespace": "ns", "annotations": {"external-dns.alpha.kubernetes.io/hostname": "a.example.com"}},
+			  "status": {"loadBalancer": {"ingress": [{"hostname": "lb1.example.net"}, {"hostname": "lb2.example.net"}]}}}`,
+			[]string{"Service ns/a: has more than one hostname as a target"},
+		},
+		{
+			`{"kind": "Service", "metadata": {"name": "a", "namespace": "ns", "annotations": {"external-dns.alpha.kubernetes.io/hostname": "a.example.com"}},
+			  "status": {"loadBalancer": {"ingress": [{"ip": "192.0.2.1"}]}}},
+			 {"kind": "Ingress", "metadata": {"name": "b", "namespace": "ns"}, "spec": {"rules": [{"host": "a.example.com"}]},
+			  "status": {"loadBalancer": {"ingress": [{"hostname": "lb.example.net"}]}}}`,
+			[]string{"Ingress ns/b: a.example.com can't be a CNAME, as Service ns/a gives it addresses"},
+		},
+	} {
+		_, err := ParseRecords(strings.NewReader(`{"items": [`+tc.items+`]}`), "example.com")
+		if err == nil {
+			t.Errorf("%s: expected errors %q", tc.items, tc.errs)
+			continue
+		}
+		lines := strings.Split(err.Error(), "\n")
+		if len(lines) != len(tc.errs) {
+			t.Errorf("%s: got errors %q, expected %q", tc.items, lines, tc.errs)
+			continue
+		}
+		for i, exp := range tc.errs {
+			if !strings.HasPrefix(lines[i], exp) {
+				t.Errorf("%s: got error %q, expected %q", tc.items, lines[i], exp)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package normalize
+
+import (
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/gobwas/glob"
+)
+
+// envMetaKey is the metadata set by ENV() on domains and records. Its
+// value is a comma-separated list of environment names.
+const envMetaKey = "env"
+
+// SelectEnvironment removes the domains and records that ENV() marked
+// as belonging only to other environments, so that they are neither
+// created nor reported as changes. The existing records with the same
+// name and type as a removed record are ignored like IGNORE_NAME,
+// unless a record for env has the same name and type.
+//
+// It must be called before ValidateAndNormalizeConfig, so that records
+// of different environments do not conflict with each other. An empty
+// env keeps everything.
+func SelectEnvironment(config *models.DNSConfig, env string) {
+	if env == "" {
+		return
+	}
+
+	var domains []*models.DomainConfig
+	for _, domain := range config.Domains {
+		if !inEnvironment(domain.Metadata, env) {
+			continue
+		}
+		domains = append(domains, domain)
+
+		var records, excluded models.Records
+		for _, rec := range domain.Records {
+			if inEnvironment(rec.Metadata, env) {
+				records = append(records, rec)
+			} else {
+				excluded = append(excluded, rec)
+			}
+		}
+		domain.Records = records
+
+		// Labels aren't normalized yet, so the keys are built here
+		// rather than with rec.Key().
+		seen := map[string]bool{}
+		key := func(rec *models.RecordConfig) string {
+			return strings.ToLower(rec.GetLabel()) + " " + rec.Type
+		}
+		for _, rec := range records {
+			seen[key(rec)] = true
+		}
+		for _, rec := range excluded {
+			if k := key(rec); !seen[k] {
+				seen[k] = true
+				domain.IgnoredNames = append(domain.IgnoredNames, &models.IgnoreName{
+					Pattern: glob.QuoteMeta(strings.ToLower(rec.GetLabel())),
+					Types:   rec.Type,
+				})
+			}
+		}
+	}
+	config.Domains = domains
+}
+
+// inEnvironment reports whether metadata without an env key, or with
+// env among its environments, is part of env.
+func inEnvironment(metadata map[string]string, env string) bool {
+	envs, ok := metadata[envMetaKey]
+	if !ok {
+		return true
+	}
+	for _, e := range strings.Split(envs, ",") {
+		if strings.TrimSpace(e) == env {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package normalize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
+)
+
+func TestSelectEnvironment(t *testing.T) {
+	makeConfig := func() *models.DNSConfig {
+		rec := func(typ, label, target, env string) *models.RecordConfig {
+			rc := &models.RecordConfig{Type: typ, Name: label}
+			rc.SetTarget(target)
+			if env != "" {
+				rc.Metadata = map[string]string{"env": env}
+			}
+			return rc
+		}
+		return &models.DNSConfig{
+			Domains: []*models.DomainConfig{
+				{Name: "example.com", Records: models.Records{
+					rec("A", "@", "1.2.3.4", ""),
+					rec("A", "api", "10.0.0.1", "staging"),
+					rec("A", "api", "192.0.2.1", "prod"),
+					rec("CNAME", "debug", "debug.internal.", "dev, staging"),
+				}},
+				{Name: "staging.example.com", Metadata: map[string]string{"env": "staging"}, Records: models.Records{
+					rec("A", "@", "10.0.0.2", ""),
+				}},
+			},
+		}
+	}
+	summarize := func(config *models.DNSConfig) string {
+		var lines []string
+		for _, d := range config.Domains {
+			for _, r := range d.Records {
+				lines = append(lines, d.Name+" "+r.GetLabel()+" "+r.Type+" "+r.GetTargetField())
+			}
+			for _, n := range d.IgnoredNames {
+				lines = append(lines, d.Name+" ignore "+n.Pattern+" "+n.Types)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	for _, tc := range []struct {
+		env string
+		exp []string
+	}{
+		{"", []string{
+			"example.com @ A 1.2.3.4",
+			"example.com api A 10.0.0.1",
+			"example.com api A 192.0.2.1",
+			"example.com debug CNAME debug.internal.",
+			"staging.example.com @ A 10.0.0.2",
+		}},
+		{"prod", []string{
+			"example.com @ A 1.2.3.4",
+			"example.com api A 192.0.2.1",
+			"example.com ignore debug CNAME",
+		}},
+		{"staging", []string{
+			"example.com @ A 1.2.3.4",
+			"example.com api A 10.0.0.1",
+			"example.com debug CNAME debug.internal.",
+			"staging.example.com @ A 10.0.0.2",
+		}},
+	} {
+		config := makeConfig()
+		SelectEnvironment(config, tc.env)
+		if got, exp := summarize(config), strings.Join(tc.exp, "\n"); got != exp {
+			t.Errorf("env %q: got:\n%s\nexpected:\n%s", tc.env, got, exp)
+		}
+	}
+}
+
+func TestSelectEnvironmentDiff(t *testing.T) {
+	// In prod, the staging-only CNAME that exists at the provider must
+	// be left alone, but the staging A record is replaced as usual.
+	config := &models.DNSConfig{
+		Domains: []*models.DomainConfig{{Name: "example.com", Records: models.Records{}}},
+	}
+	for _, r := range []struct{ typ, label, target, env string }{
+		{"A", "api", "10.0.0.1", "staging"},
+		{"A", "api", "192.0.2.1", "prod"},
+		{"CNAME", "debug", "debug.internal.", "staging"},
+	} {
+		rc := &models.RecordConfig{Type: r.typ, Name: r.label, Metadata: map[string]string{"env": r.env}}
+		rc.SetTarget(r.target)
+		config.Domains[0].Records = append(config.Domains[0].Records, rc)
+	}
+	SelectEnvironment(config, "prod")
+	dc := config.Domains[0]
+	for _, rec := range dc.Records {
+		rec.SetLabel(rec.Name, dc.Name)
+	}
+
+	var existing models.Records
+	for _, r := range []struct{ typ, label, target string }{
+		{"A", "api", "10.0.0.1"},
+		{"CNAME", "debug", "debug.internal."},
+	} {
+		rc := &models.RecordConfig{Type: r.typ}
+		rc.SetLabel(r.label, dc.Name)
+		rc.SetTarget(r.target)
+		existing = append(existing, rc)
+	}
+
+	_, create, del, mod, err := diff.New(dc).IncrementalDiff(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(create) != 0 || len(del) != 0 || len(mod) != 1 {
+		t.Fatalf("expected a single modification, got create=%v delete=%v modify=%v", create, del, mod)
+	}
+	if got := mod[0].Desired.GetTargetField(); got != "192.0.2.1" {
+		t.Errorf("expected api to be changed to 192.0.2.1, got %s", got)
+	}
+}
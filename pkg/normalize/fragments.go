@@ -0,0 +1,45 @@
+package normalize
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// fragmentRef matches a {{name}} reference to a TXT_FRAGMENT.
+var fragmentRef = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+// expandTXTFragments replaces each {{name}} in TXT records with the
+// value of the TXT_FRAGMENT called name, so that records sharing a
+// fragment are all updated when it changes.
+func expandTXTFragments(config *models.DNSConfig) (errs []error) {
+	for _, domain := range config.Domains {
+		for _, rec := range domain.Records {
+			if !rec.HasFormatIdenticalToTXT() {
+				continue
+			}
+
+			txts := make([]string, len(rec.TxtStrings))
+			changed := false
+			for i, txt := range rec.TxtStrings {
+				txts[i] = fragmentRef.ReplaceAllStringFunc(txt, func(ref string) string {
+					name := fragmentRef.FindStringSubmatch(ref)[1]
+					value, ok := config.TXTFragments[name]
+					if !ok {
+						errs = append(errs, fmt.Errorf("%s %s: unknown TXT_FRAGMENT %q", rec.Type, rec.GetLabelFQDN(), name))
+						return ref
+					}
+					changed = true
+					return value
+				})
+			}
+			if changed {
+				if err := rec.SetTargetTXTs(txts); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,55 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestExpandTXTFragments(t *testing.T) {
+	makeConfig := func(includes string) *models.DNSConfig {
+		txt := func(label, value string) *models.RecordConfig {
+			rc := &models.RecordConfig{Type: "TXT"}
+			rc.SetLabel(label, "example.com")
+			rc.SetTargetTXT(value)
+			return rc
+		}
+		return &models.DNSConfig{
+			TXTFragments: map[string]string{"spf": includes},
+			Domains: []*models.DomainConfig{
+				{Name: "example.com", Records: models.Records{
+					txt("@", "v=spf1 {{spf}} -all"),
+					txt("mail", "v=spf1 a {{spf}} ~all"),
+					txt("other", "no fragments here"),
+				}},
+			},
+		}
+	}
+
+	for _, includes := range []string{"include:a.example", "include:a.example include:b.example"} {
+		config := makeConfig(includes)
+		if errs := expandTXTFragments(config); len(errs) != 0 {
+			t.Fatal(errs)
+		}
+		recs := config.Domains[0].Records
+		for i, exp := range []string{
+			"v=spf1 " + includes + " -all",
+			"v=spf1 a " + includes + " ~all",
+			"no fragments here",
+		} {
+			if got := recs[i].GetTargetTXTJoined(); got != exp {
+				t.Errorf("%s: got %q, expected %q", recs[i].GetLabel(), got, exp)
+			}
+		}
+	}
+}
+
+func TestExpandTXTFragmentsUnknown(t *testing.T) {
+	rc := &models.RecordConfig{Type: "TXT"}
+	rc.SetLabel("@", "example.com")
+	rc.SetTargetTXT("v=spf1 {{missing}} -all")
+	config := &models.DNSConfig{Domains: []*models.DomainConfig{{Name: "example.com", Records: models.Records{rc}}}}
+	if errs := expandTXTFragments(config); len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+}
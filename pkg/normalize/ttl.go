@@ -0,0 +1,14 @@
+package normalize
+
+import "github.com/StackExchange/dnscontrol/v3/models"
+
+// OverrideTTLs sets the TTL of every record of every domain to ttl, as
+// for preview/push --ttl-override. It is for a single run, such as to
+// lower the TTLs before a migration, without editing dnsconfig.js.
+func OverrideTTLs(config *models.DNSConfig, ttl uint32) {
+	for _, d := range config.Domains {
+		for _, rec := range d.Records {
+			rec.TTL = ttl
+		}
+	}
+}
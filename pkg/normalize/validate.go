@@ -1,11 +1,15 @@
 package normalize
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/plugin"
 	"github.com/StackExchange/dnscontrol/v3/pkg/transform"
 	"github.com/StackExchange/dnscontrol/v3/providers"
 	"github.com/miekg/dns"
@@ -56,16 +60,23 @@ func validateRecordTypes(rec *models.RecordConfig, domain string, pTypes []strin
 		"AAAA":             true,
 		"ALIAS":            false,
 		"CAA":              true,
+		"CDNSKEY":          true,
+		"CDS":              true,
 		"CNAME":            true,
+		"DNAME":            true,
 		"DS":               true,
+		"HTTPS":            true,
 		"IMPORT_TRANSFORM": false,
 		"MX":               true,
 		"NAPTR":            true,
 		"NS":               true,
+		"OPENPGPKEY":       true,
 		"PTR":              true,
+		"SMIMEA":           true,
 		"SOA":              true,
 		"SRV":              true,
 		"SSHFP":            true,
+		"SVCB":             true,
 		"TLSA":             true,
 		"TXT":              true,
 	}
@@ -185,6 +196,11 @@ func checkTargets(rec *models.RecordConfig, domain string) (errs []error) {
 		if label == "@" {
 			check(fmt.Errorf("cannot create CNAME record for bare domain"))
 		}
+	case "DNAME":
+		check(checkTarget(target))
+		if label == "@" {
+			check(fmt.Errorf("cannot create DNAME record for bare domain (it would redirect every name in the zone)"))
+		}
 	case "MX":
 		check(checkTarget(target))
 	case "NAPTR":
@@ -204,9 +220,9 @@ func checkTargets(rec *models.RecordConfig, domain string) (errs []error) {
 		if label != "@" {
 			check(fmt.Errorf("SOA record is only valid for bare domain"))
 		}
-	case "SRV":
+	case "SRV", "SVCB", "HTTPS":
 		check(checkTarget(target))
-	case "TXT", "IMPORT_TRANSFORM", "CAA", "SSHFP", "TLSA", "DS":
+	case "TXT", "IMPORT_TRANSFORM", "CAA", "SSHFP", "TLSA", "DS", "CDS", "CDNSKEY", "OPENPGPKEY", "SMIMEA":
 	default:
 		if rec.Metadata["orig_custom_type"] != "" {
 			// it is a valid custom type. We perform no validation on target
@@ -263,7 +279,7 @@ func importTransform(srcDomain, dstDomain *models.DomainConfig, transforms []tra
 			r := newRec()
 			r.SetTarget(transformCNAME(r.GetTargetField(), srcDomain.Name, dstDomain.Name))
 			dstDomain.Records = append(dstDomain.Records, r)
-		case "AKAMAICDN", "MX", "NAPTR", "NS", "SOA", "SRV", "TXT", "CAA", "TLSA":
+		case "AKAMAICDN", "MX", "NAPTR", "NS", "SOA", "SRV", "TXT", "CAA", "TLSA", "OPENPGPKEY", "SMIMEA", "CDS", "CDNSKEY", "DNAME", "SVCB", "HTTPS":
 			// Not imported.
 			continue
 		default:
@@ -297,6 +313,10 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 		return []error{err}
 	}
 
+	if errs := expandTXTFragments(config); len(errs) != 0 {
+		return errs
+	}
+
 	for _, domain := range config.Domains {
 		pTypes := []string{}
 		for _, provider := range domain.DNSProviderInstances {
@@ -369,7 +389,7 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 			}
 
 			// Canonicalize Targets.
-			if rec.Type == "CNAME" || rec.Type == "MX" || rec.Type == "NS" || rec.Type == "SRV" {
+			if rec.Type == "CNAME" || rec.Type == "DNAME" || rec.Type == "MX" || rec.Type == "NS" || rec.Type == "SRV" || rec.Type == "SVCB" || rec.Type == "HTTPS" {
 				// #rtype_variations
 				// These record types have a target that is a hostname.
 				// We normalize them to a FQDN so there is less variation to handle.  If a
@@ -392,6 +412,42 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 				if rec.CaaTag != "issue" && rec.CaaTag != "issuewild" && rec.CaaTag != "iodef" {
 					errs = append(errs, fmt.Errorf("CAA tag %s is invalid", rec.CaaTag))
 				}
+			} else if rec.Type == "OPENPGPKEY" {
+				// Convert the key to canonical base64.
+				if err := rec.SetTargetOPENPGPKEY(rec.GetTargetField()); err != nil {
+					errs = append(errs, fmt.Errorf("%w in record %s (domain %s)", err, rec.GetLabel(), domain.Name))
+				}
+			} else if rec.Type == "CDNSKEY" {
+				if rec.DnskeyProtocol != 3 {
+					errs = append(errs, fmt.Errorf("CDNSKEY Protocol %d is invalid (must be 3) in record %s (domain %s)",
+						rec.DnskeyProtocol, rec.GetLabel(), domain.Name))
+				}
+				// Normalize the public key.
+				if err := rec.SetTargetCDNSKEY(rec.DnskeyFlags, rec.DnskeyProtocol, rec.DnskeyAlgorithm, rec.GetTargetField()); err != nil {
+					errs = append(errs, fmt.Errorf("%w in record %s (domain %s)", err, rec.GetLabel(), domain.Name))
+				}
+			} else if rec.Type == "CDS" {
+				if _, err := hex.DecodeString(rec.DsDigest); err != nil {
+					errs = append(errs, fmt.Errorf("CDS Digest %q is not hex in record %s (domain %s)",
+						rec.DsDigest, rec.GetLabel(), domain.Name))
+				}
+			} else if rec.Type == "SMIMEA" {
+				if rec.SmimeaUsage > 3 {
+					errs = append(errs, fmt.Errorf("SMIMEA Usage %d is invalid in record %s (domain %s)",
+						rec.SmimeaUsage, rec.GetLabel(), domain.Name))
+				}
+				if rec.SmimeaSelector > 1 {
+					errs = append(errs, fmt.Errorf("SMIMEA Selector %d is invalid in record %s (domain %s)",
+						rec.SmimeaSelector, rec.GetLabel(), domain.Name))
+				}
+				if rec.SmimeaMatchingType > 2 {
+					errs = append(errs, fmt.Errorf("SMIMEA MatchingType %d is invalid in record %s (domain %s)",
+						rec.SmimeaMatchingType, rec.GetLabel(), domain.Name))
+				}
+				// Normalize the certificate data.
+				if err := rec.SetTargetSMIMEA(rec.SmimeaUsage, rec.SmimeaSelector, rec.SmimeaMatchingType, rec.GetTargetField()); err != nil {
+					errs = append(errs, fmt.Errorf("%w in record %s (domain %s)", err, rec.GetLabel(), domain.Name))
+				}
 			} else if rec.Type == "TLSA" {
 				if rec.TlsaUsage > 3 {
 					errs = append(errs, fmt.Errorf("TLSA Usage %d is invalid in record %s (domain %s)",
@@ -406,6 +462,19 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 						rec.TlsaMatchingType, rec.GetLabel(), domain.Name))
 				}
 			}
+			if rec.Type == "SVCB" || rec.Type == "HTTPS" {
+				// Check the SvcParams, and sort them so that their order
+				// doesn't matter.
+				params, err := rec.GetSvcParams()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%w in record %s (domain %s)", err, rec.GetLabel(), domain.Name))
+				} else if rec.SvcPriority == 0 && len(params) != 0 {
+					errs = append(errs, fmt.Errorf("%s priority 0 (AliasMode) can't have SvcParams in record %s (domain %s)",
+						rec.Type, rec.GetLabel(), domain.Name))
+				} else {
+					rec.SetTargetSVCB(rec.SvcPriority, rec.GetTargetField(), params)
+				}
+			}
 
 			// Populate FQDN:
 			rec.SetLabel(rec.GetLabel(), domain.Name)
@@ -430,6 +499,7 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 				if c == nil {
 					err = fmt.Errorf("IMPORT_TRANSFORM mentions non-existant domain %q", rec.GetTargetField())
 					errs = append(errs, err)
+					continue
 				}
 				err = importTransform(c, domain, table, rec.TTL)
 				if err != nil {
@@ -450,17 +520,27 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 	}
 
 	for _, d := range config.Domains {
-		// Check that CNAMES don't have to co-exist with any other records
-		errs = append(errs, checkCNAMEs(d)...)
-		// Check that if any advanced record types are used in a domain, every provider for that domain supports them
-		err := checkProviderCapabilities(d)
-		if err != nil {
+		// Run the domain's plugins, so that their records are checked too
+		if err := plugin.Apply(d); err != nil {
 			errs = append(errs, err)
 		}
+		// Check that CNAMES don't have to co-exist with any other records
+		errs = append(errs, CheckCNAMEs(d)...)
+		// Check that if any advanced record types are used in a domain, every provider for that domain supports them
+		errs = append(errs, checkProviderCapabilities(d)...)
 		// Check for duplicates
 		errs = append(errs, checkDuplicates(d.Records)...)
 		// Check for different TTLs under the same label
 		errs = append(errs, checkLabelHasMultipleTTLs(d.Records)...)
+		// Check for CAA records that replace a parent's CAA policy
+		errs = append(errs, checkCAAOverride(d.Records)...)
+		// Check for MX and SRV records that point to names of the domain without an address
+		errs = append(errs, checkDanglingTargets(d.Name, d.Records)...)
+		// Optionally, check for records that hide a wildcard
+		if d.Metadata["warn_wildcards"] == "true" {
+			errs = append(errs, checkWildcardPrecedence(d.Records)...)
+			errs = append(errs, checkWildcardShadowing(d.Records)...)
+		}
 		// Validate FQDN consistency
 		for _, r := range d.Records {
 			if r.NameFQDN == "" || !strings.HasSuffix(r.NameFQDN, d.Name) {
@@ -487,7 +567,17 @@ func ValidateAndNormalizeConfig(config *models.DNSConfig) (errs []error) {
 			}
 			if es := providers.AuditRecords(provider.ProviderBase.ProviderType, domain.Records); len(es) != 0 {
 				for _, e := range es {
-					errs = append(errs, fmt.Errorf("%s rejects domain %s: %w", provider.ProviderBase.ProviderType, domain.Name, e))
+					ve := &models.ValidationError{Code: models.ErrCodeRejectedRecord}
+					var inner *models.ValidationError
+					if errors.As(e, &inner) {
+						ve.Code, ve.Record, ve.Source = inner.Code, inner.Record, inner.Source
+					}
+					ve.Domain = domain.Name
+					ve.Message = fmt.Sprintf("%s rejects domain %s: %s", provider.ProviderBase.ProviderType, domain.Name, e)
+					if ve.Source != "" {
+						ve.Message += fmt.Sprintf(" (%s)", ve.Source)
+					}
+					errs = append(errs, ve)
 				}
 			}
 		}
@@ -545,7 +635,18 @@ func checkAutoDNSSEC(dc *models.DomainConfig) (errs []error) {
 	return
 }
 
-func checkCNAMEs(dc *models.DomainConfig) (errs []error) {
+// cnameCompatible lists the types that may be at the same name as a
+// CNAME. DNSSEC signs the CNAME with an RRSIG, and proves that there
+// are no other types there with an NSEC (RFC 4035 section 2.5).
+var cnameCompatible = map[string]bool{
+	"NSEC":  true,
+	"RRSIG": true,
+}
+
+// CheckCNAMEs checks that a name with a CNAME has no other records,
+// apart from the DNSSEC types in cnameCompatible. Providers that add
+// records to a domain after it is normalized can use it to check them.
+func CheckCNAMEs(dc *models.DomainConfig) (errs []error) {
 	cnames := map[string]bool{}
 	for _, r := range dc.Records {
 		if r.Type == "CNAME" {
@@ -556,7 +657,7 @@ func checkCNAMEs(dc *models.DomainConfig) (errs []error) {
 		}
 	}
 	for _, r := range dc.Records {
-		if cnames[r.GetLabel()] && r.Type != "CNAME" {
+		if cnames[r.GetLabel()] && r.Type != "CNAME" && !cnameCompatible[r.Type] {
 			errs = append(errs, fmt.Errorf("cannot have CNAME and %s record with same name: %s", r.Type, r.GetLabelFQDN()))
 		}
 	}
@@ -606,6 +707,224 @@ func checkLabelHasMultipleTTLs(records []*models.RecordConfig) (errs []error) {
 	return errs
 }
 
+// checkWildcardPrecedence warns about records that have the same type as
+// a wildcard covering their name, such as www.example.com A next to
+// *.example.com A. That is valid, but the wildcard is never used for
+// www.example.com, which is sometimes a surprise.
+func checkWildcardPrecedence(records []*models.RecordConfig) (errs []error) {
+	wildcards := map[string]bool{}
+	for _, r := range records {
+		if strings.HasPrefix(r.NameFQDN, "*.") {
+			wildcards[r.NameFQDN+" "+r.Type] = true
+		}
+	}
+	if len(wildcards) == 0 {
+		return nil
+	}
+
+	ok := wildcardOK(records)
+	warned := map[string]bool{}
+	for _, r := range records {
+		if strings.HasPrefix(r.NameFQDN, "*.") || ok[r.NameFQDN] || warned[r.NameFQDN+" "+r.Type] {
+			continue
+		}
+		// Look for the closest wildcard above the name.
+		labels := strings.Split(r.NameFQDN, ".")
+		for i := 1; i < len(labels); i++ {
+			wildcard := "*." + strings.Join(labels[i:], ".")
+			if wildcards[wildcard+" "+r.Type] {
+				warned[r.NameFQDN+" "+r.Type] = true
+				errs = append(errs, Warning{fmt.Errorf("%s %s has its own records, so the wildcard %s %s doesn't apply to it", r.NameFQDN, r.Type, wildcard, r.Type)})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// checkWildcardShadowing warns about wildcard records of a type that a
+// name below the wildcard doesn't have, when the name has records of
+// other types. A wildcard only applies to names that don't exist (RFC
+// 4592), so once www.example.com has an A record, a query for its MX
+// records gets an empty answer rather than the MX records of
+// *.example.com. For MX this means that mail for www.example.com goes to
+// its A or AAAA address, or can't be delivered if it has neither. Names
+// with a CNAME or a delegation (NS) are left out, since their other
+// types are answered elsewhere. The warnings for a name are suppressed by
+// setting the metadata wildcard_ok on any of its records.
+func checkWildcardShadowing(records []*models.RecordConfig) (errs []error) {
+	wildcardTypes := map[string][]string{} // the types of each wildcard, in order
+	types := map[string][]string{}         // the types of each other name, in order
+	has := map[string]bool{}               // "name type" of each record
+	var names []string
+	for _, r := range records {
+		if has[r.NameFQDN+" "+r.Type] {
+			continue
+		}
+		has[r.NameFQDN+" "+r.Type] = true
+		if strings.HasPrefix(r.NameFQDN, "*.") {
+			wildcardTypes[r.NameFQDN] = append(wildcardTypes[r.NameFQDN], r.Type)
+			continue
+		}
+		if _, ok := types[r.NameFQDN]; !ok {
+			names = append(names, r.NameFQDN)
+		}
+		types[r.NameFQDN] = append(types[r.NameFQDN], r.Type)
+	}
+	if len(wildcardTypes) == 0 {
+		return nil
+	}
+
+	ok := wildcardOK(records)
+	for _, name := range names {
+		if ok[name] || has[name+" CNAME"] || has[name+" NS"] {
+			continue
+		}
+		own := append([]string(nil), types[name]...)
+		sort.Strings(own)
+		// Each type is compared with the closest wildcard above the name
+		// that has it, as for checkWildcardPrecedence.
+		seen := map[string]bool{}
+		labels := strings.Split(name, ".")
+		for i := 1; i < len(labels); i++ {
+			wildcard := "*." + strings.Join(labels[i:], ".")
+			for _, typ := range wildcardTypes[wildcard] {
+				if seen[typ] {
+					continue
+				}
+				seen[typ] = true
+				if has[name+" "+typ] {
+					continue // warned about by checkWildcardPrecedence
+				}
+				var result string
+				switch {
+				case typ != "MX":
+					result = fmt.Sprintf("queries for its %s records get an empty answer", typ)
+				case has[name+" A"] || has[name+" AAAA"]:
+					result = "mail for it goes to its own A/AAAA address instead of the wildcard's MX"
+				default:
+					result = "mail for it can't be delivered"
+				}
+				errs = append(errs, Warning{fmt.Errorf("%s has %s records, so the wildcard %s %s doesn't apply to it: %s (set the metadata wildcard_ok on its records if this is intended)",
+					name, strings.Join(own, "/"), wildcard, typ, result)})
+			}
+		}
+	}
+	return errs
+}
+
+// wildcardOK returns the names that have a record with the metadata
+// wildcard_ok, for which the wildcard warnings are suppressed.
+func wildcardOK(records []*models.RecordConfig) map[string]bool {
+	ok := map[string]bool{}
+	for _, r := range records {
+		if r.Metadata["wildcard_ok"] == "true" {
+			ok[r.NameFQDN] = true
+		}
+	}
+	return ok
+}
+
+// checkCAAOverride warns about CAA records below the apex that leave out
+// some of the CAA policy of the closest name above them. A CA only uses
+// the CAA records of the closest name that has any (RFC 8659), so the
+// records at shop.example.com replace those of example.com for
+// shop.example.com and the names below it, rather than adding to them.
+func checkCAAOverride(records []*models.RecordConfig) (errs []error) {
+	policies := map[string][]string{} // by name, "tag value" of each CAA record
+	has := map[string]bool{}          // "name tag value" of each CAA record
+	var names []string
+	for _, r := range records {
+		if r.Type != "CAA" {
+			continue
+		}
+		if _, ok := policies[r.NameFQDN]; !ok {
+			names = append(names, r.NameFQDN)
+		}
+		p := r.CaaTag + " " + r.GetTargetField()
+		policies[r.NameFQDN] = append(policies[r.NameFQDN], p)
+		has[r.NameFQDN+" "+p] = true
+	}
+
+	for _, name := range names {
+		// Find the closest name above with a policy.
+		labels := strings.Split(name, ".")
+		for i := 1; i < len(labels); i++ {
+			parent := strings.Join(labels[i:], ".")
+			if _, ok := policies[parent]; !ok {
+				continue
+			}
+			var missing []string
+			for _, p := range policies[parent] {
+				if !has[name+" "+p] {
+					missing = append(missing, p)
+				}
+			}
+			if len(missing) != 0 {
+				errs = append(errs, Warning{fmt.Errorf("the CAA records of %s replace those of %s for %s and the names below it, so these don't apply: %s", name, parent, name, strings.Join(missing, ", "))})
+			}
+			break
+		}
+	}
+	return errs
+}
+
+// checkDanglingTargets warns about MX and SRV records whose target is a
+// name of the domain that has no A, AAAA, CNAME or ALIAS record, so that
+// mail or the service can't reach it. A target that has no records at
+// all may still get an address from a wildcard. Targets outside the
+// domain, or in a zone delegated from it, are left out, as are the null
+// targets of RFC 7505 and RFC 2782 (".").
+func checkDanglingTargets(domain string, records []*models.RecordConfig) (errs []error) {
+	domain = strings.ToLower(domain)
+	has := map[string]bool{}       // "name type" of each record
+	exists := map[string]bool{}    // names that have records
+	delegated := map[string]bool{} // names below the apex with NS records
+	for _, r := range records {
+		name := strings.ToLower(r.NameFQDN)
+		has[name+" "+r.Type] = true
+		exists[name] = true
+		if r.Type == "NS" && name != domain {
+			delegated[name] = true
+		}
+	}
+	hasAddress := func(name string) bool {
+		return has[name+" A"] || has[name+" AAAA"] || has[name+" CNAME"] || has[name+" ALIAS"]
+	}
+
+	warned := map[string]bool{}
+	for _, r := range records {
+		if r.Type != "MX" && r.Type != "SRV" {
+			continue
+		}
+		target := strings.ToLower(strings.TrimSuffix(r.GetTargetField(), "."))
+		if target == "" || (target != domain && !strings.HasSuffix(target, "."+domain)) {
+			continue
+		}
+		if hasAddress(target) {
+			continue
+		}
+		// Look for a delegation, or if the target has no records, a
+		// wildcard that gives it an address, from the closest name up.
+		resolved := false
+		labels := strings.Split(target, ".")
+		for i := 0; i < len(labels) && !resolved; i++ {
+			name := strings.Join(labels[i:], ".")
+			resolved = delegated[name] || (i > 0 && !exists[target] && hasAddress("*."+name))
+			if name == domain {
+				break
+			}
+		}
+		key := strings.ToLower(r.NameFQDN) + " " + r.Type + " " + target
+		if resolved || warned[key] {
+			continue
+		}
+		warned[key] = true
+		errs = append(errs, Warning{fmt.Errorf("the %s record of %s points to %s, which has no A, AAAA or CNAME record", r.Type, r.NameFQDN, target)})
+	}
+	return errs
+}
+
 // We pull this out of checkProviderCapabilities() so that it's visible within
 // the package elsewhere, so that our test suite can look at the list of
 // capabilities we're checking and make sure that it's up-to-date.
@@ -617,12 +936,19 @@ var providerCapabilityChecks = []pairTypeCapability{
 	capabilityCheck("AUTODNSSEC", providers.CanAutoDNSSEC),
 	capabilityCheck("AZURE_ALIAS", providers.CanUseAzureAlias),
 	capabilityCheck("CAA", providers.CanUseCAA),
+	capabilityCheck("CDNSKEY", providers.CanUseCDNSKEY),
+	capabilityCheck("CDS", providers.CanUseCDS),
+	capabilityCheck("DNAME", providers.CanUseDNAME),
+	capabilityCheck("HTTPS", providers.CanUseHTTPS),
 	capabilityCheck("NAPTR", providers.CanUseNAPTR),
+	capabilityCheck("OPENPGPKEY", providers.CanUseOPENPGPKEY),
 	capabilityCheck("PTR", providers.CanUsePTR),
 	capabilityCheck("R53_ALIAS", providers.CanUseRoute53Alias),
+	capabilityCheck("SMIMEA", providers.CanUseSMIMEA),
 	capabilityCheck("SOA", providers.CanUseSOA),
 	capabilityCheck("SRV", providers.CanUseSRV),
 	capabilityCheck("SSHFP", providers.CanUseSSHFP),
+	capabilityCheck("SVCB", providers.CanUseSVCB),
 	capabilityCheck("TLSA", providers.CanUseTLSA),
 
 	// DS needs special record-level checks
@@ -651,9 +977,9 @@ func capabilityCheck(rType string, caps ...providers.Capability) pairTypeCapabil
 	}
 }
 
-func providerHasAtLeastOneCapability(pType string, caps ...providers.Capability) bool {
+func providerHasAtLeastOneCapability(pType string, overrides []map[providers.Capability]bool, caps ...providers.Capability) bool {
 	for _, cap := range caps {
-		if providers.ProviderHasCapability(pType, cap) {
+		if providers.ProviderHasCapabilityOverridden(pType, cap, overrides...) {
 			return true
 		}
 	}
@@ -661,6 +987,25 @@ func providerHasAtLeastOneCapability(pType string, caps ...providers.Capability)
 	return false
 }
 
+// capabilityOverrides returns the capability overrides that apply to
+// provider when it serves dc. The capabilities discovered from the
+// account come first, then the overrides from creds.json, so that the
+// domain's overrides (the "capabilities" metadata) take precedence.
+func capabilityOverrides(dc *models.DomainConfig, provider *models.DNSProviderInstance) ([]map[providers.Capability]bool, error) {
+	var overrides []map[providers.Capability]bool
+	for _, s := range []string{provider.DiscoveredCaps, provider.CapabilityOverrides, dc.Metadata["capabilities"]} {
+		o, err := providers.ParseCapabilityOverrides(s)
+		if err != nil {
+			return nil, err
+		}
+		if err := providers.ValidateCapabilityOverrides(provider.ProviderType, o); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, nil
+}
+
 func checkProviderDS(pType string, records models.Records) error {
 	switch {
 	case providers.ProviderHasCapability(pType, providers.CanUseDS):
@@ -684,7 +1029,10 @@ func checkProviderDS(pType string, records models.Records) error {
 	return nil
 }
 
-func checkProviderCapabilities(dc *models.DomainConfig) error {
+// checkProviderCapabilities returns an error for each capability the
+// domain uses that one of its providers doesn't support, so that all of
+// them are listed before any provider is contacted.
+func checkProviderCapabilities(dc *models.DomainConfig) (errs []error) {
 	// Check if the zone uses a capability that the provider doesn't
 	// support.
 	for _, ty := range providerCapabilityChecks {
@@ -716,20 +1064,40 @@ func checkProviderCapabilities(dc *models.DomainConfig) error {
 				// be performed.
 				continue
 			}
+			overrides, err := capabilityOverrides(dc, provider)
+			if err != nil {
+				return append(errs, fmt.Errorf("domain %s: %w", dc.Name, err))
+			}
 			// fmt.Printf("  (checking if %q can %q for domain %q)\n", provider.ProviderType, ty.rType, dc.Name)
-			if !providerHasAtLeastOneCapability(provider.ProviderType, ty.caps...) {
-				return fmt.Errorf("domain %s uses %s records, but DNS provider type %s does not support them", dc.Name, ty.rType, provider.ProviderType)
+			if !providerHasAtLeastOneCapability(provider.ProviderType, overrides, ty.caps...) {
+				ve := &models.ValidationError{
+					Code:    models.ErrCodeUnsupportedRecordType,
+					Domain:  dc.Name,
+					Message: fmt.Sprintf("domain %s uses %s records, but DNS provider type %s does not support them", dc.Name, ty.rType, provider.ProviderType),
+				}
+				for _, r := range dc.Records {
+					if r.Type == ty.rType {
+						ve.Record = models.RecordIdentity(r)
+						ve.Source = r.Source
+						if r.Source != "" {
+							ve.Message += fmt.Sprintf(" (first at %s)", r.Source)
+						}
+						break
+					}
+				}
+				errs = append(errs, ve)
+				continue
 			}
 
 			if ty.checkFunc != nil {
 				checkErr := ty.checkFunc(provider.ProviderType, dc.Records)
 				if checkErr != nil {
-					return fmt.Errorf("while checking %s records in domain %s: %w", ty.rType, dc.Name, checkErr)
+					errs = append(errs, fmt.Errorf("while checking %s records in domain %s: %w", ty.rType, dc.Name, checkErr))
 				}
 			}
 		}
 	}
-	return nil
+	return errs
 }
 
 func applyRecordTransforms(domain *models.DomainConfig) error {
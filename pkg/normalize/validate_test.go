@@ -1,8 +1,10 @@
 package normalize
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
@@ -199,6 +201,22 @@ func TestNSAtRoot(t *testing.T) {
 	}
 }
 
+func TestDNAMEAtRoot(t *testing.T) {
+	// do not allow dname records for @
+	rec := &models.RecordConfig{Type: "DNAME"}
+	rec.SetLabel("old", "foo.com")
+	rec.SetTarget("new.foo.com.")
+	errs := checkTargets(rec, "foo.com")
+	if len(errs) > 0 {
+		t.Errorf("Expect no error with dname record on subdomain, got %v", errs)
+	}
+	rec.SetLabel("@", "foo.com")
+	errs = checkTargets(rec, "foo.com")
+	if len(errs) != 1 {
+		t.Error("Expect error with dname record on @")
+	}
+}
+
 func TestTransforms(t *testing.T) {
 	var tests = []struct {
 		givenIP         string
@@ -244,8 +262,11 @@ func TestCNAMEMutex(t *testing.T) {
 	}{
 		{"A", "foo", true},
 		{"A", "foo2", false},
+		{"TXT", "foo", true},
 		{"CNAME", "foo", true},
 		{"CNAME", "foo2", false},
+		{"RRSIG", "foo", false},
+		{"NSEC", "foo", false},
 	}
 	for _, tst := range tests {
 		t.Run(fmt.Sprintf("%s %s", tst.rType, tst.name), func(t *testing.T) {
@@ -256,10 +277,13 @@ func TestCNAMEMutex(t *testing.T) {
 				Name:    "example.com",
 				Records: []*models.RecordConfig{recA, recB},
 			}
-			errs := checkCNAMEs(dc)
+			errs := CheckCNAMEs(dc)
 			if errs != nil && !tst.fail {
 				t.Error("Got error but expected none")
 			}
+			if len(errs) != 0 && !strings.Contains(errs[0].Error(), "foo.example.com") {
+				t.Errorf("Error %q doesn't name the label", errs[0])
+			}
 			if errs == nil && tst.fail {
 				t.Error("Expected error but got none")
 			}
@@ -285,6 +309,61 @@ func TestCAAValidation(t *testing.T) {
 	}
 }
 
+func TestSVCBValidation(t *testing.T) {
+	for _, tc := range []struct {
+		priority uint16
+		params   string
+		ok       bool
+		exp      string
+	}{
+		{1, `port=8443 alpn="h2,h3"`, true, `alpn="h2,h3" port="8443"`},
+		{0, "", true, ""},
+		{0, "alpn=h2", false, ""}, // AliasMode has no SvcParams
+		{1, "port=notanumber", false, ""},
+	} {
+		config := &models.DNSConfig{
+			Domains: []*models.DomainConfig{
+				{
+					Name:          "example.com",
+					RegistrarName: "BIND",
+					Records: []*models.RecordConfig{
+						makeRC("@", "example.com", ".", models.RecordConfig{Type: "HTTPS", TTL: 300, SvcPriority: tc.priority, SvcParams: tc.params}),
+					},
+				},
+			},
+		}
+		errs := ValidateAndNormalizeConfig(config)
+		if tc.ok != (len(errs) == 0) {
+			t.Errorf("%d %s: got errors %v", tc.priority, tc.params, errs)
+			continue
+		}
+		if got := config.Domains[0].Records[0].SvcParams; tc.ok && got != tc.exp {
+			t.Errorf("%d %s: params are normalized to %s, expected %s", tc.priority, tc.params, got, tc.exp)
+		}
+	}
+}
+
+func TestPlugins(t *testing.T) {
+	config := &models.DNSConfig{
+		Domains: []*models.DomainConfig{
+			{
+				Name:          "example.com",
+				RegistrarName: "BIND",
+				Metadata:      map[string]string{"plugins": "min_ttl", "min_ttl": "600"},
+				Records: []*models.RecordConfig{
+					makeRC("www", "example.com", "192.0.2.1", models.RecordConfig{Type: "A", TTL: 300}),
+				},
+			},
+		},
+	}
+	if errs := ValidateAndNormalizeConfig(config); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if ttl := config.Domains[0].Records[0].TTL; ttl != 600 {
+		t.Errorf("TTL is %d, expected the plugin to raise it to 600", ttl)
+	}
+}
+
 func TestCheckDuplicates(t *testing.T) {
 	records := []*models.RecordConfig{
 		// The only difference is the target:
@@ -367,6 +446,165 @@ func TestCheckLabelHasNoMultipleTTLs(t *testing.T) {
 	}
 }
 
+func TestCheckWildcardPrecedence(t *testing.T) {
+	records := []*models.RecordConfig{
+		makeRC("*", "example.com", "1.1.1.1", models.RecordConfig{Type: "A"}),
+		makeRC("www", "example.com", "2.2.2.2", models.RecordConfig{Type: "A"}),
+		makeRC("www", "example.com", "3.3.3.3", models.RecordConfig{Type: "A"}),
+		makeRC("a.b", "example.com", "4.4.4.4", models.RecordConfig{Type: "A"}),
+		// Different types don't overlap:
+		makeRC("mail", "example.com", "mx.example.com.", models.RecordConfig{Type: "MX"}),
+		// The wildcard doesn't cover the apex:
+		makeRC("@", "example.com", "5.5.5.5", models.RecordConfig{Type: "A"}),
+	}
+	var got []string
+	for _, err := range checkWildcardPrecedence(records) {
+		if _, ok := err.(Warning); !ok {
+			t.Errorf("expected a warning, got error %v", err)
+		}
+		got = append(got, err.Error())
+	}
+	exp := []string{
+		"www.example.com A has its own records, so the wildcard *.example.com A doesn't apply to it",
+		"a.b.example.com A has its own records, so the wildcard *.example.com A doesn't apply to it",
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("got warnings:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+}
+
+func TestCheckWildcardPrecedenceWildcardOnly(t *testing.T) {
+	records := []*models.RecordConfig{
+		makeRC("*", "example.com", "1.1.1.1", models.RecordConfig{Type: "A"}),
+		makeRC("*", "example.com", "2.2.2.2", models.RecordConfig{Type: "A"}),
+		makeRC("*.sub", "example.com", "3.3.3.3", models.RecordConfig{Type: "A"}),
+		makeRC("@", "example.com", "4.4.4.4", models.RecordConfig{Type: "A"}),
+	}
+	if errs := checkWildcardPrecedence(records); len(errs) != 0 {
+		t.Errorf("expected no warnings, got %v", errs)
+	}
+}
+
+func TestCheckWildcardShadowing(t *testing.T) {
+	records := []*models.RecordConfig{
+		makeRC("*", "example.com", "mx.example.net.", models.RecordConfig{Type: "MX"}),
+		makeRC("*", "example.com", "\"v=spf1 -all\"", models.RecordConfig{Type: "TXT"}),
+		makeRC("*.sub", "example.com", "1.1.1.1", models.RecordConfig{Type: "A"}),
+		// Mail for www goes to its A record:
+		makeRC("www", "example.com", "2.2.2.2", models.RecordConfig{Type: "A"}),
+		makeRC("www", "example.com", "\"v=spf1 a -all\"", models.RecordConfig{Type: "TXT"}),
+		// Mail for _dmarc can't be delivered:
+		makeRC("_dmarc", "example.com", "\"v=DMARC1; p=reject\"", models.RecordConfig{Type: "TXT"}),
+		// The closest wildcard with A is *.sub, and with MX is *:
+		makeRC("x.sub", "example.com", "x.example.net.", models.RecordConfig{Type: "NS"}),
+		makeRC("y.sub", "example.com", "\"y\"", models.RecordConfig{Type: "TXT"}),
+		// A CNAME answers every type:
+		makeRC("alias", "example.com", "www.example.com.", models.RecordConfig{Type: "CNAME"}),
+		// The wildcard doesn't cover the apex:
+		makeRC("@", "example.com", "3.3.3.3", models.RecordConfig{Type: "A"}),
+	}
+	var got []string
+	for _, err := range checkWildcardShadowing(records) {
+		if _, ok := err.(Warning); !ok {
+			t.Errorf("expected a warning, got error %v", err)
+		}
+		got = append(got, err.Error())
+	}
+	const suffix = " (set the metadata wildcard_ok on its records if this is intended)"
+	exp := []string{
+		"www.example.com has A/TXT records, so the wildcard *.example.com MX doesn't apply to it: mail for it goes to its own A/AAAA address instead of the wildcard's MX" + suffix,
+		"_dmarc.example.com has TXT records, so the wildcard *.example.com MX doesn't apply to it: mail for it can't be delivered" + suffix,
+		"y.sub.example.com has TXT records, so the wildcard *.sub.example.com A doesn't apply to it: queries for its A records get an empty answer" + suffix,
+		"y.sub.example.com has TXT records, so the wildcard *.example.com MX doesn't apply to it: mail for it can't be delivered" + suffix,
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("got warnings:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+}
+
+func TestCheckWildcardShadowingSuppressed(t *testing.T) {
+	ok := map[string]string{"wildcard_ok": "true"}
+	records := []*models.RecordConfig{
+		makeRC("*", "example.com", "mx.example.net.", models.RecordConfig{Type: "MX"}),
+		makeRC("*", "example.com", "1.1.1.1", models.RecordConfig{Type: "A"}),
+		makeRC("www", "example.com", "2.2.2.2", models.RecordConfig{Type: "A", Metadata: ok}),
+		makeRC("www", "example.com", "\"v=spf1 a -all\"", models.RecordConfig{Type: "TXT"}),
+		makeRC("_dmarc", "example.com", "\"v=DMARC1; p=reject\"", models.RecordConfig{Type: "TXT", Metadata: ok}),
+	}
+	if errs := checkWildcardShadowing(records); len(errs) != 0 {
+		t.Errorf("expected no warnings, got %v", errs)
+	}
+	// The same-type warning about www A is suppressed too.
+	if errs := checkWildcardPrecedence(records); len(errs) != 0 {
+		t.Errorf("expected no warnings, got %v", errs)
+	}
+}
+
+func TestCheckCAAOverride(t *testing.T) {
+	records := []*models.RecordConfig{
+		makeRC("@", "example.com", "letsencrypt.org", models.RecordConfig{Type: "CAA", CaaTag: "issue"}),
+		makeRC("@", "example.com", "mailto:security@example.com", models.RecordConfig{Type: "CAA", CaaTag: "iodef"}),
+		// Includes the apex policy, so it only adds to it:
+		makeRC("www", "example.com", "letsencrypt.org", models.RecordConfig{Type: "CAA", CaaTag: "issue"}),
+		makeRC("www", "example.com", "mailto:security@example.com", models.RecordConfig{Type: "CAA", CaaTag: "iodef"}),
+		makeRC("www", "example.com", "digicert.com", models.RecordConfig{Type: "CAA", CaaTag: "issue"}),
+		// Replaces the apex policy:
+		makeRC("shop", "example.com", "digicert.com", models.RecordConfig{Type: "CAA", CaaTag: "issue"}),
+		// Compared to shop, the closest name with a policy:
+		makeRC("eu.shop", "example.com", "digicert.com", models.RecordConfig{Type: "CAA", CaaTag: "issue"}),
+	}
+	var got []string
+	for _, err := range checkCAAOverride(records) {
+		if _, ok := err.(Warning); !ok {
+			t.Errorf("expected a warning, got error %v", err)
+		}
+		got = append(got, err.Error())
+	}
+	exp := []string{
+		"the CAA records of shop.example.com replace those of example.com for shop.example.com and the names below it, so these don't apply: issue letsencrypt.org, iodef mailto:security@example.com",
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("got warnings:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+}
+
+func TestCheckDanglingTargets(t *testing.T) {
+	records := []*models.RecordConfig{
+		makeRC("mail", "example.com", "1.1.1.1", models.RecordConfig{Type: "A"}),
+		makeRC("www", "example.com", "mail.example.com.", models.RecordConfig{Type: "CNAME"}),
+		// Valid: the targets have an A record, a CNAME, or are elsewhere.
+		makeRC("@", "example.com", "mail.example.com.", models.RecordConfig{Type: "MX", MxPreference: 10}),
+		makeRC("@", "example.com", "www.example.com.", models.RecordConfig{Type: "MX", MxPreference: 20}),
+		makeRC("@", "example.com", "mx.example.net.", models.RecordConfig{Type: "MX", MxPreference: 30}),
+		makeRC("_sip._tcp", "example.com", "mail.example.com.", models.RecordConfig{Type: "SRV", SrvPort: 5060}),
+		// Null MX:
+		makeRC("nomail", "example.com", ".", models.RecordConfig{Type: "MX"}),
+		// Delegated, and given an address by a wildcard:
+		makeRC("sub", "example.com", "ns1.example.net.", models.RecordConfig{Type: "NS"}),
+		makeRC("x", "example.com", "mx.sub.example.com.", models.RecordConfig{Type: "MX"}),
+		makeRC("*.wild", "example.com", "2.2.2.2", models.RecordConfig{Type: "A"}),
+		makeRC("y", "example.com", "mx.wild.example.com.", models.RecordConfig{Type: "MX"}),
+		// Dangling: a name without records, and one with only a TXT record.
+		makeRC("@", "example.com", "mx2.example.com.", models.RecordConfig{Type: "MX", MxPreference: 40}),
+		makeRC("txt", "example.com", "\"v=spf1 -all\"", models.RecordConfig{Type: "TXT"}),
+		makeRC("_xmpp._tcp", "example.com", "txt.example.com.", models.RecordConfig{Type: "SRV", SrvPort: 5222}),
+	}
+	var got []string
+	for _, err := range checkDanglingTargets("example.com", records) {
+		if _, ok := err.(Warning); !ok {
+			t.Errorf("expected a warning, got error %v", err)
+		}
+		got = append(got, err.Error())
+	}
+	exp := []string{
+		"the MX record of example.com points to mx2.example.com, which has no A, AAAA or CNAME record",
+		"the SRV record of _xmpp._tcp.example.com points to txt.example.com, which has no A, AAAA or CNAME record",
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("got warnings:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+}
+
 func TestTLSAValidation(t *testing.T) {
 	config := &models.DNSConfig{
 		Domains: []*models.DomainConfig{
@@ -391,6 +629,8 @@ const (
 	ProviderFullDS      = "FULL_DS_SUPPORT"
 	ProviderChildDSOnly = "CHILD_DS_SUPPORT"
 	ProviderBothDSCaps  = "BOTH_DS_CAPABILITIES"
+
+	ProviderOptionalDNSSEC = "OPTIONAL_DNSSEC_SUPPORT"
 )
 
 func init() {
@@ -405,6 +645,109 @@ func init() {
 		providers.CanUseDS:            providers.Can(),
 		providers.CanUseDSForChildren: providers.Can(),
 	})
+	providers.RegisterDomainServiceProviderType(ProviderOptionalDNSSEC, providers.DspFuncs{}, providers.DocumentationNotes{
+		providers.CanAutoDNSSEC: providers.Cannot(),
+	}, providers.OverridableCapabilities{providers.CanAutoDNSSEC})
+}
+
+func TestCapabilityOverrides(t *testing.T) {
+	tests := []struct {
+		name       string
+		pType      string
+		discovered string
+		credsOver  string
+		domainOver string
+		wantErr    bool
+	}{
+		{"no overrides", ProviderOptionalDNSSEC, "", "", "", true},
+		{"creds override", ProviderOptionalDNSSEC, "", "CanAutoDNSSEC=true", "", false},
+		{"domain override", ProviderOptionalDNSSEC, "", "", "CanAutoDNSSEC", false},
+		{"domain overrides creds", ProviderOptionalDNSSEC, "", "CanAutoDNSSEC=true", "CanAutoDNSSEC=false", true},
+		{"not overridable", ProviderNoDS, "", "CanAutoDNSSEC", "", true},
+		{"unknown capability", ProviderOptionalDNSSEC, "", "CanAutoDNSSECX", "", true},
+		{"invalid value", ProviderOptionalDNSSEC, "", "CanAutoDNSSEC=maybe", "", true},
+		{"discovered", ProviderOptionalDNSSEC, "CanAutoDNSSEC=true", "", "", false},
+		{"discovered unavailable", ProviderOptionalDNSSEC, "CanAutoDNSSEC=false", "", "", true},
+		{"creds overrides discovered", ProviderOptionalDNSSEC, "CanAutoDNSSEC=true", "CanAutoDNSSEC=false", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &models.DomainConfig{
+				Name:       "example.com",
+				AutoDNSSEC: "on",
+				Metadata:   map[string]string{},
+				DNSProviderInstances: []*models.DNSProviderInstance{{
+					ProviderBase:        models.ProviderBase{Name: "dsp", ProviderType: tt.pType},
+					CapabilityOverrides: tt.credsOver,
+					DiscoveredCaps:      tt.discovered,
+				}},
+			}
+			if tt.domainOver != "" {
+				dc.Metadata["capabilities"] = tt.domainOver
+			}
+			errs := checkProviderCapabilities(dc)
+			if (len(errs) != 0) != tt.wantErr {
+				t.Errorf("checkProviderCapabilities() errors = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCapabilityErrorCode(t *testing.T) {
+	tlsa := &models.RecordConfig{Type: "TLSA"}
+	tlsa.SetLabel("_443._tcp", "example.com")
+	dc := &models.DomainConfig{
+		Name:    "example.com",
+		Records: models.Records{tlsa},
+		DNSProviderInstances: []*models.DNSProviderInstance{{
+			ProviderBase: models.ProviderBase{Name: "dsp", ProviderType: ProviderNoDS},
+		}},
+	}
+	errs := checkProviderCapabilities(dc)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	var ve *models.ValidationError
+	if !errors.As(errs[0], &ve) {
+		t.Fatalf("expected a *models.ValidationError, got %#v", errs[0])
+	}
+	exp := models.ValidationError{
+		Code:    models.ErrCodeUnsupportedRecordType,
+		Domain:  "example.com",
+		Record:  "_443._tcp.example.com TLSA",
+		Message: "domain example.com uses TLSA records, but DNS provider type NO_DS_SUPPORT does not support them",
+	}
+	if *ve != exp {
+		t.Errorf("got %+v, expected %+v", *ve, exp)
+	}
+}
+
+func TestCapabilityErrorsConsolidated(t *testing.T) {
+	var recs models.Records
+	for _, typ := range []string{"SSHFP", "TLSA", "A", "DS"} {
+		rc := &models.RecordConfig{Type: typ}
+		rc.SetLabel("host", "example.com")
+		recs = append(recs, rc)
+	}
+	dc := &models.DomainConfig{
+		Name:    "example.com",
+		Records: recs,
+		DNSProviderInstances: []*models.DNSProviderInstance{{
+			ProviderBase: models.ProviderBase{Name: "dsp", ProviderType: ProviderNoDS},
+		}},
+	}
+	var got []string
+	for _, err := range checkProviderCapabilities(dc) {
+		got = append(got, err.Error())
+	}
+	exp := []string{
+		"domain example.com uses SSHFP records, but DNS provider type NO_DS_SUPPORT does not support them",
+		"domain example.com uses TLSA records, but DNS provider type NO_DS_SUPPORT does not support them",
+		"domain example.com uses DS records, but DNS provider type NO_DS_SUPPORT does not support them",
+	}
+	if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		t.Errorf("got errors:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
 }
 
 func Test_DSChecks(t *testing.T) {
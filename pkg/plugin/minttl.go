@@ -0,0 +1,35 @@
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// MetaMinTTL is the domain metadata that sets the smallest TTL the
+// min_ttl plugin allows.
+const MetaMinTTL = "min_ttl"
+
+func init() {
+	Register("min_ttl", Func(minTTL))
+}
+
+// minTTL raises the TTL of each record to at least the domain's min_ttl
+// metadata. It is an example of a plugin that enforces a policy.
+func minTTL(dc *models.DomainConfig, records models.Records) (models.Records, error) {
+	v, ok := dc.Metadata[MetaMinTTL]
+	if !ok {
+		return nil, fmt.Errorf("the %s metadata is not set", MetaMinTTL)
+	}
+	min, err := strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q is not a TTL", MetaMinTTL, v)
+	}
+	for _, rc := range records {
+		if rc.TTL < uint32(min) {
+			rc.TTL = uint32(min)
+		}
+	}
+	return records, nil
+}
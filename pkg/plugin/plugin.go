@@ -0,0 +1,99 @@
+// Package plugin runs record transforms that are compiled into
+// DNSControl but not part of it, such as an organization's naming
+// policy, without forking DNSControl.
+//
+// A plugin registers itself by name, usually from an init function:
+//
+//	func init() {
+//		plugin.Register("naming_policy", namingPolicy{})
+//	}
+//
+// and a domain lists the plugins to run on it, in order, in its
+// "plugins" metadata:
+//
+//	D("example.com", REG, DnsProvider(DNS), {"plugins": "naming_policy,min_ttl"}, ...)
+//
+// The plugins run while the configuration is normalized, before the
+// records are checked and compared with the providers' records.
+package plugin
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// Plugin transforms the records of a domain.
+type Plugin interface {
+	// Transform returns the domain's records after the plugin's changes.
+	// It may change, add or remove records. New records must be complete,
+	// with SetLabel and a TTL. dc is the domain, for its name and metadata;
+	// its records are the records to transform.
+	Transform(dc *models.DomainConfig, records models.Records) (models.Records, error)
+}
+
+// Func is a function that is a Plugin.
+type Func func(dc *models.DomainConfig, records models.Records) (models.Records, error)
+
+// Transform calls f.
+func (f Func) Transform(dc *models.DomainConfig, records models.Records) (models.Records, error) {
+	return f(dc, records)
+}
+
+// MetaPlugins is the domain metadata that lists the plugins to run on
+// the domain, separated by commas.
+const MetaPlugins = "plugins"
+
+var (
+	mu      sync.RWMutex
+	plugins = map[string]Plugin{}
+)
+
+// Register makes a plugin available by name. It panics if a plugin with
+// the same name is already registered.
+func Register(name string, p Plugin) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := plugins[name]; ok {
+		panic(fmt.Sprintf("plugin %q is already registered", name))
+	}
+	plugins[name] = p
+}
+
+// Names returns the names of the registered plugins, sorted.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	var names []string
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply runs the plugins listed in the domain's metadata on its records,
+// in order, and replaces its records with the result.
+func Apply(dc *models.DomainConfig) error {
+	for _, name := range strings.Split(dc.Metadata[MetaPlugins], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		mu.RLock()
+		p, ok := plugins[name]
+		mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("domain %s: unknown plugin %q (available: %s)", dc.Name, name, strings.Join(Names(), ", "))
+		}
+		records, err := p.Transform(dc, dc.Records)
+		if err != nil {
+			return fmt.Errorf("domain %s: plugin %s: %w", dc.Name, name, err)
+		}
+		dc.Records = records
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func makeRC(label, typ, target string, ttl uint32) *models.RecordConfig {
+	rc := &models.RecordConfig{Type: typ, TTL: ttl}
+	rc.SetLabel(label, "example.com")
+	rc.SetTarget(target)
+	return rc
+}
+
+func init() {
+	// A naming policy: hosts are named "srv-*", and each gets a
+	// "*-mgmt" record for its management interface.
+	Register("test_naming", Func(func(dc *models.DomainConfig, records models.Records) (models.Records, error) {
+		var result models.Records
+		for _, rc := range records {
+			if rc.Type == "A" && !strings.HasPrefix(rc.GetLabel(), "srv-") {
+				rc.SetLabel("srv-"+rc.GetLabel(), dc.Name)
+			}
+			result = append(result, rc)
+			if rc.Type == "A" {
+				mgmt := makeRC(rc.GetLabel()+"-mgmt", "A", "10.0.0."+strings.TrimPrefix(rc.GetTargetField(), "192.0.2."), rc.TTL)
+				result = append(result, mgmt)
+			}
+		}
+		return result, nil
+	}))
+}
+
+func TestApply(t *testing.T) {
+	dc := &models.DomainConfig{
+		Name:     "example.com",
+		Metadata: map[string]string{MetaPlugins: "test_naming, min_ttl", MetaMinTTL: "600"},
+		Records: models.Records{
+			makeRC("web", "A", "192.0.2.1", 300),
+			makeRC("srv-db", "A", "192.0.2.2", 3600),
+			makeRC("@", "MX", "mail.example.com.", 300),
+		},
+	}
+	if err := Apply(dc); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rc := range dc.Records {
+		got = append(got, rc.GetLabelFQDN()+" "+rc.Type+" "+rc.ToDiffable())
+	}
+	exp := []string{
+		"srv-web.example.com A 192.0.2.1 ttl=600",
+		"srv-web-mgmt.example.com A 10.0.0.1 ttl=600",
+		"srv-db.example.com A 192.0.2.2 ttl=3600",
+		"srv-db-mgmt.example.com A 10.0.0.2 ttl=3600",
+		"example.com MX 0 mail.example.com. ttl=600",
+	}
+	if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	for _, tc := range []struct {
+		meta map[string]string
+		err  string
+	}{
+		{map[string]string{MetaPlugins: "nonesuch"}, `domain example.com: unknown plugin "nonesuch" (available: `},
+		{map[string]string{MetaPlugins: "min_ttl"}, "domain example.com: plugin min_ttl: the min_ttl metadata is not set"},
+		{map[string]string{MetaPlugins: "min_ttl", MetaMinTTL: "soon"}, `domain example.com: plugin min_ttl: min_ttl "soon" is not a TTL`},
+	} {
+		dc := &models.DomainConfig{Name: "example.com", Metadata: tc.meta}
+		err := Apply(dc)
+		if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+			t.Errorf("%v: got error %v, expected %q", tc.meta, err, tc.err)
+		}
+	}
+}
+
+func TestApplyNone(t *testing.T) {
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{makeRC("web", "A", "192.0.2.1", 300)}}
+	if err := Apply(dc); err != nil {
+		t.Fatal(err)
+	}
+	if len(dc.Records) != 1 || dc.Records[0].GetLabel() != "web" {
+		t.Errorf("records changed without plugins: %v", dc.Records)
+	}
+}
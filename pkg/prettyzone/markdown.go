@@ -0,0 +1,77 @@
+package prettyzone
+
+// Generate Markdown tables of records, for documentation and reviews.
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// WriteMarkdownTable writes the records as a Markdown table with name,
+// type, TTL and value columns. The records are sorted like a zonefile,
+// and each RRset (the records with the same name and type) is a single
+// row with one line per value. If the records of an RRset have
+// different TTLs, each TTL is listed.
+func WriteMarkdownTable(w io.Writer, records models.Records, origin string) error {
+	z := PrettySort(records, origin, 0, nil)
+	sort.Sort(z)
+
+	if _, err := fmt.Fprintln(w, "| Name | Type | TTL | Value |"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "|------|------|-----|-------|"); err != nil {
+		return err
+	}
+
+	recs := z.Records
+	for len(recs) != 0 {
+		// Find the end of the RRset.
+		n := 1
+		for n < len(recs) && recs[n].GetLabel() == recs[0].GetLabel() && recs[n].Type == recs[0].Type {
+			n++
+		}
+
+		var ttls, values []string
+		for _, rc := range recs[:n] {
+			if ttl := fmt.Sprint(rc.TTL); !contains(ttls, ttl) {
+				ttls = append(ttls, ttl)
+			}
+			values = append(values, markdownEscape(rc.GetTargetCombined()))
+		}
+
+		if _, err := fmt.Fprintf(w, "| %s | %s | %s | %s |\n",
+			markdownEscape(recs[0].GetLabel()), recs[0].Type,
+			strings.Join(ttls, ", "), strings.Join(values, "<br>")); err != nil {
+			return err
+		}
+		recs = recs[n:]
+	}
+	return nil
+}
+
+// markdownEscape escapes the characters of s that would end a table
+// cell or be taken as formatting.
+func markdownEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		"|", `\|`,
+		"*", `\*`,
+		"_", `\_`,
+		"`", "\\`",
+		"<", "&lt;",
+		">", "&gt;",
+	).Replace(s)
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
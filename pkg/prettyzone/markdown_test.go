@@ -0,0 +1,58 @@
+package prettyzone
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/miekg/dns"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestWriteMarkdownTable(t *testing.T) {
+	var rrs []dns.RR
+	for _, s := range []string{
+		"example.com. 3600 IN MX 20 mx2.example.com.",
+		"example.com. 3600 IN MX 10 mx1.example.com.",
+		`example.com. 300 IN TXT "v=spf1 include:_spf.example.com ~all"`,
+		"www.example.com. 300 IN A 192.0.2.2",
+		"www.example.com. 300 IN A 192.0.2.1",
+		"www.example.com. 600 IN A 192.0.2.3",
+		"ftp.example.com. 300 IN CNAME www.example.com.",
+		`_dmarc.example.com. 300 IN TXT "v=DMARC1; p=reject; rua=mailto:a|b@example.com"`,
+		"_sip._tcp.example.com. 300 IN SRV 10 60 5060 sip.example.com.",
+	} {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rrs = append(rrs, rr)
+	}
+	recs, err := models.RRstoRCs(rrs, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdownTable(&buf, recs, "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "records.md")
+	if *update {
+		if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exp, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(exp) {
+		t.Errorf("got:\n%s\nexpected (%s):\n%s", buf.String(), golden, exp)
+	}
+}
@@ -0,0 +1,122 @@
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// GroupedPrinter is a CLI that prints the output of each domain as a
+// group: a header summarizing the domain's corrections, such as
+// "==> example.com: 3 corrections (gcore: 2, none: 1)", followed by
+// the domain's output indented so that editors and log viewers can
+// fold it. A domain's output is held until the next domain starts, or
+// Flush is called, since the header needs its counts.
+type GroupedPrinter struct {
+	ConsolePrinter // prints to buf
+
+	out      CLI
+	buf      bytes.Buffer
+	domain   string
+	provider string
+	counts   []providerCount
+	errors   int
+}
+
+// providerCount is the number of corrections of a provider.
+type providerCount struct {
+	name string
+	n    int
+}
+
+// NewGroupedPrinter returns a GroupedPrinter that prints to out.
+func NewGroupedPrinter(out CLI) *GroupedPrinter {
+	g := &GroupedPrinter{out: out}
+	g.ConsolePrinter.Writer = &g.buf
+	return g
+}
+
+// StartDomain is called at the start of each domain.
+func (g *GroupedPrinter) StartDomain(domain string) {
+	g.Flush()
+	g.domain = domain
+}
+
+// StartDNSProvider is called at the start of each new provider.
+func (g *GroupedPrinter) StartDNSProvider(provider string, skip bool) {
+	g.provider = provider
+	g.ConsolePrinter.StartDNSProvider(provider, skip)
+}
+
+// StartRegistrar is called at the start of each new registrar.
+func (g *GroupedPrinter) StartRegistrar(provider string, skip bool) {
+	g.provider = provider
+	g.ConsolePrinter.StartRegistrar(provider, skip)
+}
+
+// EndProvider is called at the end of each provider.
+func (g *GroupedPrinter) EndProvider(numCorrections int, err error) {
+	if err != nil {
+		g.errors++
+	} else if numCorrections != 0 {
+		g.counts = append(g.counts, providerCount{g.provider, numCorrections})
+	}
+	g.ConsolePrinter.EndProvider(numCorrections, err)
+}
+
+// PromptToRun prompts the user to see if they want to execute a
+// correction. The domain's output so far is printed first, without
+// its header.
+func (g *GroupedPrinter) PromptToRun() bool {
+	g.out.Printf("%s", g.buf.String())
+	g.buf.Reset()
+	return g.out.PromptToRun()
+}
+
+// Debugf is called to print/format debug information. It isn't
+// grouped, since whether it is printed is up to the underlying CLI.
+func (g *GroupedPrinter) Debugf(format string, args ...interface{}) {
+	g.out.Debugf(format, args...)
+}
+
+// Flush prints the current domain's group, if any, and any output
+// since the last group.
+func (g *GroupedPrinter) Flush() {
+	body := g.buf.String()
+	g.buf.Reset()
+	if g.domain == "" {
+		if body != "" {
+			g.out.Printf("%s", body)
+		}
+		return
+	}
+
+	total := 0
+	var counts []string
+	for _, c := range g.counts {
+		total += c.n
+		counts = append(counts, fmt.Sprintf("%s: %d", c.name, c.n))
+	}
+	header := fmt.Sprintf("==> %s: %d correction%s", g.domain, total, plural(total))
+	if len(counts) != 0 {
+		header += " (" + strings.Join(counts, ", ") + ")"
+	}
+	if g.errors != 0 {
+		header += fmt.Sprintf(", %d error%s", g.errors, plural(g.errors))
+	}
+	g.out.Printf("%s\n", header)
+	for _, line := range strings.SplitAfter(body, "\n") {
+		if line != "" {
+			g.out.Printf("    %s", line)
+		}
+	}
+
+	g.domain, g.provider, g.counts, g.errors = "", "", nil, 0
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
@@ -1,6 +1,10 @@
 package rejectif
 
-import "github.com/StackExchange/dnscontrol/v3/models"
+import (
+	"errors"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
 
 // Auditor stores a list of checks to be executed during Audit().
 type Auditor struct {
@@ -23,7 +27,8 @@ func (aud *Auditor) Add(rtype string, fn checker) {
 }
 
 // Audit performs the audit. For each record it calls each function in
-// the list of checks.
+// the list of checks. The errors are *models.ValidationError, with the
+// ErrCodeRejectedRecord code unless the check returned a coded error.
 func (aud *Auditor) Audit(records models.Records) (errs []error) {
 	// No checks? Exit early.
 	if aud.checksFor == nil {
@@ -34,9 +39,20 @@ func (aud *Auditor) Audit(records models.Records) (errs []error) {
 	for _, rc := range records {
 		for _, f := range aud.checksFor[rc.Type] {
 			e := f(rc)
-			if e != nil {
-				errs = append(errs, e)
+			if e == nil {
+				continue
+			}
+			var ve *models.ValidationError
+			if !errors.As(e, &ve) {
+				ve = &models.ValidationError{Code: models.ErrCodeRejectedRecord, Message: e.Error()}
+			}
+			if ve.Record == "" {
+				ve.Record = models.RecordIdentity(rc)
+			}
+			if ve.Source == "" {
+				ve.Source = rc.Source
 			}
+			errs = append(errs, ve)
 		}
 	}
 
@@ -2,12 +2,32 @@ package rejectif
 
 import (
 	"fmt"
+	"net"
+	"strings"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/miekg/dns"
 )
 
 // Keep these in alphabetical order.
 
+// SrvHasInvalidTarget detects SRV records whose target isn't a valid
+// hostname, such as an IP address or a name with an empty label. A null
+// target is left to SrvHasNullTarget.
+func SrvHasInvalidTarget(rc *models.RecordConfig) error {
+	target := rc.GetTargetField()
+	if target == "." {
+		return nil
+	}
+	if net.ParseIP(strings.TrimSuffix(target, ".")) != nil {
+		return fmt.Errorf("srv target %q is an IP address, not a hostname", target)
+	}
+	if _, ok := dns.IsDomainName(target); !ok || strings.Contains(target, "..") {
+		return fmt.Errorf("srv target %q is not a valid hostname", target)
+	}
+	return nil
+}
+
 // SrvHasNullTarget detects SRV records that has a null target.
 func SrvHasNullTarget(rc *models.RecordConfig) error {
 	if rc.GetTargetField() == "." {
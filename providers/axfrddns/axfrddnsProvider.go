@@ -263,7 +263,7 @@ func (c *axfrddnsProvider) GetZoneRecords(domain string) (models.Records, error)
 	}
 
 	var foundDNSSecRecords *models.RecordConfig
-	foundRecords := models.Records{}
+	var zoneRecords []dns.RR
 	for _, rr := range rawRecords {
 		switch rr.(type) {
 		case *dns.RRSIG,
@@ -286,18 +286,13 @@ func (c *axfrddnsProvider) GetZoneRecords(domain string) (models.Records, error)
 			}
 			continue
 		default:
-			rec, err := models.RRtoRC(rr, domain)
-			if err != nil {
-				return nil, err
-			}
-			foundRecords = append(foundRecords, &rec)
+			zoneRecords = append(zoneRecords, rr)
 		}
 	}
 
-	if len(foundRecords) >= 1 && foundRecords[len(foundRecords)-1].Type == "SOA" {
-		// The SOA is sent two times: as the first and the last record
-		// See section 2.2 of RFC5936
-		foundRecords = foundRecords[:len(foundRecords)-1]
+	foundRecords, err := models.AXFRtoRCs(zoneRecords, domain)
+	if err != nil {
+		return nil, err
 	}
 
 	if foundDNSSecRecords != nil {
@@ -2,7 +2,13 @@
 
 package providers
 
-import "log"
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 // Capability is a bitmasked set of "features" that a provider supports. Only use constants from this package.
 type Capability uint32
@@ -17,6 +23,11 @@ const (
 	// so folks can ask for that.
 	CanAutoDNSSEC Capability = iota
 
+	// CanConcur indicates the provider can be used by several goroutines
+	// at once, so that its domains can be processed in parallel with
+	// --parallel-domains.
+	CanConcur
+
 	// CanGetZones indicates the provider supports the get-zones subcommand.
 	CanGetZones
 
@@ -32,6 +43,15 @@ const (
 	// CanUseCAA indicates the provider can handle CAA records
 	CanUseCAA
 
+	// CanUseCDNSKEY indicates the provider can handle CDNSKEY records
+	CanUseCDNSKEY
+
+	// CanUseCDS indicates the provider can handle CDS records
+	CanUseCDS
+
+	// CanUseDNAME indicates the provider can handle DNAME records
+	CanUseDNAME
+
 	// CanUseDS indicates that the provider can handle DS record types. This
 	// implies CanUseDSForChildren without specifying the latter explicitly.
 	CanUseDS
@@ -40,15 +60,24 @@ const (
 	// only for children records, not at the root of the zone.
 	CanUseDSForChildren
 
+	// CanUseHTTPS indicates the provider can handle HTTPS records
+	CanUseHTTPS
+
 	// CanUseNAPTR indicates the provider can handle NAPTR records
 	CanUseNAPTR
 
+	// CanUseOPENPGPKEY indicates the provider can handle OPENPGPKEY records
+	CanUseOPENPGPKEY
+
 	// CanUsePTR indicates the provider can handle PTR records
 	CanUsePTR
 
 	// CanUseRoute53Alias indicates the provider support the specific R53_ALIAS records that only the Route53 provider supports
 	CanUseRoute53Alias
 
+	// CanUseSMIMEA indicates the provider can handle SMIMEA records
+	CanUseSMIMEA
+
 	// CanUseSOA indicates the provider supports full management of a zone's SOA record
 	CanUseSOA
 
@@ -58,6 +87,9 @@ const (
 	// CanUseSSHFP indicates the provider can handle SSHFP records
 	CanUseSSHFP
 
+	// CanUseSVCB indicates the provider can handle SVCB records
+	CanUseSVCB
+
 	// CanUseTLSA indicates the provider can handle TLSA records
 	CanUseTLSA
 
@@ -79,6 +111,11 @@ const (
 
 var providerCapabilities = map[string]map[Capability]bool{}
 
+// overridableCapabilities lists, per provider type, the capabilities
+// the provider's code implements even though they are not advertised
+// by default. Users may enable these with a capability override.
+var overridableCapabilities = map[string]map[Capability]bool{}
+
 // ProviderHasCapability returns true if provider has capability.
 func ProviderHasCapability(pType string, cap Capability) bool {
 	if providerCapabilities[pType] == nil {
@@ -87,6 +124,103 @@ func ProviderHasCapability(pType string, cap Capability) bool {
 	return providerCapabilities[pType][cap]
 }
 
+// ProviderHasCapabilityOverridden is like ProviderHasCapability but
+// applies the given overrides (in order, the last one wins) on top of
+// the provider's advertised capabilities. The overrides should have
+// been checked with ValidateCapabilityOverrides first.
+func ProviderHasCapabilityOverridden(pType string, cap Capability, overrides ...map[Capability]bool) bool {
+	has := ProviderHasCapability(pType, cap)
+	for _, o := range overrides {
+		if v, ok := o[cap]; ok {
+			has = v
+		}
+	}
+	return has
+}
+
+// OverridableCapabilities lists capabilities that a provider implements
+// but only enables when the user asks for them, usually because they
+// depend on the features of the user's account. Pass it to
+// RegisterDomainServiceProviderType along with the DocumentationNotes.
+type OverridableCapabilities []Capability
+
+// ProviderCanOverrideCapability returns true if the provider permits
+// cap to be enabled with a capability override.
+func ProviderCanOverrideCapability(pType string, cap Capability) bool {
+	return overridableCapabilities[pType][cap]
+}
+
+// ParseCapabilityOverrides parses a list of capability overrides such
+// as "CanAutoDNSSEC=true,CanUseAlias=false". A name without a value
+// (i.e. "CanAutoDNSSEC") is the same as "CanAutoDNSSEC=true".
+func ParseCapabilityOverrides(s string) (map[Capability]bool, error) {
+	overrides := map[Capability]bool{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, hasValue := strings.Cut(item, "=")
+		enabled := true
+		if hasValue {
+			var err error
+			if enabled, err = strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("capability override %q has an invalid value: %w", item, err)
+			}
+		}
+		cap, ok := capabilityByName(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("capability override %q names an unknown capability", item)
+		}
+		overrides[cap] = enabled
+	}
+	return overrides, nil
+}
+
+// FormatCapabilityOverrides is the inverse of ParseCapabilityOverrides.
+func FormatCapabilityOverrides(overrides map[Capability]bool) string {
+	var items []string
+	for cap, enabled := range overrides {
+		items = append(items, fmt.Sprintf("%s=%t", cap, enabled))
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// ValidateCapabilityOverrides returns an error if the overrides enable
+// a capability that the provider does not advertise and cannot be
+// overridden to support. Disabling a capability is always permitted.
+func ValidateCapabilityOverrides(pType string, overrides map[Capability]bool) error {
+	for cap, enabled := range overrides {
+		if !enabled || ProviderHasCapability(pType, cap) {
+			continue
+		}
+		if !ProviderCanOverrideCapability(pType, cap) {
+			return fmt.Errorf("provider type %s does not support enabling %s", pType, cap)
+		}
+	}
+	return nil
+}
+
+// Capabilities returns all the capabilities, in order.
+func Capabilities() []Capability {
+	caps := make([]Capability, len(_Capability_index)-1)
+	for i := range caps {
+		caps[i] = Capability(i)
+	}
+	return caps
+}
+
+// capabilityByName returns the Capability with the given name.
+func capabilityByName(name string) (Capability, bool) {
+	for _, cap := range Capabilities() {
+		if cap.String() == name {
+			return cap, true
+		}
+	}
+	return 0, false
+}
+
 // DocumentationNote is a way for providers to give more detail about what features they support.
 type DocumentationNote struct {
 	HasFeature    bool
@@ -112,6 +246,13 @@ func unwrapProviderCapabilities(pName string, meta []ProviderMetadata) {
 		switch x := pm.(type) {
 		case Capability:
 			providerCapabilities[pName][x] = true
+		case OverridableCapabilities:
+			if overridableCapabilities[pName] == nil {
+				overridableCapabilities[pName] = map[Capability]bool{}
+			}
+			for _, cap := range x {
+				overridableCapabilities[pName][cap] = true
+			}
 		case DocumentationNotes:
 			if Notes[pName] == nil {
 				Notes[pName] = DocumentationNotes{}
@@ -9,29 +9,37 @@ func _() {
 	// Re-run the stringer command to generate them again.
 	var x [1]struct{}
 	_ = x[CanAutoDNSSEC-0]
-	_ = x[CanGetZones-1]
-	_ = x[CanUseAKAMAICDN-2]
-	_ = x[CanUseAlias-3]
-	_ = x[CanUseAzureAlias-4]
-	_ = x[CanUseCAA-5]
-	_ = x[CanUseDS-6]
-	_ = x[CanUseDSForChildren-7]
-	_ = x[CanUseNAPTR-8]
-	_ = x[CanUsePTR-9]
-	_ = x[CanUseRoute53Alias-10]
-	_ = x[CanUseSOA-11]
-	_ = x[CanUseSRV-12]
-	_ = x[CanUseSSHFP-13]
-	_ = x[CanUseTLSA-14]
-	_ = x[CantUseNOPURGE-15]
-	_ = x[DocCreateDomains-16]
-	_ = x[DocDualHost-17]
-	_ = x[DocOfficiallySupported-18]
+	_ = x[CanConcur-1]
+	_ = x[CanGetZones-2]
+	_ = x[CanUseAKAMAICDN-3]
+	_ = x[CanUseAlias-4]
+	_ = x[CanUseAzureAlias-5]
+	_ = x[CanUseCAA-6]
+	_ = x[CanUseCDNSKEY-7]
+	_ = x[CanUseCDS-8]
+	_ = x[CanUseDNAME-9]
+	_ = x[CanUseDS-10]
+	_ = x[CanUseDSForChildren-11]
+	_ = x[CanUseHTTPS-12]
+	_ = x[CanUseNAPTR-13]
+	_ = x[CanUseOPENPGPKEY-14]
+	_ = x[CanUsePTR-15]
+	_ = x[CanUseRoute53Alias-16]
+	_ = x[CanUseSMIMEA-17]
+	_ = x[CanUseSOA-18]
+	_ = x[CanUseSRV-19]
+	_ = x[CanUseSSHFP-20]
+	_ = x[CanUseSVCB-21]
+	_ = x[CanUseTLSA-22]
+	_ = x[CantUseNOPURGE-23]
+	_ = x[DocCreateDomains-24]
+	_ = x[DocDualHost-25]
+	_ = x[DocOfficiallySupported-26]
 }
 
-const _Capability_name = "CanAutoDNSSECCanGetZonesCanUseAKAMAICDNCanUseAliasCanUseAzureAliasCanUseCAACanUseDSCanUseDSForChildrenCanUseNAPTRCanUsePTRCanUseRoute53AliasCanUseSOACanUseSRVCanUseSSHFPCanUseTLSACantUseNOPURGEDocCreateDomainsDocDualHostDocOfficiallySupported"
+const _Capability_name = "CanAutoDNSSECCanConcurCanGetZonesCanUseAKAMAICDNCanUseAliasCanUseAzureAliasCanUseCAACanUseCDNSKEYCanUseCDSCanUseDNAMECanUseDSCanUseDSForChildrenCanUseHTTPSCanUseNAPTRCanUseOPENPGPKEYCanUsePTRCanUseRoute53AliasCanUseSMIMEACanUseSOACanUseSRVCanUseSSHFPCanUseSVCBCanUseTLSACantUseNOPURGEDocCreateDomainsDocDualHostDocOfficiallySupported"
 
-var _Capability_index = [...]uint8{0, 13, 24, 39, 50, 66, 75, 83, 102, 113, 122, 140, 149, 158, 169, 179, 193, 209, 220, 242}
+var _Capability_index = [...]uint16{0, 13, 22, 33, 48, 59, 75, 84, 97, 106, 117, 125, 144, 155, 166, 182, 191, 209, 221, 230, 239, 250, 260, 270, 284, 300, 311, 333}
 
 func (i Capability) String() string {
 	if i >= Capability(len(_Capability_index)-1) {
@@ -0,0 +1,102 @@
+package gcore
+
+// G-Core has no ALIAS record type, so DNSControl flattens ALIAS
+// records into the A and AAAA records of their target each time
+// corrections are generated. This keeps the apex in sync with the
+// target as long as DNSControl is run regularly. If the target's A or
+// AAAA records are in the same domain's config, those are used instead,
+// so that the ALIAS matches what is being pushed rather than what is
+// currently published.
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// aliasResolver looks up the addresses of ALIAS targets.
+// It is satisfied by *net.Resolver.
+type aliasResolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// flattenAliases replaces each ALIAS record in dc with A and AAAA
+// records containing all the current addresses of its target.
+func (c *gcoreProvider) flattenAliases(dc *models.DomainConfig) error {
+	var recs models.Records
+	for _, rec := range dc.Records {
+		if rec.Type != "ALIAS" {
+			recs = append(recs, rec)
+			continue
+		}
+		if dc.Records.HasRecordTypeName("A", rec.GetLabel()) || dc.Records.HasRecordTypeName("AAAA", rec.GetLabel()) {
+			return fmt.Errorf("ALIAS %s conflicts with the A or AAAA records at the same label", rec.GetLabelFQDN())
+		}
+		flattened, err := c.resolveAlias(rec, dc)
+		if err != nil {
+			return err
+		}
+		recs = append(recs, flattened...)
+	}
+	dc.Records = recs
+	return nil
+}
+
+// resolveAlias returns the A and AAAA records that ALIAS record rec in
+// dc should be flattened into.
+func (c *gcoreProvider) resolveAlias(rec *models.RecordConfig, dc *models.DomainConfig) (models.Records, error) {
+	target := rec.GetTargetField()
+	ips := desiredAddrs(dc.Records, target)
+	if len(ips) == 0 {
+		addrs, err := c.resolver.LookupIPAddr(c.ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("resolving ALIAS %s target %s: %w", rec.GetLabelFQDN(), target, err)
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("ALIAS %s target %s has no A or AAAA records", rec.GetLabelFQDN(), target)
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP.String())
+		}
+	}
+
+	// Sort the addresses so that the order of the resolver's answers
+	// doesn't matter.
+	sort.Strings(ips)
+
+	var recs models.Records
+	for _, ip := range ips {
+		rtype := "AAAA"
+		if net.ParseIP(ip).To4() != nil {
+			rtype = "A"
+		}
+		flat := &models.RecordConfig{
+			Type:     rtype,
+			TTL:      rec.TTL,
+			Metadata: rec.Metadata,
+		}
+		flat.SetLabel(rec.GetLabel(), dc.Name)
+		if err := flat.SetTarget(ip); err != nil {
+			return nil, err
+		}
+		recs = append(recs, flat)
+	}
+	return recs, nil
+}
+
+// desiredAddrs returns the addresses of the A and AAAA records at the
+// FQDN target in records, or nil if there are none.
+func desiredAddrs(records models.Records, target string) []string {
+	target = strings.ToLower(strings.TrimSuffix(target, "."))
+	var ips []string
+	for _, rec := range records {
+		if (rec.Type == "A" || rec.Type == "AAAA") && strings.ToLower(rec.GetLabelFQDN()) == target {
+			ips = append(ips, rec.GetTargetField())
+		}
+	}
+	return ips
+}
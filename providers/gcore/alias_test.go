@@ -0,0 +1,162 @@
+package gcore
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// fakeResolver answers lookups from a fixed table.
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r[host]
+	if !ok {
+		return nil, fmt.Errorf("no such host %s", host)
+	}
+	var addrs []net.IPAddr
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+func makeRC(label, typ, target string) *models.RecordConfig {
+	rc := &models.RecordConfig{Type: typ, TTL: 300}
+	rc.SetLabel(label, "example.com")
+	rc.SetTarget(target)
+	return rc
+}
+
+func TestFlattenAliases(t *testing.T) {
+	c := &gcoreProvider{
+		provider: offlineClient(),
+		ctx:      context.Background(),
+		resolver: fakeResolver{
+			"target.example.net.": {"192.0.2.3", "192.0.2.1", "2001:db8::1", "192.0.2.2"},
+		},
+	}
+	dc := &models.DomainConfig{
+		Name: "example.com",
+		Records: models.Records{
+			makeRC("@", "ALIAS", "target.example.net."),
+			makeRC("www", "A", "198.51.100.1"),
+		},
+	}
+	if err := c.flattenAliases(dc); err != nil {
+		t.Fatal(err)
+	}
+
+	rrset := recordsToNative(dc.Records, models.RecordKey{NameFQDN: "example.com", Type: "A"})
+	if rrset == nil {
+		t.Fatal("no A RRset at the apex")
+	}
+	var got []string
+	for _, rr := range rrset.Records {
+		got = append(got, fmt.Sprint(rr.Content...))
+	}
+	if exp := "192.0.2.1,192.0.2.2,192.0.2.3"; strings.Join(got, ",") != exp {
+		t.Errorf("apex A RRset is %v, expected %s", got, exp)
+	}
+	if rrset.TTL != 300 {
+		t.Errorf("apex A RRset has TTL %d, expected 300", rrset.TTL)
+	}
+	if rrset := recordsToNative(dc.Records, models.RecordKey{NameFQDN: "example.com", Type: "AAAA"}); rrset == nil || len(rrset.Records) != 1 {
+		t.Errorf("apex AAAA RRset is %v, expected one record", rrset)
+	}
+	if dc.Records.HasRecordTypeName("ALIAS", "@") {
+		t.Error("ALIAS record was not removed")
+	}
+}
+
+func TestFlattenAliases_TargetInConfig(t *testing.T) {
+	// The resolver has the addresses currently published for www, but
+	// the config is about to change them.
+	c := &gcoreProvider{
+		provider: offlineClient(),
+		ctx:      context.Background(),
+		resolver: fakeResolver{"www.example.com.": {"192.0.2.99"}},
+	}
+	dc := &models.DomainConfig{
+		Name: "example.com",
+		Records: models.Records{
+			makeRC("@", "ALIAS", "www.example.com."),
+			makeRC("www", "A", "192.0.2.10"),
+			makeRC("www", "AAAA", "2001:db8::10"),
+		},
+	}
+	if err := c.flattenAliases(dc); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, rec := range dc.Records {
+		if rec.GetLabel() == "@" {
+			got = append(got, rec.Type+" "+rec.GetTargetField())
+		}
+	}
+	if exp := "A 192.0.2.10,AAAA 2001:db8::10"; strings.Join(got, ",") != exp {
+		t.Errorf("apex records are %v, expected %s", got, exp)
+	}
+}
+
+func TestFlattenAliases_TargetChanged(t *testing.T) {
+	c := &gcoreProvider{
+		provider: offlineClient(),
+		ctx:      context.Background(),
+		resolver: fakeResolver{
+			"target.example.net.": {"192.0.2.1", "192.0.2.2", "192.0.2.3"},
+		},
+	}
+	dc := &models.DomainConfig{
+		Name: "example.com",
+		Records: models.Records{
+			makeRC("@", "ALIAS", "target.example.net."),
+		},
+	}
+	if err := c.flattenAliases(dc); err != nil {
+		t.Fatal(err)
+	}
+
+	// The target gained an address since the last push.
+	existing := models.Records{
+		makeRC("@", "A", "192.0.2.1"),
+		makeRC("@", "A", "192.0.2.2"),
+	}
+	corrections, err := c.GenerateDomainCorrections(dc, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 {
+		t.Fatalf("expected 1 correction, got %d", len(corrections))
+	}
+	if !strings.Contains(corrections[0].Msg, "192.0.2.3") {
+		t.Errorf("correction %q does not add 192.0.2.3", corrections[0].Msg)
+	}
+}
+
+func TestFlattenAliases_Errors(t *testing.T) {
+	c := &gcoreProvider{
+		provider: offlineClient(),
+		ctx:      context.Background(),
+		resolver: fakeResolver{"target.example.net.": {"192.0.2.1"}},
+	}
+	for _, tst := range []struct {
+		name    string
+		records models.Records
+	}{
+		{"unresolvable", models.Records{makeRC("@", "ALIAS", "missing.example.net.")}},
+		{"conflict", models.Records{makeRC("@", "ALIAS", "target.example.net."), makeRC("@", "A", "192.0.2.9")}},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			dc := &models.DomainConfig{Name: "example.com", Records: tst.records}
+			if err := c.flattenAliases(dc); err == nil {
+				t.Error("expected error, got none")
+			}
+		})
+	}
+}
@@ -0,0 +1,378 @@
+package gcore
+
+// Calls to G-Core API endpoints that the SDK does not (yet) expose.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+
+	dnssdk "github.com/G-Core/gcore-dns-sdk-go"
+)
+
+// zoneInfo is the subset of G-Core's zone description that is not
+// included in dnssdk.Zone.
+type zoneInfo struct {
+	Name          string `json:"name"`
+	DNSSECEnabled bool   `json:"dnssec_enabled"`
+	Enabled       *bool  `json:"enabled"` // nil if not reported, which means enabled
+	Contact       string `json:"contact"` // the SOA RNAME, as an email address
+}
+
+// zoneInfo gets the zone's settings.
+// https://apidocs.gcore.com/dns#tag/zones/operation/Zone
+func (c *gcoreProvider) zoneInfo(zone string) (zoneInfo, error) {
+	var result zoneInfo
+	uri := path.Join("/v2/zones", strings.Trim(zone, "."))
+	if err := c.apiRequest(http.MethodGet, uri, nil, &result); err != nil {
+		return zoneInfo{}, fmt.Errorf("get zone %s: %w", zone, err)
+	}
+	return result, nil
+}
+
+// dnssecInfo gets the zone's DNSSEC settings.
+// https://apidocs.gcore.com/dns#tag/dnssec/operation/GetDnssec
+func (c *gcoreProvider) dnssecInfo(zone string) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	uri := path.Join("/v2/zones", strings.Trim(zone, "."), "dnssec")
+	if err := c.apiRequest(http.MethodGet, uri, nil, &result); err != nil {
+		return nil, fmt.Errorf("get dnssec %s: %w", zone, err)
+	}
+	return result, nil
+}
+
+// exportZone gets the zone's records in zonefile form.
+// https://apidocs.gcore.com/dns#tag/zones/operation/ExportZone
+func (c *gcoreProvider) exportZone(zone string) (string, error) {
+	var result struct {
+		RawZone string `json:"raw_zone"`
+	}
+	uri := path.Join("/v2/zones", strings.Trim(zone, "."), "export")
+	if err := c.apiRequest(http.MethodGet, uri, nil, &result); err != nil {
+		return "", fmt.Errorf("export zone %s: %w", zone, err)
+	}
+	return result.RawZone, nil
+}
+
+// setDNSSEC enables or disables DNSSEC for the zone.
+// https://apidocs.gcore.com/dns#tag/dnssec/operation/PatchDnssec
+func (c *gcoreProvider) setDNSSEC(zone string, enabled bool) error {
+	uri := path.Join("/v2/zones", strings.Trim(zone, "."), "dnssec")
+	body := map[string]bool{"enabled": enabled}
+	if err := c.apiRequest(http.MethodPatch, uri, body, nil); err != nil {
+		return fmt.Errorf("set dnssec %s: %w", zone, err)
+	}
+	return nil
+}
+
+// setZoneEnabled enables or disables (suspends) the zone. A disabled
+// zone is not served, but can still be read and changed.
+// https://apidocs.gcore.com/dns#tag/zones/operation/EnableZone
+// https://apidocs.gcore.com/dns#tag/zones/operation/DisableZone
+func (c *gcoreProvider) setZoneEnabled(zone string, enabled bool) error {
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+	uri := path.Join("/v2/zones", strings.Trim(zone, "."), action)
+	if err := c.apiRequest(http.MethodPatch, uri, nil, nil); err != nil {
+		return fmt.Errorf("%s zone %s: %w", action, zone, err)
+	}
+	return nil
+}
+
+// setContact sets the zone's contact email address, which is the RNAME
+// of its SOA record.
+// https://apidocs.gcore.com/dns#tag/zones/operation/PatchZone
+func (c *gcoreProvider) setContact(zone, contact string) error {
+	uri := path.Join("/v2/zones", strings.Trim(zone, "."))
+	body := map[string]string{"contact": contact}
+	if err := c.apiRequest(http.MethodPatch, uri, body, nil); err != nil {
+		return fmt.Errorf("set contact %s: %w", zone, err)
+	}
+	return nil
+}
+
+// deleteName deletes the RRsets of the given types at name, which must
+// be all of the RRsets at name. It uses a single request to delete the
+// whole name if the API supports it, and deletes each type otherwise.
+// https://apidocs.gcore.com/dns#tag/rrsets/operation/DeleteRRSetsByName
+func (c *gcoreProvider) deleteName(zone, name string, types []string) error {
+	if atomic.LoadInt32(&c.noBulkDelete) == 0 {
+		uri := path.Join("/v2/zones", strings.Trim(zone, "."), strings.Trim(name, "."))
+		err := c.apiRequest(http.MethodDelete, uri, nil, nil)
+		if err == nil {
+			return nil
+		}
+		var apiErr dnssdk.APIError
+		if !errors.As(err, &apiErr) || (apiErr.StatusCode != http.StatusNotFound && apiErr.StatusCode != http.StatusMethodNotAllowed) {
+			return fmt.Errorf("delete %s: %w", name, err)
+		}
+		atomic.StoreInt32(&c.noBulkDelete, 1)
+	}
+
+	for _, typ := range types {
+		if err := c.provider.DeleteRRSet(c.ctx, zone, name, typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rrsetBody is an RRset as G-Core's API sends and receives it. It is
+// dnssdk.RRSet, and whether the whole RRset is enabled, which the SDK
+// doesn't have, and any other fields (see raw.go).
+type rrsetBody struct {
+	dnssdk.RRSet
+	Enabled *bool                      `json:"enabled,omitempty"` // nil if not reported or unchanged, which means enabled
+	Raw     map[string]json.RawMessage `json:"-"`                 // the fields that aren't in rrsetFields
+}
+
+// rrsetBodyFields is rrsetBody without its JSON methods.
+type rrsetBodyFields rrsetBody
+
+// MarshalJSON adds the fields of Raw to the RRset.
+func (b rrsetBody) MarshalJSON() ([]byte, error) {
+	bs, err := json.Marshal(rrsetBodyFields(b))
+	if err != nil || len(b.Raw) == 0 {
+		return bs, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(bs, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range b.Raw {
+		if !rrsetFields[k] {
+			fields[k] = v
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON keeps the fields that aren't in rrsetFields in Raw.
+func (b *rrsetBody) UnmarshalJSON(bs []byte) error {
+	if err := json.Unmarshal(bs, (*rrsetBodyFields)(b)); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(bs, &fields); err != nil {
+		return err
+	}
+	b.Raw = nil
+	for k, v := range fields {
+		if !rrsetFields[k] {
+			if b.Raw == nil {
+				b.Raw = map[string]json.RawMessage{}
+			}
+			b.Raw[k] = v
+		}
+	}
+	return nil
+}
+
+// getRRSet gets the RRset.
+// https://apidocs.gcore.com/dns#tag/rrsets/operation/RRSet
+func (c *gcoreProvider) getRRSet(zone, name, typ string) (rrsetBody, error) {
+	var result rrsetBody
+	if err := c.apiRequest(http.MethodGet, rrsetURI(zone, name, typ), nil, &result); err != nil {
+		return rrsetBody{}, fmt.Errorf("get %s %s: %w", name, typ, err)
+	}
+	return result, nil
+}
+
+// nameRRSet is an RRset in an updateName request.
+type nameRRSet struct {
+	Type string `json:"type"`
+	rrsetBody
+}
+
+// MarshalJSON adds the type to the RRset. Without it, the method of
+// rrsetBody would leave the type out.
+func (n nameRRSet) MarshalJSON() ([]byte, error) {
+	bs, err := n.rrsetBody.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(bs, &fields); err != nil {
+		return nil, err
+	}
+	if fields["type"], err = json.Marshal(n.Type); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON reads the type of the RRset, which the method of
+// rrsetBody would leave out.
+func (n *nameRRSet) UnmarshalJSON(bs []byte) error {
+	var typ struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(bs, &typ); err != nil {
+		return err
+	}
+	n.Type = typ.Type
+	return n.rrsetBody.UnmarshalJSON(bs)
+}
+
+// updateName creates or replaces the given RRsets at name, leaving any
+// other RRsets at name as they are. It uses a single request if the API
+// supports it, so that name never has a mix of old and new RRsets, and
+// otherwise creates (if the type is in create) or updates each RRset.
+func (c *gcoreProvider) updateName(zone, name string, rrsets []nameRRSet, create map[string]bool) error {
+	if atomic.LoadInt32(&c.noBulkUpdate) == 0 {
+		uri := path.Join("/v2/zones", strings.Trim(zone, "."), strings.Trim(name, "."))
+		err := c.apiRequest(http.MethodPut, uri, map[string][]nameRRSet{"rrsets": rrsets}, nil)
+		if err == nil {
+			return nil
+		}
+		var apiErr dnssdk.APIError
+		if !errors.As(err, &apiErr) || (apiErr.StatusCode != http.StatusNotFound && apiErr.StatusCode != http.StatusMethodNotAllowed) {
+			return fmt.Errorf("update %s: %w", name, err)
+		}
+		atomic.StoreInt32(&c.noBulkUpdate, 1)
+	}
+
+	for _, rrset := range rrsets {
+		if err := c.upsertRRSet(zone, name, rrset.Type, rrset.rrsetBody, create[rrset.Type]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upsertRRSet creates the RRset if create is set, and updates it
+// otherwise. If the RRset turns out to already exist (or not exist),
+// such as when the zone changed after it was read, it is updated (or
+// created) instead, so that the correction still succeeds.
+func (c *gcoreProvider) upsertRRSet(zone, name, typ string, rrset rrsetBody, create bool) error {
+	var err error
+	if create {
+		err = c.apiRequest(http.MethodPost, rrsetURI(zone, name, typ), rrset, nil)
+		if isAPIStatus(err, http.StatusConflict) {
+			err = c.apiRequest(http.MethodPut, rrsetURI(zone, name, typ), rrset, nil)
+		}
+	} else {
+		err = c.apiRequest(http.MethodPut, rrsetURI(zone, name, typ), rrset, nil)
+		if isAPIStatus(err, http.StatusNotFound) {
+			err = c.apiRequest(http.MethodPost, rrsetURI(zone, name, typ), rrset, nil)
+		}
+	}
+	return rejectedViews(err, name, typ, rrset.RRSet)
+}
+
+// isAPIStatus reports whether err is an API error with the HTTP status.
+func isAPIStatus(err error, status int) bool {
+	var apiErr dnssdk.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// rrsetURI is the URI of an RRset, as used by the SDK.
+func rrsetURI(zone, name, typ string) string {
+	return path.Join("/v2/zones", strings.Trim(zone, "."), strings.Trim(name, "."), typ)
+}
+
+// describeDeleteName describes the requests that deleteName sends.
+func (c *gcoreProvider) describeDeleteName(zone, name string, types []string) []models.HTTPRequest {
+	if atomic.LoadInt32(&c.noBulkDelete) == 0 {
+		uri := path.Join("/v2/zones", strings.Trim(zone, "."), strings.Trim(name, "."))
+		return []models.HTTPRequest{c.describeRequest(http.MethodDelete, uri, nil)}
+	}
+	var reqs []models.HTTPRequest
+	for _, typ := range types {
+		reqs = append(reqs, c.describeRequest(http.MethodDelete, rrsetURI(zone, name, typ), nil))
+	}
+	return reqs
+}
+
+// describeUpdateName describes the requests that updateName sends.
+func (c *gcoreProvider) describeUpdateName(zone, name string, rrsets []nameRRSet, create map[string]bool) []models.HTTPRequest {
+	if atomic.LoadInt32(&c.noBulkUpdate) == 0 {
+		uri := path.Join("/v2/zones", strings.Trim(zone, "."), strings.Trim(name, "."))
+		return []models.HTTPRequest{c.describeRequest(http.MethodPut, uri, map[string][]nameRRSet{"rrsets": rrsets})}
+	}
+	var reqs []models.HTTPRequest
+	for _, rrset := range rrsets {
+		method := http.MethodPut
+		if create[rrset.Type] {
+			method = http.MethodPost
+		}
+		reqs = append(reqs, c.describeRequest(method, rrsetURI(zone, name, rrset.Type), rrset.rrsetBody))
+	}
+	return reqs
+}
+
+// describeRequest describes the request that apiRequest (or the SDK)
+// sends for method, uri and body, with the API key left as
+// $GCORE_API_KEY.
+func (c *gcoreProvider) describeRequest(method, uri string, body interface{}) models.HTTPRequest {
+	req := models.HTTPRequest{
+		Method: method,
+		URL:    c.endpoint(uri).String(),
+		Header: map[string]string{
+			"Content-Type":  "application/json",
+			"Authorization": "APIKey $GCORE_API_KEY",
+		},
+	}
+	if body != nil {
+		req.Body, _ = json.Marshal(body)
+	}
+	return req
+}
+
+// endpoint returns the URL of uri.
+func (c *gcoreProvider) endpoint(uri string) *url.URL {
+	u := *c.provider.BaseURL
+	u.Path = path.Join(u.Path, uri)
+	return &u
+}
+
+// apiRequest sends an authenticated request to the G-Core API. It is
+// the equivalent of the SDK's private request function, and shares
+// the SDK client's base URL and HTTP client.
+func (c *gcoreProvider) apiRequest(method, uri string, body interface{}, dest interface{}) error {
+	var bs []byte
+	if body != nil {
+		var err error
+		if bs, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("encode body: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, method, c.endpoint(uri).String(), bytes.NewReader(bs))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "APIKey "+c.apiKey)
+
+	resp, err := c.provider.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		all, _ := io.ReadAll(resp.Body)
+		e := dnssdk.APIError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(all, &e); err != nil {
+			e.Message = string(all)
+		}
+		return describeAPIError(method, uri, e)
+	}
+
+	if dest == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
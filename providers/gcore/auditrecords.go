@@ -1,6 +1,9 @@
 package gcore
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/StackExchange/dnscontrol/v3/models"
 	"github.com/StackExchange/dnscontrol/v3/pkg/rejectif"
 )
@@ -10,6 +13,70 @@ import (
 // supported, an empty list is returned.
 func AuditRecords(records []*models.RecordConfig) []error {
 	a := rejectif.Auditor{}
+	a.Add("MX", rejectif.MxNull)
+	a.Add("SRV", rejectif.SrvHasInvalidTarget)
 	a.Add("SRV", rejectif.SrvHasNullTarget)
-	return a.Audit(records)
+	a.Add("TXT", checkDMARC)
+	a.Add("TXT", checkSPF)
+	for _, typ := range []string{"A", "AAAA", "CAA", "CDNSKEY", "CDS", "CNAME", "HTTPS", "MX", "NS", "OPENPGPKEY", "SMIMEA", "SRV", "SVCB", "TXT"} {
+		a.Add(typ, checkRouting)
+		a.Add(typ, checkRRSetEnabled)
+		a.Add(typ, checkRaw)
+		a.Add(typ, checkStaged)
+	}
+	errs := append(a.Audit(records), checkAnswerCount(records)...)
+	return append(errs, checkPolicyCount(records)...)
+}
+
+// maxAnswers is the most answers G-Core accepts in an RRset. An RRset
+// may set another limit with metaMaxAnswers, such as if the account's
+// limit is different.
+const maxAnswers = 100
+
+// metaMaxAnswers is the record metadata that overrides maxAnswers for
+// its RRset.
+const metaMaxAnswers = "gcore_max_answers"
+
+// checkAnswerCount returns an error for each RRset with more answers
+// than G-Core accepts, which would otherwise only fail at push.
+func checkAnswerCount(records []*models.RecordConfig) (errs []error) {
+	var keys []models.RecordKey
+	rrsets := map[models.RecordKey][]*models.RecordConfig{}
+	for _, rc := range records {
+		key := rc.Key()
+		if _, ok := rrsets[key]; !ok {
+			keys = append(keys, key)
+		}
+		rrsets[key] = append(rrsets[key], rc)
+	}
+	for _, key := range keys {
+		rrset := rrsets[key]
+		limit := maxAnswers
+		for _, rc := range rrset {
+			v, ok := rc.Metadata[metaMaxAnswers]
+			if !ok {
+				continue
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				errs = append(errs, rejected(rc, fmt.Sprintf("%s must be a whole number of at least 1, got %q", metaMaxAnswers, v)))
+				continue
+			}
+			limit = n
+		}
+		if len(rrset) > limit {
+			errs = append(errs, rejected(rrset[0], fmt.Sprintf("%s %s has %d answers, more than the %d G-Core accepts", key.NameFQDN, key.Type, len(rrset), limit)))
+		}
+	}
+	return errs
+}
+
+// rejected returns a validation error about rc.
+func rejected(rc *models.RecordConfig, msg string) error {
+	return &models.ValidationError{
+		Code:    models.ErrCodeRejectedRecord,
+		Message: msg,
+		Record:  models.RecordIdentity(rc),
+		Source:  rc.Source,
+	}
 }
@@ -0,0 +1,118 @@
+package gcore
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestAuditSRV(t *testing.T) {
+	for _, tc := range []struct {
+		port   uint16
+		target string
+		ok     bool
+	}{
+		{5060, "sip.example.com.", true},
+		{65535, "sip.example.com.", true},
+		{0, "sip.example.com.", true},
+		{5060, ".", false},
+		{5060, "192.0.2.1.", false},
+		{5060, "sip..example.com.", false},
+	} {
+		rc := &models.RecordConfig{Type: "SRV"}
+		rc.SetLabel("_sip._tcp", "example.com")
+		if err := rc.SetTargetSRV(10, 60, tc.port, tc.target); err != nil {
+			t.Fatal(err)
+		}
+		errs := AuditRecords(models.Records{rc})
+		if tc.ok && len(errs) != 0 {
+			t.Errorf("%d %s: expected no errors, got %v", tc.port, tc.target, errs)
+		} else if !tc.ok && len(errs) == 0 {
+			t.Errorf("%d %s: expected an error, got none", tc.port, tc.target)
+		}
+	}
+}
+
+func TestAuditMX(t *testing.T) {
+	for _, tc := range []struct {
+		pref   uint16
+		target string
+		ok     bool
+	}{
+		{10, "mx.example.com.", true},
+		{0, ".", false}, // null MX (RFC 7505)
+	} {
+		rc := &models.RecordConfig{Type: "MX"}
+		rc.SetLabel("@", "example.com")
+		if err := rc.SetTargetMX(tc.pref, tc.target); err != nil {
+			t.Fatal(err)
+		}
+		errs := AuditRecords(models.Records{rc})
+		if tc.ok && len(errs) != 0 {
+			t.Errorf("%d %s: expected no errors, got %v", tc.pref, tc.target, errs)
+		} else if !tc.ok && len(errs) == 0 {
+			t.Errorf("%d %s: expected an error, got none", tc.pref, tc.target)
+		}
+	}
+}
+
+func TestAuditErrorCode(t *testing.T) {
+	rc := &models.RecordConfig{Type: "SRV"}
+	rc.SetLabel("_sip._tcp", "example.com")
+	if err := rc.SetTargetSRV(10, 60, 5060, "192.0.2.1."); err != nil {
+		t.Fatal(err)
+	}
+	errs := AuditRecords(models.Records{rc})
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	var ve *models.ValidationError
+	if !errors.As(errs[0], &ve) {
+		t.Fatalf("expected a *models.ValidationError, got %#v", errs[0])
+	}
+	if ve.Code != models.ErrCodeRejectedRecord || ve.Record != "_sip._tcp.example.com SRV" {
+		t.Errorf("got code %q and record %q", ve.Code, ve.Record)
+	}
+}
+
+func TestAuditAnswerCount(t *testing.T) {
+	rrset := func(n int, meta map[string]string) models.Records {
+		var records models.Records
+		for i := 0; i < n; i++ {
+			rc := makeRC("pool", "A", fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+			rc.Metadata = meta
+			records = append(records, rc)
+		}
+		return records
+	}
+	for _, tc := range []struct {
+		n    int
+		meta map[string]string
+		err  string
+	}{
+		{maxAnswers, nil, ""},
+		{maxAnswers + 1, nil, fmt.Sprintf("pool.example.com A has %d answers, more than the %d G-Core accepts", maxAnswers+1, maxAnswers)},
+		{5, map[string]string{metaMaxAnswers: "5"}, ""},
+		{6, map[string]string{metaMaxAnswers: "5"}, "pool.example.com A has 6 answers, more than the 5 G-Core accepts"},
+		{maxAnswers + 1, map[string]string{metaMaxAnswers: "200"}, ""},
+		{1, map[string]string{metaMaxAnswers: "many"}, `gcore_max_answers must be a whole number of at least 1, got "many"`},
+	} {
+		errs := AuditRecords(rrset(tc.n, tc.meta))
+		if tc.err == "" {
+			if len(errs) != 0 {
+				t.Errorf("%d answers, %v: expected no errors, got %v", tc.n, tc.meta, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || errs[0].Error() != tc.err {
+			t.Errorf("%d answers, %v: got %v, expected %q", tc.n, tc.meta, errs, tc.err)
+			continue
+		}
+		var ve *models.ValidationError
+		if !errors.As(errs[0], &ve) || ve.Record != "pool.example.com A" {
+			t.Errorf("%d answers, %v: got %#v", tc.n, tc.meta, errs[0])
+		}
+	}
+}
@@ -5,6 +5,7 @@ package gcore
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	dnssdk "github.com/G-Core/gcore-dns-sdk-go"
 	"github.com/StackExchange/dnscontrol/v3/models"
@@ -38,27 +39,106 @@ func nativeToRecords(n dnssdk.RRSet, zoneName string, recName string, recType st
 				return nil, fmt.Errorf("unparsable record received from G-Core: %w", err)
 			}
 
-		default: //  "A", "AAAA", "CAA", "NS", "CNAME", "MX", "PTR", "SRV", "TXT"
+		case "SVCB", "HTTPS":
+			rc.Type = recType
+			if err := setTargetSVCBContent(rc, value.Content); err != nil {
+				return nil, fmt.Errorf("unparsable record received from G-Core: %w", err)
+			}
+
+		case "TXT":
+			if err := rc.SetTargetTXTs(txtStrings(value.ContentToString())); err != nil {
+				return nil, fmt.Errorf("unparsable record received from G-Core: %w", err)
+			}
+
+		default: //  "A", "AAAA", "CAA", "NS", "CNAME", "MX", "PTR", "SRV"
 			if err := rc.PopulateFromString(recType, value.ContentToString(), zoneName); err != nil {
 				return nil, fmt.Errorf("unparsable record received from G-Core: %w", err)
 			}
 		}
+		meta := map[string]string{}
+		if health := answerHealth(value); health != "" {
+			meta[metaHealth] = health
+		}
+		if recType == "CNAME" && value.Meta[answerMetaFlatten] == true {
+			meta[metaCNAMEFlatten] = "true"
+		}
+		if stamp, ok := value.Meta[answerMetaManaged].(string); ok {
+			meta[metaManaged] = stamp
+		}
+		if managedBy, ok := value.Meta[answerMetaManagedBy].(string); ok {
+			meta[metaManagedBy] = managedBy
+		}
+		if until, ok := value.Meta[answerMetaStagedUntil].(string); ok {
+			meta[metaStagedUntil] = until
+		}
+		readRoutingMeta(value, meta)
+		if len(meta) != 0 {
+			rc.Metadata = meta
+		}
 		rcs = append(rcs, rc)
 	}
 
 	return rcs, nil
 }
 
+// metaHealth is set on records read from G-Core to the result of the
+// answer's last health check, if it has one. It is informational only.
+const metaHealth = "gcore_health"
+
+// answerHealth returns "healthy" or "unhealthy" if G-Core has health
+// checked the answer, or "" otherwise.
+func answerHealth(rr dnssdk.ResourceRecord) string {
+	healthy, ok := rr.Meta["healthy"].(bool)
+	if !ok {
+		return ""
+	}
+	if healthy {
+		return "healthy"
+	}
+	return "unhealthy"
+}
+
+// metaCNAMEFlatten is set to "true" on a CNAME record to have G-Core
+// answer queries for it with the A and AAAA records of its target.
+const metaCNAMEFlatten = "gcore_cname_flatten"
+
+// answerMetaFlatten is the answer meta field G-Core uses for
+// metaCNAMEFlatten.
+const answerMetaFlatten = "cname_flattening"
+
+// getCompareMetadata makes the diff compare whether CNAME records are
+// flattened, and the routing metadata of each answer.
+func getCompareMetadata(r *models.RecordConfig) map[string]string {
+	m := routingMetadata(r)
+	if r.Type == "CNAME" {
+		m["flatten"] = fmt.Sprint(r.Metadata[metaCNAMEFlatten] == "true")
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// nativeName returns the name G-Core uses for the RRset with key.
+//
+// G-Core's API always uses the FQDN without the trailing dot, never a
+// name relative to the zone: "www" in example.com is
+// "www.example.com", and the apex is "example.com". nativeToRecords
+// expects names in the same form.
+func nativeName(key models.RecordKey) string {
+	return strings.TrimSuffix(key.NameFQDN, ".")
+}
+
+// recordsToNative keeps the answers in the order of rcs, so that MX and
+// SRV records are written in the order they are declared. The diff
+// compares answers as a set, so G-Core returning them in another order
+// doesn't cause a change.
 func recordsToNative(rcs []*models.RecordConfig, expectedKey models.RecordKey) *dnssdk.RRSet {
 	// Merge DNSControl records into G-Core RRsets
 
 	var result *dnssdk.RRSet
 
 	for _, r := range rcs {
-		label := r.GetLabel()
-		if label == "@" {
-			label = ""
-		}
 		key := r.Key()
 
 		if key != expectedKey {
@@ -77,6 +157,29 @@ func recordsToNative(rcs []*models.RecordConfig, expectedKey models.RecordKey) *
 				Meta:    nil,
 				Enabled: true,
 			}
+		case "SMIMEA":
+			rr = dnssdk.ResourceRecord{
+				Content: []interface{}{
+					int64(r.SmimeaUsage),
+					int64(r.SmimeaSelector),
+					int64(r.SmimeaMatchingType),
+					r.GetTargetField(),
+				},
+				Meta:    nil,
+				Enabled: true,
+			}
+		case "SVCB", "HTTPS":
+			rr = dnssdk.ResourceRecord{
+				Content: svcbContent(r),
+				Meta:    nil,
+				Enabled: true,
+			}
+		case "TXT":
+			rr = dnssdk.ResourceRecord{
+				Content: []interface{}{txtContent(r.TxtStrings)},
+				Meta:    nil,
+				Enabled: true,
+			}
 		default:
 			rr = dnssdk.ResourceRecord{
 				Content: dnssdk.ContentFromValue(key.Type, r.GetTargetCombined()),
@@ -84,6 +187,16 @@ func recordsToNative(rcs []*models.RecordConfig, expectedKey models.RecordKey) *
 				Enabled: true,
 			}
 		}
+		if key.Type == "CNAME" && r.Metadata[metaCNAMEFlatten] == "true" {
+			rr.Meta = map[string]interface{}{answerMetaFlatten: true}
+		}
+		if until := r.Metadata[metaStagedUntil]; until != "" {
+			if rr.Meta == nil {
+				rr.Meta = map[string]interface{}{}
+			}
+			rr.Meta[answerMetaStagedUntil] = until
+		}
+		addRoutingMeta(&rr, r)
 
 		if result == nil {
 			result = &dnssdk.RRSet{
@@ -103,5 +216,51 @@ func recordsToNative(rcs []*models.RecordConfig, expectedKey models.RecordKey) *
 		}
 	}
 
+	if result != nil {
+		result.Filters = routingFilters(result)
+	}
 	return result
 }
+
+// txtContent returns the content of a TXT answer with the given
+// strings. Each string is quoted, as in a zonefile, so that a record
+// declared with several strings (TXT("x", ["a", "b"])) keeps them
+// separate instead of G-Core joining or splitting them.
+func txtContent(txts []string) string {
+	quoted := make([]string, len(txts))
+	for i, txt := range txts {
+		txt = strings.ReplaceAll(txt, `\`, `\\`)
+		quoted[i] = `"` + strings.ReplaceAll(txt, `"`, `\"`) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// txtStrings returns the strings of a TXT answer's content, as written
+// by txtContent. Content that isn't quoted strings, such as from the
+// G-Core control panel, is a single string.
+func txtStrings(content string) []string {
+	var txts []string
+	rest := content
+	for rest != "" {
+		if rest[0] != '"' {
+			return []string{content}
+		}
+		var b strings.Builder
+		i := 1
+		for ; i < len(rest) && rest[i] != '"'; i++ {
+			if rest[i] == '\\' && i+1 < len(rest) {
+				i++
+			}
+			b.WriteByte(rest[i])
+		}
+		if i == len(rest) {
+			return []string{content} // unterminated
+		}
+		txts = append(txts, b.String())
+		rest = strings.TrimLeft(rest[i+1:], " ")
+	}
+	if len(txts) == 0 {
+		return []string{content}
+	}
+	return txts
+}
@@ -0,0 +1,306 @@
+package gcore
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	dnssdk "github.com/G-Core/gcore-dns-sdk-go"
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// roundTrip converts rc to a G-Core RRset and back.
+func roundTrip(t *testing.T, rc *models.RecordConfig) *models.RecordConfig {
+	t.Helper()
+	rrset := recordsToNative([]*models.RecordConfig{rc}, rc.Key())
+	if rrset == nil {
+		t.Fatalf("recordsToNative returned no RRset for %s", rc.GetTargetDebug())
+	}
+	recs, err := nativeToRecords(*rrset, "example.com", rc.GetLabelFQDN(), rc.Type)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("nativeToRecords returned %d records, expected 1", len(recs))
+	}
+	return recs[0]
+}
+
+func TestOPENPGPKEYRoundTrip(t *testing.T) {
+	// RFC 7929 labels are the hashed local part of the email address.
+	const label = "c93f1e400f26708f98cb19d936620da35eec8f72e57f9eec01c1afd6._openpgpkey"
+	const key = "AQIDBAUGBwgJCgsMDQ4PEBESExQVFhcYGRobHB0eHyAhIiMkJSYn+/8="
+
+	rc := &models.RecordConfig{Type: "OPENPGPKEY", TTL: 300}
+	rc.SetLabel(label, "example.com")
+	if err := rc.SetTargetOPENPGPKEY(key); err != nil {
+		t.Fatal(err)
+	}
+
+	got := roundTrip(t, rc)
+	if got.GetLabel() != label {
+		t.Errorf("label is %q, expected %q", got.GetLabel(), label)
+	}
+	if got.GetTargetField() != key {
+		t.Errorf("key is %q, expected %q", got.GetTargetField(), key)
+	}
+	if got.ToDiffable() != rc.ToDiffable() {
+		t.Errorf("diffable is %q, expected %q", got.ToDiffable(), rc.ToDiffable())
+	}
+}
+
+func TestSMIMEARoundTrip(t *testing.T) {
+	const label = "c93f1e400f26708f98cb19d936620da35eec8f72e57f9eec01c1afd6._smimecert"
+	const cert = "0c72ac70b745ac19998811b131d662c9ac69dbdbe7cb23e5b514b56664c5d3d6"
+
+	rc := &models.RecordConfig{Type: "SMIMEA", TTL: 300}
+	rc.SetLabel(label, "example.com")
+	if err := rc.SetTargetSMIMEA(3, 1, 1, cert); err != nil {
+		t.Fatal(err)
+	}
+
+	got := roundTrip(t, rc)
+	if got.SmimeaUsage != 3 || got.SmimeaSelector != 1 || got.SmimeaMatchingType != 1 {
+		t.Errorf("fields are %d %d %d, expected 3 1 1", got.SmimeaUsage, got.SmimeaSelector, got.SmimeaMatchingType)
+	}
+	if got.GetTargetField() != cert {
+		t.Errorf("certificate data is %q, expected %q", got.GetTargetField(), cert)
+	}
+	if got.ToDiffable() != rc.ToDiffable() {
+		t.Errorf("diffable is %q, expected %q", got.ToDiffable(), rc.ToDiffable())
+	}
+}
+
+func TestSMIMEAHexNormalization(t *testing.T) {
+	// G-Core may return the certificate data in a different case or
+	// split differently than dnsconfig.js; neither should cause a diff.
+	desired := &models.RecordConfig{Type: "SMIMEA"}
+	desired.SetLabel("x._smimecert", "example.com")
+	if err := desired.SetTargetSMIMEAString("3 1 1 0c72ac70b745ac19 998811b131d662c9"); err != nil {
+		t.Fatal(err)
+	}
+
+	rrset := dnssdk.RRSet{Records: []dnssdk.ResourceRecord{{
+		Content: []interface{}{float64(3), float64(1), float64(1), "0C72AC70B745AC19998811B131D662C9"},
+	}}}
+	existing, err := nativeToRecords(rrset, "example.com", "x._smimecert.example.com", "SMIMEA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if existing[0].ToDiffable() != desired.ToDiffable() {
+		t.Errorf("diffable is %q, expected %q", existing[0].ToDiffable(), desired.ToDiffable())
+	}
+}
+
+func TestCDSRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		keytag              uint16
+		algorithm, digestty uint8
+		digest              string
+	}{
+		{2371, 13, 2, "1F987CC6583E92DF0890718C42C5F6D4ED4C4E2C6D9E2F2F4E7C1B5BC1A2E0F3"},
+		{0, 0, 0, "00"}, // RFC 8078: delete the DS records
+	} {
+		rc := &models.RecordConfig{Type: "CDS", TTL: 300}
+		rc.SetLabel("@", "example.com")
+		if err := rc.SetTargetCDS(tc.keytag, tc.algorithm, tc.digestty, tc.digest); err != nil {
+			t.Fatal(err)
+		}
+
+		got := roundTrip(t, rc)
+		if got.DsKeyTag != tc.keytag || got.DsAlgorithm != tc.algorithm || got.DsDigestType != tc.digestty || got.DsDigest != tc.digest {
+			t.Errorf("fields are %d %d %d %s, expected %d %d %d %s", got.DsKeyTag, got.DsAlgorithm, got.DsDigestType, got.DsDigest, tc.keytag, tc.algorithm, tc.digestty, tc.digest)
+		}
+		if got.ToDiffable() != rc.ToDiffable() {
+			t.Errorf("diffable is %q, expected %q", got.ToDiffable(), rc.ToDiffable())
+		}
+	}
+}
+
+func TestCDNSKEYRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		flags               uint16
+		protocol, algorithm uint8
+		key                 string
+	}{
+		{257, 3, 13, "mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ=="},
+		{0, 3, 0, "AA=="}, // RFC 8078: delete the DS records
+	} {
+		rc := &models.RecordConfig{Type: "CDNSKEY", TTL: 300}
+		rc.SetLabel("@", "example.com")
+		if err := rc.SetTargetCDNSKEY(tc.flags, tc.protocol, tc.algorithm, tc.key); err != nil {
+			t.Fatal(err)
+		}
+
+		got := roundTrip(t, rc)
+		if got.DnskeyFlags != tc.flags || got.DnskeyProtocol != tc.protocol || got.DnskeyAlgorithm != tc.algorithm || got.GetTargetField() != tc.key {
+			t.Errorf("fields are %d %d %d %s, expected %d %d %d %s", got.DnskeyFlags, got.DnskeyProtocol, got.DnskeyAlgorithm, got.GetTargetField(), tc.flags, tc.protocol, tc.algorithm, tc.key)
+		}
+		if got.ToDiffable() != rc.ToDiffable() {
+			t.Errorf("diffable is %q, expected %q", got.ToDiffable(), rc.ToDiffable())
+		}
+	}
+}
+
+func TestGenericRoundTrip(t *testing.T) {
+	rc := &models.RecordConfig{Type: "TYPE65534", TTL: 300}
+	rc.SetLabel("@", "example.com")
+	if err := rc.SetTargetGeneric(`\# 5 0d2a5b0000`); err != nil {
+		t.Fatal(err)
+	}
+
+	rrset := recordsToNative([]*models.RecordConfig{rc}, rc.Key())
+	if content := rrset.Records[0].Content; len(content) != 1 || content[0] != `\# 5 0d2a5b0000` {
+		t.Errorf("content is %#v, expected the generic data", content)
+	}
+	got := roundTrip(t, rc)
+	if got.Type != rc.Type || got.ToDiffable() != rc.ToDiffable() {
+		t.Errorf("round trip is %s %s, expected %s %s", got.Type, got.ToDiffable(), rc.Type, rc.ToDiffable())
+	}
+}
+
+func TestDNAMERoundTrip(t *testing.T) {
+	rc := &models.RecordConfig{Type: "DNAME", TTL: 300}
+	rc.SetLabel("old", "example.com")
+	if err := rc.SetTarget("new.example.com."); err != nil {
+		t.Fatal(err)
+	}
+
+	got := roundTrip(t, rc)
+	if got.Type != "DNAME" || got.GetLabel() != "old" {
+		t.Errorf("round trip is %s %s, expected DNAME old", got.Type, got.GetLabel())
+	}
+	if got.GetTargetField() != "new.example.com." {
+		t.Errorf("target is %q, expected %q", got.GetTargetField(), "new.example.com.")
+	}
+}
+
+func TestAnswerHealth(t *testing.T) {
+	rrset := dnssdk.RRSet{TTL: 300, Records: []dnssdk.ResourceRecord{
+		{Content: []interface{}{"192.0.2.1"}, Meta: map[string]interface{}{"healthy": true}},
+		{Content: []interface{}{"192.0.2.2"}, Meta: map[string]interface{}{"healthy": false}},
+		{Content: []interface{}{"192.0.2.3"}},
+	}}
+	existing, err := nativeToRecords(rrset, "example.com", "www.example.com", "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, exp := range []string{"healthy", "unhealthy", ""} {
+		if got := existing[i].Metadata[metaHealth]; got != exp {
+			t.Errorf("%s: %s is %q, expected %q", existing[i].GetTargetField(), metaHealth, got, exp)
+		}
+	}
+
+	// The health status must not cause corrections.
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "A", "192.0.2.1"),
+		makeRC("www", "A", "192.0.2.2"),
+		makeRC("www", "A", "192.0.2.3"),
+	}}
+	corrections, err := (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(dc, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected no corrections, got %d", len(corrections))
+	}
+}
+
+func TestTXTStrings(t *testing.T) {
+	for _, txts := range [][]string{
+		{"v=spf1 -all"},
+		{"part one", "part two"}, // explicit strings, kept separate
+		{`say "hi"`, `back\slash`},
+		{`x" "y`}, // one string that looks like two
+		{strings.Repeat("a", 300)},
+	} {
+		rc := makeRC("www", "TXT", "")
+		if err := rc.SetTargetTXTs(txts); err != nil {
+			t.Fatal(err)
+		}
+		got := roundTrip(t, rc)
+		if !reflect.DeepEqual(got.TxtStrings, txts) {
+			t.Errorf("%q: got %q after a round trip", txts, got.TxtStrings)
+		}
+	}
+
+	// An answer that isn't quoted is a single string.
+	rrset := dnssdk.RRSet{TTL: 300, Records: []dnssdk.ResourceRecord{{Content: []interface{}{`hello "world" again`}, Enabled: true}}}
+	recs, err := nativeToRecords(rrset, "example.com", "www.example.com", "TXT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{`hello "world" again`}; !reflect.DeepEqual(recs[0].TxtStrings, exp) {
+		t.Errorf("got %q, expected %q", recs[0].TxtStrings, exp)
+	}
+}
+
+func TestSVCBRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		typ      string
+		priority uint16
+		target   string
+		params   string
+		content  string // the answer's content, as sent to G-Core
+	}{
+		{"HTTPS", 0, "cdn.example.net.", "", "[0 cdn.example.net.]"}, // AliasMode
+		{"HTTPS", 1, ".", `alpn="h2,h3"`, "[1 . [alpn h2 h3]]"},
+		{"SVCB", 16, "svc.example.com.", `port=8443 ipv6hint=2001:db8::1,2001:db8::2 alpn=h3 no-default-alpn mandatory=alpn,port`,
+			"[16 svc.example.com. [mandatory alpn port] [alpn h3] [no-default-alpn] [port 8443] [ipv6hint 2001:db8::1 2001:db8::2]]"},
+	} {
+		rc := &models.RecordConfig{Type: tc.typ, TTL: 300}
+		rc.SetLabel("@", "example.com")
+		if err := rc.SetTargetSVCBStrings(fmt.Sprint(tc.priority), tc.target, tc.params); err != nil {
+			t.Fatal(err)
+		}
+
+		rrset := recordsToNative([]*models.RecordConfig{rc}, rc.Key())
+		if got := fmt.Sprint(rrset.Records[0].Content); got != tc.content {
+			t.Errorf("%s %d: content is %s, expected %s", tc.typ, tc.priority, got, tc.content)
+		}
+
+		got := roundTrip(t, rc)
+		if got.Type != tc.typ || got.SvcPriority != tc.priority || got.GetTargetField() != tc.target || got.SvcParams != rc.SvcParams {
+			t.Errorf("fields are %s %d %s %s, expected %s %d %s %s", got.Type, got.SvcPriority, got.GetTargetField(), got.SvcParams, tc.typ, tc.priority, tc.target, rc.SvcParams)
+		}
+		if got.ToDiffable() != rc.ToDiffable() {
+			t.Errorf("diffable is %q, expected %q", got.ToDiffable(), rc.ToDiffable())
+		}
+	}
+}
+
+func TestSVCBParamOrder(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	c := newTestProvider(t, api)
+
+	https := func(params string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "HTTPS", TTL: 300}
+		rc.SetLabel("@", "example.com")
+		if err := rc.SetTargetSVCBStrings("1", ".", params); err != nil {
+			t.Fatal(err)
+		}
+		return rc
+	}
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{https(`alpn="h2,h3" port=8443 ipv4hint=192.0.2.1`)}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The same parameters in another order are the same record.
+	dc = &models.DomainConfig{Name: "example.com", Records: models.Records{https(`ipv4hint=192.0.2.1 port=8443 alpn="h2,h3"`)}}
+	corrections, err = c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected no corrections, got %d: %s", len(corrections), corrections[0].Msg)
+	}
+}
@@ -0,0 +1,46 @@
+package gcore
+
+import (
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// isDefaultRecord reports whether rc is one of the records that G-Core
+// creates with a new zone: the SOA record and the NS records of its own
+// nameservers, at the apex.
+func isDefaultRecord(rc *models.RecordConfig) bool {
+	if rc.GetLabel() != "@" {
+		return false
+	}
+	switch rc.Type {
+	case "SOA":
+		return true
+	case "NS":
+		target := strings.TrimSuffix(strings.ToLower(rc.GetTargetField()), ".")
+		for _, ns := range defaultNameServerNames {
+			if target == ns {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// preserveDefaults leaves out of existing the records G-Core created
+// with the zone, if dnsconfig.js has no records of their RRset, so that
+// they aren't deleted. If it does, the RRset is managed as usual.
+func preserveDefaults(dc *models.DomainConfig, existing models.Records) models.Records {
+	desired := map[models.RecordKey]bool{}
+	for _, rc := range dc.Records {
+		desired[rc.Key()] = true
+	}
+	kept := make(models.Records, 0, len(existing))
+	for _, rc := range existing {
+		if isDefaultRecord(rc) && !desired[rc.Key()] {
+			continue
+		}
+		kept = append(kept, rc)
+	}
+	return kept
+}
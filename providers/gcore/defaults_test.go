@@ -0,0 +1,115 @@
+package gcore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func defaultsZone() *fakeAPI {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "example.com", "SOA", 3600, "ns1.gcorelabs.net. support.gcore.com. 1 3600 600 604800 300")
+	api.addRRSet("example.com", "example.com", "NS", 3600, "ns1.gcorelabs.net.", "ns2.gcdn.services.")
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1")
+	api.addRRSet("example.com", "old.example.com", "A", 300, "192.0.2.2")
+	return api
+}
+
+func TestPreserveDefaults(t *testing.T) {
+	api := defaultsZone()
+	c := newTestProvider(t, api)
+	c.preserveDefaults = true
+
+	corrections, err := c.GetDomainCorrections(&models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "A", "192.0.2.1"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, correction := range corrections {
+		msgs = append(msgs, correction.Msg)
+	}
+	got := strings.Join(msgs, "\n")
+	if !strings.Contains(got, "old.example.com") {
+		t.Errorf("expected old.example.com to be deleted, got corrections:\n%s", got)
+	}
+	for _, typ := range []string{"SOA", "NS"} {
+		if strings.Contains(got, typ+" example.com") {
+			t.Errorf("expected the %s records to be kept, got corrections:\n%s", typ, got)
+		}
+	}
+}
+
+func TestPreserveDefaultsDisabled(t *testing.T) {
+	api := defaultsZone()
+	c := newTestProvider(t, api)
+
+	corrections, err := c.GetDomainCorrections(&models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "A", "192.0.2.1"),
+		makeRC("old", "A", "192.0.2.2"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, correction := range corrections {
+		msgs = append(msgs, correction.Msg)
+	}
+	got := strings.Join(msgs, "\n")
+	for _, typ := range []string{"SOA", "NS"} {
+		if !strings.Contains(got, "DELETE "+typ+" example.com") {
+			t.Errorf("expected the %s records to be deleted, got corrections:\n%s", typ, got)
+		}
+	}
+}
+
+func TestPreserveDefaultsManaged(t *testing.T) {
+	// NS records in dnsconfig.js are managed as usual.
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("@", "NS", "ns1.gcorelabs.net."),
+	}}
+	existing := models.Records{
+		makeRC("@", "NS", "ns1.gcorelabs.net."),
+		makeRC("@", "NS", "ns2.gcdn.services."),
+		makeRC("@", "NS", "ns.example.net."),
+	}
+	if got := preserveDefaults(dc, existing); len(got) != 3 {
+		t.Errorf("got %d records, expected all 3 to be kept", len(got))
+	}
+}
+
+func TestPreserveDefaultsDeleteApex(t *testing.T) {
+	// Deleting all the other apex records keeps the preserved ones.
+	api := defaultsZone()
+	api.addRRSet("example.com", "example.com", "A", 300, "192.0.2.1")
+	api.addRRSet("example.com", "example.com", "MX", 300, "10 mail.example.com.")
+	c := newTestProvider(t, api)
+	c.preserveDefaults = true
+
+	corrections, err := c.GetDomainCorrections(&models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "A", "192.0.2.1"),
+		makeRC("old", "A", "192.0.2.2"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rrsets := api.zones["example.com"].RRSets
+	for _, typ := range []string{"SOA", "NS"} {
+		if _, ok := rrsets[fakeRRSetKey{"example.com", typ}]; !ok {
+			t.Errorf("the %s records were deleted:\n%s", typ, strings.Join(api.requests, "\n"))
+		}
+	}
+	for _, typ := range []string{"A", "MX"} {
+		if _, ok := rrsets[fakeRRSetKey{"example.com", typ}]; ok {
+			t.Errorf("the apex %s records weren't deleted", typ)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package gcore
+
+// G-Core can disable a whole RRset: it keeps the RRset, but doesn't
+// answer queries with it. A disabled RRset is still managed like any
+// other. It is updated if its records change, and deleted if it isn't in
+// dnsconfig.js.
+
+import (
+	"fmt"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// metaRRSetEnabled is set to "false" on a record to disable its RRset.
+// Records read from a disabled RRset have it set to "false".
+const metaRRSetEnabled = "gcore_rrset_enabled"
+
+// rrsetDisabled reports whether the RRset of recs is disabled, which it
+// is if any of recs has metaRRSetEnabled set to "false".
+func rrsetDisabled(recs models.Records) bool {
+	for _, rc := range recs {
+		if rc.Metadata[metaRRSetEnabled] == "false" {
+			return true
+		}
+	}
+	return false
+}
+
+// markDisabled sets metaRRSetEnabled to "false" on recs, which were read
+// from a disabled RRset.
+func markDisabled(recs []*models.RecordConfig) {
+	for _, rc := range recs {
+		if rc.Metadata == nil {
+			rc.Metadata = map[string]string{}
+		}
+		rc.Metadata[metaRRSetEnabled] = "false"
+	}
+}
+
+// enabledChanges returns, for each RRset in both desired and existing
+// that is to be enabled or disabled, a message saying so.
+func enabledChanges(desired, existing map[models.RecordKey]models.Records) map[models.RecordKey]string {
+	msgs := map[models.RecordKey]string{}
+	for key, recs := range desired {
+		if _, ok := existing[key]; !ok {
+			continue
+		}
+		switch was, is := rrsetDisabled(existing[key]), rrsetDisabled(recs); {
+		case is && !was:
+			msgs[key] = fmt.Sprintf("DISABLE %s %s", key.NameFQDN, key.Type)
+		case was && !is:
+			msgs[key] = fmt.Sprintf("ENABLE %s %s", key.NameFQDN, key.Type)
+		}
+	}
+	return msgs
+}
+
+// enabledField returns the enabled field to send for the RRset with the
+// records desired, which currently has the records existing: false if
+// it is to be disabled, true if it is disabled now and is to be enabled,
+// and nil otherwise.
+func enabledField(desired, existing models.Records) *bool {
+	switch {
+	case rrsetDisabled(desired):
+		enabled := false
+		return &enabled
+	case rrsetDisabled(existing):
+		enabled := true
+		return &enabled
+	}
+	return nil
+}
+
+// checkRRSetEnabled checks the value of metaRRSetEnabled.
+func checkRRSetEnabled(rc *models.RecordConfig) error {
+	switch v, ok := rc.Metadata[metaRRSetEnabled]; {
+	case !ok, v == "true", v == "false":
+		return nil
+	default:
+		return fmt.Errorf("%s must be \"true\" or \"false\", got %q", metaRRSetEnabled, v)
+	}
+}
@@ -0,0 +1,93 @@
+package gcore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestRRSetEnabled(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1", "192.0.2.2")
+	disabled := false
+	rrset := api.zones["example.com"].RRSets[fakeRRSetKey{"www.example.com", "A"}]
+	rrset.Enabled = &disabled
+	api.zones["example.com"].RRSets[fakeRRSetKey{"www.example.com", "A"}] = rrset
+	c := newTestProvider(t, api)
+
+	// desired returns the www A RRset, disabled by the metadata of its
+	// first record if disable is set.
+	desired := func(disable bool) *models.DomainConfig {
+		first := makeRC("www", "A", "192.0.2.1")
+		if disable {
+			first.Metadata = map[string]string{metaRRSetEnabled: "false"}
+		}
+		return &models.DomainConfig{Name: "example.com", Records: models.Records{first, makeRC("www", "A", "192.0.2.2")}}
+	}
+	// push pushes dc, checking that it has one correction with msg, if
+	// msg isn't empty, and none otherwise.
+	push := func(dc *models.DomainConfig, msg string) {
+		t.Helper()
+		existing, err := c.GetZoneRecords("example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		corrections, err := c.GenerateDomainCorrections(dc, existing)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg == "" {
+			for _, correction := range corrections {
+				t.Errorf("unexpected correction: %s", correction.Msg)
+			}
+			return
+		}
+		if len(corrections) != 1 {
+			t.Fatalf("got %d corrections, expected 1", len(corrections))
+		}
+		if !strings.Contains(corrections[0].Msg, msg) {
+			t.Errorf("got correction %q, expected it to contain %q", corrections[0].Msg, msg)
+		}
+		if err := corrections[0].F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// check checks whether the RRset is enabled at G-Core, and that it
+	// still has both answers.
+	check := func(enabled bool) {
+		t.Helper()
+		rrset, ok := api.zones["example.com"].RRSets[fakeRRSetKey{"www.example.com", "A"}]
+		if !ok {
+			t.Fatal("www A was deleted")
+		}
+		if got := rrset.Enabled == nil || *rrset.Enabled; got != enabled {
+			t.Errorf("www A is enabled: %t, expected %t", got, enabled)
+		}
+		if len(rrset.Records) != 2 {
+			t.Errorf("www A has %d answers, expected 2", len(rrset.Records))
+		}
+	}
+
+	// The disabled RRset is read as disabled, and is left as it is.
+	push(desired(true), "")
+	check(false)
+
+	push(desired(false), "ENABLE www.example.com A")
+	check(true)
+	push(desired(false), "")
+
+	push(desired(true), "DISABLE www.example.com A")
+	check(false)
+	push(desired(true), "")
+}
+
+func TestAuditRRSetEnabled(t *testing.T) {
+	rc := makeRC("www", "A", "192.0.2.1")
+	rc.Metadata = map[string]string{metaRRSetEnabled: "no"}
+	errs := AuditRecords([]*models.RecordConfig{rc})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `gcore_rrset_enabled must be "true" or "false", got "no"`) {
+		t.Errorf("got errors %v, expected one about gcore_rrset_enabled", errs)
+	}
+}
@@ -0,0 +1,29 @@
+package gcore
+
+import "github.com/StackExchange/dnscontrol/v3/pkg/diff"
+
+// recordedDiff is the diff that the corrections of a zone were computed
+// from, after the records were prepared as G-Core compares them.
+type recordedDiff struct {
+	unchanged, create, toDelete, modify diff.Changeset
+}
+
+// recordDiff saves d as the diff of zone's last corrections.
+func (c *gcoreProvider) recordDiff(zone string, d recordedDiff) {
+	c.diffsMu.Lock()
+	defer c.diffsMu.Unlock()
+	if c.diffs == nil {
+		c.diffs = map[string]recordedDiff{}
+	}
+	c.diffs[zone] = d
+}
+
+// LastDiff returns the diff that the last corrections of domain were
+// computed from: that of its records with the snapshot's added, its
+// ALIASes flattened and its names in A-labels, against those G-Core has.
+func (c *gcoreProvider) LastDiff(domain string) (unchanged, create, toDelete, modify diff.Changeset, ok bool) {
+	c.diffsMu.Lock()
+	defer c.diffsMu.Unlock()
+	d, ok := c.diffs[domain]
+	return d.unchanged, d.create, d.toDelete, d.modify, ok
+}
@@ -0,0 +1,64 @@
+package gcore
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
+)
+
+func TestLastDiff(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "example.com", "A", 300, "192.0.2.1") // the flattened ALIAS
+	api.addRRSet("example.com", "xn--bcher-kva.example.com", "A", 300, "192.0.2.5")
+	api.addRRSet("example.com", "old.example.com", "A", 300, "192.0.2.9")
+	c := newTestProvider(t, api)
+	c.resolver = fakeResolver{"target.example.net.": {"192.0.2.1"}}
+
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("@", "ALIAS", "target.example.net."),
+		makeRC("bücher", "A", "192.0.2.5"),
+		makeRC("new", "A", "192.0.2.7"),
+	}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, correction := range corrections {
+		msgs = append(msgs, correction.Msg)
+	}
+
+	unchanged, create, toDelete, modify, ok := c.LastDiff("example.com")
+	if !ok {
+		t.Fatal("no diff was recorded")
+	}
+	explain := func(cs diff.Changeset) string {
+		var lines []string
+		for _, c := range cs {
+			lines = append(lines, c.Explain())
+		}
+		sort.Strings(lines)
+		return strings.Join(lines, "\n")
+	}
+	// The ALIAS and the IDN name are compared as they are pushed, so
+	// they are kept, like the corrections say.
+	if got, exp := explain(unchanged), "example.com A 192.0.2.1 is kept because it matches the configuration\n"+
+		"xn--bcher-kva.example.com A 192.0.2.5 is kept because it matches the configuration"; got != exp {
+		t.Errorf("got kept:\n%s\nexpected:\n%s", got, exp)
+	}
+	if len(modify) != 0 {
+		t.Errorf("got updates:\n%s\nexpected none", explain(modify))
+	}
+	// Those that change are the ones in the corrections.
+	if got := strings.Join(msgs, "\n"); len(create) != 1 || len(toDelete) != 1 ||
+		!strings.Contains(got, "CREATE A new.example.com") || !strings.Contains(got, "DELETE A old.example.com") {
+		t.Errorf("got created:\n%s\ndeleted:\n%s\nand corrections:\n%s", explain(create), explain(toDelete), got)
+	}
+	if _, _, _, _, ok := c.LastDiff("example.net"); ok {
+		t.Error("got a diff for example.net, whose corrections weren't computed")
+	}
+}
@@ -0,0 +1,34 @@
+package gcore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/miekg/dns"
+)
+
+// exportedRecords reads the records of domain from G-Core's zonefile
+// export. This is one request, rather than one for each RRset, but the
+// zonefile only has what DNS answers with: it doesn't have the answers'
+// metadata, such as their routing filters or whether a CNAME is
+// flattened, and has no disabled answers.
+func (c *gcoreProvider) exportedRecords(domain string) (models.Records, error) {
+	raw, err := c.exportZone(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var rrs []dns.RR
+	zp := dns.NewZoneParser(strings.NewReader(raw), dns.Fqdn(domain), "")
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		if dnssecManagedTypes[dns.TypeToString[rr.Header().Rrtype]] {
+			continue
+		}
+		rrs = append(rrs, rr)
+	}
+	if err := zp.Err(); err != nil {
+		return nil, fmt.Errorf("parse the export of zone %s: %w", domain, err)
+	}
+	return models.RRstoRCs(rrs, domain)
+}
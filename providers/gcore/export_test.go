@@ -0,0 +1,63 @@
+package gcore
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestExportedRecords(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "example.com", "MX", 300, "10 mail.example.com.")
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1", "192.0.2.2")
+	api.addRRSet("example.com", "txt.example.com", "TXT", 600, "v=spf1 -all")
+	api.addRRSet("example.com", "alias.example.com", "CNAME", 300, "www.example.com.")
+	api.addRRSet("example.com", "www.example.com", "RRSIG", 300, "A 13 3 300 20261101000000 20261015000000 12345 example.com. c2ln")
+	c := newTestProvider(t, api)
+
+	byAPI, err := c.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.readExport = true
+	before := len(api.requests)
+	byExport, err := c.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(api.requests) - before; n != 1 {
+		t.Errorf("reading the export sent %d requests, expected 1", n)
+	}
+
+	describe := func(recs models.Records) string {
+		var lines []string
+		for _, rc := range recs {
+			lines = append(lines, rc.GetLabel()+" "+rc.Type+" "+rc.GetTargetCombined()+" "+fmt.Sprint(rc.TTL))
+		}
+		sort.Strings(lines)
+		return strings.Join(lines, "\n")
+	}
+	if got, exp := describe(byExport), describe(byAPI); got != exp {
+		t.Errorf("the export has records:\n%s\nexpected the same as the API:\n%s", got, exp)
+	}
+
+	// Desired records that match the export have no corrections.
+	corrections, err := (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(&models.DomainConfig{Name: "example.com", Records: byAPI}, byExport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		t.Errorf("unexpected correction: %s", correction.Msg)
+	}
+}
+
+func TestZoneReadInvalid(t *testing.T) {
+	_, err := NewGCore(map[string]string{"api-key": "test", "zone-read": "axfr"}, nil)
+	if err == nil || !strings.Contains(err.Error(), `zone-read must be "api" or "export"`) {
+		t.Errorf("got error %v, expected one about zone-read", err)
+	}
+}
@@ -0,0 +1,347 @@
+package gcore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	dnssdk "github.com/G-Core/gcore-dns-sdk-go"
+)
+
+// fakeAPI is an in-memory implementation of the parts of the G-Core
+// DNS API used by the provider.
+type fakeAPI struct {
+	mu       sync.Mutex
+	zones    map[string]*fakeZone
+	fail     map[string]int // "METHOD /path" to HTTP status
+	failN    map[string]int // number of times left to fail, if limited
+	requests []string       // "METHOD /path" of every request received
+	headers  http.Header    // added to every response
+
+	noBulkDelete bool // reject DELETE /v2/zones/{zone}/{name}
+	noBulkUpdate bool // reject PUT /v2/zones/{zone}/{name}
+}
+
+type fakeZone struct {
+	DNSSECEnabled bool
+	DNSSECInfo    map[string]interface{} // extra fields of GET .../dnssec
+	Disabled      bool
+	Contact       string
+	RRSets        map[fakeRRSetKey]rrsetBody
+}
+
+type fakeRRSetKey struct {
+	Name, Type string
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		zones: map[string]*fakeZone{},
+		fail:  map[string]int{},
+		failN: map[string]int{},
+	}
+}
+
+// addZone creates an empty zone.
+func (f *fakeAPI) addZone(name string) *fakeZone {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	z := &fakeZone{RRSets: map[fakeRRSetKey]rrsetBody{}}
+	f.zones[name] = z
+	return z
+}
+
+// addRRSet stores an RRset with the given content, one string per record.
+func (f *fakeAPI) addRRSet(zone, name, typ string, ttl int, contents ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	rrset := rrsetBody{RRSet: dnssdk.RRSet{TTL: ttl}}
+	for _, content := range contents {
+		rrset.Records = append(rrset.Records, dnssdk.ResourceRecord{
+			Content: dnssdk.ContentFromValue(typ, content),
+			Enabled: true,
+		})
+	}
+	f.zones[zone].RRSets[fakeRRSetKey{name, typ}] = rrset
+}
+
+// failRequest makes requests matching "METHOD /path" return status.
+func (f *fakeAPI) failRequest(req string, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fail[req] = status
+}
+
+// failRequestN makes the next n requests matching "METHOD /path" return
+// status.
+func (f *fakeAPI) failRequestN(req string, status int, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fail[req] = status
+	f.failN[req] = n
+}
+
+// countRequests returns the number of requests received with the
+// given "METHOD /path" prefix.
+func (f *fakeAPI) countRequests(prefix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, req := range f.requests {
+		if strings.HasPrefix(req, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	req := r.Method + " " + r.URL.Path
+	f.requests = append(f.requests, req)
+	for k, v := range f.headers {
+		w.Header()[k] = v
+	}
+	if n, ok := f.failN[req]; ok {
+		if n == 0 {
+			delete(f.fail, req)
+			delete(f.failN, req)
+		} else {
+			f.failN[req] = n - 1
+		}
+	}
+	if status, ok := f.fail[req]; ok {
+		writeJSON(w, status, map[string]string{"error": http.StatusText(status)})
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v2/zones"), "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && parts[0] == "":
+		var list dnssdk.ListZones
+		for _, name := range f.zoneNames() {
+			list.Zones = append(list.Zones, dnssdk.Zone{Name: name})
+		}
+		writeJSON(w, http.StatusOK, list)
+
+	case r.Method == http.MethodPost && parts[0] == "":
+		var body dnssdk.AddZone
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		f.zones[body.Name] = &fakeZone{RRSets: map[fakeRRSetKey]rrsetBody{}}
+		writeJSON(w, http.StatusOK, dnssdk.CreateResponse{ID: uint64(len(f.zones))})
+
+	case len(parts) == 1 && r.Method == http.MethodPatch:
+		var body struct{ Contact string }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		f.zones[parts[0]].Contact = body.Contact
+		writeJSON(w, http.StatusOK, struct{}{})
+
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		delete(f.zones, parts[0])
+		writeJSON(w, http.StatusOK, struct{}{})
+
+	case len(parts) == 1:
+		z, ok := f.zones[parts[0]]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "zone not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, f.zoneJSON(parts[0], z))
+
+	case len(parts) == 2 && parts[1] == "export" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]string{"raw_zone": f.zonefile(f.zones[parts[0]])})
+
+	case len(parts) == 2 && parts[1] == "dnssec" && r.Method == http.MethodGet:
+		info := map[string]interface{}{"enabled": f.zones[parts[0]].DNSSECEnabled}
+		for k, v := range f.zones[parts[0]].DNSSECInfo {
+			info[k] = v
+		}
+		writeJSON(w, http.StatusOK, info)
+
+	case len(parts) == 2 && parts[1] == "dnssec" && r.Method == http.MethodPatch:
+		var body struct{ Enabled bool }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		f.zones[parts[0]].DNSSECEnabled = body.Enabled
+		writeJSON(w, http.StatusOK, struct{}{})
+
+	case len(parts) == 2 && (parts[1] == "enable" || parts[1] == "disable") && r.Method == http.MethodPatch:
+		f.zones[parts[0]].Disabled = parts[1] == "disable"
+		writeJSON(w, http.StatusOK, struct{}{})
+
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		if f.noBulkDelete {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		z := f.zones[parts[0]]
+		for key := range z.RRSets {
+			if key.Name == parts[1] {
+				delete(z.RRSets, key)
+			}
+		}
+		writeJSON(w, http.StatusOK, struct{}{})
+
+	case len(parts) == 2 && r.Method == http.MethodPut:
+		if f.noBulkUpdate {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		var body struct{ RRSets []nameRRSet }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		z := f.zones[parts[0]]
+		for _, rrset := range body.RRSets {
+			z.RRSets[fakeRRSetKey{parts[1], rrset.Type}] = rrset.rrsetBody
+		}
+		writeJSON(w, http.StatusOK, struct{}{})
+
+	case len(parts) == 3:
+		z, ok := f.zones[parts[0]]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "zone not found"})
+			return
+		}
+		key := fakeRRSetKey{parts[1], parts[2]}
+		switch r.Method {
+		case http.MethodGet:
+			rrset, ok := z.RRSets[key]
+			if !ok {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "rrset not found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, rrset)
+		case http.MethodPost, http.MethodPut:
+			var rrset rrsetBody
+			if err := json.NewDecoder(r.Body).Decode(&rrset); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			if _, exists := z.RRSets[key]; exists && r.Method == http.MethodPost {
+				writeJSON(w, http.StatusConflict, map[string]string{"error": "rrset already exists"})
+				return
+			} else if !exists && r.Method == http.MethodPut {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "rrset not found"})
+				return
+			}
+			z.RRSets[key] = rrset
+			writeJSON(w, http.StatusOK, struct{}{})
+		case http.MethodDelete:
+			delete(z.RRSets, key)
+			writeJSON(w, http.StatusOK, struct{}{})
+		}
+
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+	}
+}
+
+func (f *fakeAPI) zoneNames() []string {
+	var names []string
+	for name := range f.zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *fakeAPI) zoneJSON(name string, z *fakeZone) interface{} {
+	var records []dnssdk.ZoneRecord
+	for key := range z.RRSets {
+		records = append(records, dnssdk.ZoneRecord{Name: key.Name, Type: key.Type})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Name != records[j].Name {
+			return records[i].Name < records[j].Name
+		}
+		return records[i].Type < records[j].Type
+	})
+	return struct {
+		Name          string              `json:"name"`
+		DNSSECEnabled bool                `json:"dnssec_enabled"`
+		Enabled       bool                `json:"enabled"`
+		Contact       string              `json:"contact"`
+		Records       []dnssdk.ZoneRecord `json:"records"`
+	}{name, z.DNSSECEnabled, !z.Disabled, z.Contact, records}
+}
+
+// zonefile returns the RRsets of z in zonefile form, as G-Core's export
+// does.
+func (f *fakeAPI) zonefile(z *fakeZone) string {
+	var keys []fakeRRSetKey
+	for key := range z.RRSets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Name != keys[j].Name {
+			return keys[i].Name < keys[j].Name
+		}
+		return keys[i].Type < keys[j].Type
+	})
+	var b strings.Builder
+	for _, key := range keys {
+		rrset := z.RRSets[key]
+		if rrset.Enabled != nil && !*rrset.Enabled {
+			continue
+		}
+		for _, rr := range rrset.Records {
+			if !rr.Enabled {
+				continue
+			}
+			content := rr.ContentToString()
+			if key.Type == "TXT" {
+				content = strconv.Quote(content)
+			}
+			fmt.Fprintf(&b, "%s.\t%d\tIN\t%s\t%s\n", key.Name, rrset.TTL, key.Type, content)
+		}
+	}
+	return b.String()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// newTestProvider returns a provider which talks to a fake API server.
+func newTestProvider(t *testing.T, api *fakeAPI) *gcoreProvider {
+	t.Helper()
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	c := &gcoreProvider{
+		provider: dnssdk.NewClient(dnssdk.PermanentAPIKeyAuth("test")),
+		ctx:      context.Background(),
+		apiKey:   "test",
+		resolver: fakeResolver{},
+	}
+	c.provider.BaseURL, _ = url.Parse(srv.URL)
+	return c
+}
+
+// offlineClient returns an SDK client for tests which only generate
+// corrections, and so never send requests.
+func offlineClient() *dnssdk.Client {
+	return dnssdk.NewClient(dnssdk.PermanentAPIKeyAuth("test"))
+}
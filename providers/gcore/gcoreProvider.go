@@ -3,57 +3,178 @@ package gcore
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
 	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
 	"github.com/StackExchange/dnscontrol/v3/providers"
 
 	dnssdk "github.com/G-Core/gcore-dns-sdk-go"
+	"golang.org/x/net/idna"
 )
 
 /*
 G-Core API DNS provider:
 Info required in `creds.json`:
    - api-key
+Optional:
+   - proxy
+   - template-domain
+   - zone-read
+   - stamp-managed
 */
 
 type gcoreProvider struct {
 	provider *dnssdk.Client
 	ctx      context.Context
+	apiKey   string
+	resolver aliasResolver
+
+	transport *retryTransport // the SDK client's transport, which counts requests
+
+	// Set (to 1, atomically) once the API rejects deleteName's bulk
+	// delete, or updateName's bulk update.
+	noBulkDelete int32
+	noBulkUpdate int32
+
+	templateDomain string // the zone whose records are copied to new zones
+
+	preserveDefaults bool // keep the records G-Core creates with a zone; see preserveDefaults
+
+	readExport bool // read zones from G-Core's zonefile export; see exportedRecords
+
+	managedStamp string // if set, written to the meta of the answers written; see stamp
+
+	now func() time.Time // the current time, for staged changes; time.Now if nil
+
+	compareOnly bool // corrections have no F; see SetCompareOnly
+
+	pushedMu sync.Mutex
+	pushed   map[string]map[models.RecordKey]pushedRRset // by domain, the RRsets changed by corrections that ran
+
+	diffsMu sync.Mutex
+	diffs   map[string]recordedDiff // by zone, the diff of the last corrections; see LastDiff
+
+	discover     bool // discover-capabilities is enabled
+	discoverOnce sync.Once
+	discovered   map[providers.Capability]bool
+	discoverErr  error
 }
 
 // NewGCore creates the provider.
 func NewGCore(m map[string]string, metadata json.RawMessage) (providers.DNSServiceProvider, error) {
-	if m["api-key"] == "" {
+	// preview --from-snapshot never contacts G-Core (see GetSnapshotCorrections).
+	offline := m["_from_snapshot"] == "true"
+	if m["api-key"] == "" && !offline {
 		return nil, fmt.Errorf("missing G-Core API key")
 	}
+	base, err := newBaseTransport(m["proxy"])
+	if err != nil {
+		return nil, err
+	}
 
 	c := &gcoreProvider{
 		provider: dnssdk.NewClient(dnssdk.PermanentAPIKeyAuth(m["api-key"])),
 		ctx:      context.TODO(),
+		apiKey:   m["api-key"],
+		resolver: net.DefaultResolver,
+		discover: m["discover-capabilities"] == "true" && !offline,
+
+		templateDomain:   m["template-domain"],
+		preserveDefaults: m["preserve-defaults"] == "true",
+	}
+	switch m["zone-read"] {
+	case "", "api":
+	case "export":
+		c.readExport = true
+	default:
+		return nil, fmt.Errorf("zone-read must be \"api\" or \"export\", not %q", m["zone-read"])
+	}
+	if m["stamp-managed"] == "true" {
+		c.managedStamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	c.transport = newRetryTransport(base)
+	c.provider.HTTPClient.Transport = c.transport
+
+	if m["validate-api-key"] == "true" && !offline {
+		if err := c.checkAuth(); err != nil {
+			return nil, err
+		}
 	}
 
 	return c, nil
 }
 
+// Diagnostics reports the number of API requests made, and the quota
+// left, if G-Core reported it.
+func (c *gcoreProvider) Diagnostics() []string {
+	if c.transport == nil {
+		return nil
+	}
+	return c.transport.Diagnostics()
+}
+
+// checkAuth makes a cheap authenticated request to check that the API
+// key is valid, so that a bad key is reported before any changes are made.
+func (c *gcoreProvider) checkAuth() error {
+	_, err := c.provider.Zones(c.ctx)
+	if err == nil {
+		return nil
+	}
+	var apiErr dnssdk.APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+		return fmt.Errorf("G-Core rejected the API key (HTTP %d); check api-key in creds.json", apiErr.StatusCode)
+	}
+	return fmt.Errorf("G-Core API key check failed: %w", err)
+}
+
 var features = providers.DocumentationNotes{
-	providers.CanAutoDNSSEC:          providers.Cannot(),
+	providers.CanAutoDNSSEC:          providers.Cannot("Depends on the account. Enable with the CanAutoDNSSEC capability override"),
+	providers.CanConcur:              providers.Can(),
 	providers.CanGetZones:            providers.Can(),
-	providers.CanUseAlias:            providers.Cannot(),
+	providers.CanUseAlias:            providers.Can("Flattened by DNSControl into the target's A and AAAA records on each run"),
 	providers.CanUseCAA:              providers.Can(),
+	providers.CanUseCDNSKEY:          providers.Can(),
+	providers.CanUseCDS:              providers.Can(),
+	providers.CanUseDNAME:            providers.Can(),
 	providers.CanUseDS:               providers.Cannot(),
+	providers.CanUseHTTPS:            providers.Can(),
 	providers.CanUseNAPTR:            providers.Cannot(),
+	providers.CanUseOPENPGPKEY:       providers.Can(),
 	providers.CanUsePTR:              providers.Cannot(),
+	providers.CanUseSMIMEA:           providers.Can(),
 	providers.CanUseSRV:              providers.Can("G-Core doesn't support SRV records with empty targets"),
 	providers.CanUseSSHFP:            providers.Cannot(),
+	providers.CanUseSVCB:             providers.Can(),
 	providers.CanUseTLSA:             providers.Cannot(),
 	providers.DocCreateDomains:       providers.Can(),
 	providers.DocDualHost:            providers.Can(),
 	providers.DocOfficiallySupported: providers.Cannot(),
 }
 
+// overridable lists the capabilities that are implemented, but which
+// only work if the G-Core account supports them.
+var overridable = providers.OverridableCapabilities{
+	providers.CanAutoDNSSEC,
+}
+
+// metaEnabled is the domain metadata that enables or disables the zone.
+const metaEnabled = "gcore_enabled"
+
+// metaContact is the domain metadata that sets the zone's contact email
+// address (the SOA RNAME).
+const metaContact = "gcore_contact"
+
 var defaultNameServerNames = []string{
 	"ns1.gcorelabs.net",
 	"ns2.gcdn.services",
@@ -64,7 +185,43 @@ func init() {
 		Initializer:   NewGCore,
 		RecordAuditor: AuditRecords,
 	}
-	providers.RegisterDomainServiceProviderType("GCORE", fns, features)
+	providers.RegisterDomainServiceProviderType("GCORE", fns, features, overridable)
+}
+
+// DiscoverCapabilities returns the capabilities that depend on the
+// account's plan, if discover-capabilities is enabled. The result is
+// cached. G-Core's API doesn't describe the plan, so DNSSEC is probed
+// by reading the DNSSEC settings of a zone, which is refused (HTTP 403)
+// if the plan doesn't include it.
+func (c *gcoreProvider) DiscoverCapabilities() (map[providers.Capability]bool, error) {
+	if !c.discover {
+		return nil, nil
+	}
+	c.discoverOnce.Do(func() {
+		c.discovered, c.discoverErr = c.discoverCapabilities()
+	})
+	return c.discovered, c.discoverErr
+}
+
+func (c *gcoreProvider) discoverCapabilities() (map[providers.Capability]bool, error) {
+	zones, err := c.provider.Zones(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(zones) == 0 {
+		return nil, nil // nothing to probe
+	}
+
+	_, err = c.dnssecInfo(zones[0].Name)
+	var apiErr dnssdk.APIError
+	switch {
+	case err == nil:
+		return map[providers.Capability]bool{providers.CanAutoDNSSEC: true}, nil
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden:
+		return map[providers.Capability]bool{providers.CanAutoDNSSEC: false}, nil
+	default:
+		return nil, err
+	}
 }
 
 // GetNameservers returns the nameservers for a domain.
@@ -79,12 +236,252 @@ func (c *gcoreProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models
 	}
 	models.PostProcessRecords(existing)
 	clean := PrepFoundRecords(existing)
-	PrepDesiredRecords(dc)
-	return c.GenerateDomainCorrections(dc, clean)
+	if err := c.prepareDomain(dc, existing); err != nil {
+		return nil, err
+	}
+
+	corrections, err := c.getZoneEnabledCorrections(dc)
+	if err != nil {
+		return nil, err
+	}
+	contactCorrections, err := c.getContactCorrections(dc)
+	if err != nil {
+		return nil, err
+	}
+	corrections = append(corrections, contactCorrections...)
+	dnssecCorrections, err := c.getDNSSECCorrections(dc)
+	if err != nil {
+		return nil, err
+	}
+	corrections = append(corrections, dnssecCorrections...)
+	recordCorrections, err := c.GenerateDomainCorrections(dc, clean)
+	if err != nil {
+		return nil, err
+	}
+	corrections = append(corrections, recordCorrections...)
+	reverseCorrections, err := c.getReverseCorrections(dc)
+	if err != nil {
+		return nil, err
+	}
+	corrections = append(corrections, reverseCorrections...)
+	orderCorrections(dc, corrections)
+	if c.compareOnly {
+		for _, correction := range corrections {
+			correction.F = nil
+		}
+	}
+	return corrections, nil
+}
+
+// prepareDomain adds the records of dc that come from elsewhere, converts
+// them as G-Core needs them, and prints the warnings about dc and
+// existing, the records read from G-Core.
+func (c *gcoreProvider) prepareDomain(dc *models.DomainConfig, existing models.Records) error {
+	if err := addK8sRecords(dc); err != nil {
+		return err
+	}
+	if err := PrepDesiredRecords(dc); err != nil {
+		return err
+	}
+	if err := c.flattenAliases(dc); err != nil {
+		return err
+	}
+	for _, w := range practiceWarnings(dc, existing) {
+		printer.Warnf("%s\n", w)
+	}
+	return nil
+}
+
+// GetSnapshotCorrections returns the corrections of the records of dc
+// against existing, a snapshot of the zone's records, without contacting
+// G-Core. The zone's state, contact and DNSSEC aren't in the snapshot,
+// so they aren't compared. The corrections can't be run.
+func (c *gcoreProvider) GetSnapshotCorrections(dc *models.DomainConfig, existing models.Records) ([]*models.Correction, error) {
+	models.PostProcessRecords(existing)
+	clean := PrepFoundRecords(existing)
+	if err := c.prepareDomain(dc, existing); err != nil {
+		return nil, err
+	}
+	corrections, err := c.GenerateDomainCorrections(dc, clean)
+	if err != nil {
+		return nil, err
+	}
+	orderCorrections(dc, corrections)
+	for _, correction := range corrections {
+		correction.F = nil
+	}
+	return corrections, nil
+}
+
+// SetCompareOnly makes the provider read-only: the corrections it
+// returns describe the changes, but can't make them.
+func (c *gcoreProvider) SetCompareOnly() {
+	c.compareOnly = true
+}
+
+// SetContext makes the provider's API requests with ctx, so that they
+// are cancelled once it is done.
+func (c *gcoreProvider) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// getZoneEnabledCorrections returns corrections that enable or disable
+// the zone. The state is only managed if the gcore_enabled domain
+// metadata is set.
+func (c *gcoreProvider) getZoneEnabledCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	var desired bool
+	switch v := dc.Metadata[metaEnabled]; v {
+	case "":
+		return nil, nil
+	case "true":
+		desired = true
+	case "false":
+		desired = false
+	default:
+		return nil, fmt.Errorf("%s must be \"true\" or \"false\", got %q", metaEnabled, v)
+	}
+
+	zone, err := c.zoneInfo(dc.Name)
+	if err != nil {
+		return nil, err
+	}
+	if actual := zone.Enabled == nil || *zone.Enabled; actual == desired {
+		return nil, nil
+	}
+
+	msg, action := "Disable zone", "disable"
+	if desired {
+		msg, action = "Enable zone", "enable"
+	}
+	uri := path.Join("/v2/zones", strings.Trim(dc.Name, "."), action)
+	return []*models.Correction{
+		{
+			Msg:      msg,
+			F:        func() error { return c.setZoneEnabled(dc.Name, desired) },
+			Requests: []models.HTTPRequest{c.describeRequest(http.MethodPatch, uri, nil)},
+		},
+	}, nil
+}
+
+// getContactCorrections returns a correction that changes the zone's
+// contact email address, if the gcore_contact domain metadata is set
+// to another address.
+func (c *gcoreProvider) getContactCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	given := dc.Metadata[metaContact]
+	if given == "" {
+		return nil, nil
+	}
+	desired, err := encodeContact(given)
+	if err != nil {
+		return nil, err
+	}
+
+	zone, err := c.zoneInfo(dc.Name)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := encodeContact(zone.Contact)
+	if err != nil {
+		existing = zone.Contact
+	}
+	if strings.EqualFold(existing, desired) {
+		return nil, nil
+	}
+
+	msg := fmt.Sprintf("Change contact (SOA RNAME) from %q to %q", zone.Contact, desired)
+	if desired != given {
+		msg += fmt.Sprintf(" (%s)", given)
+	}
+	uri := path.Join("/v2/zones", strings.Trim(dc.Name, "."))
+	return []*models.Correction{
+		{
+			Msg:      msg,
+			F:        func() error { return c.setContact(dc.Name, desired) },
+			Requests: []models.HTTPRequest{c.describeRequest(http.MethodPatch, uri, map[string]string{"contact": desired})},
+		},
+	}, nil
+}
+
+// encodeContact returns the contact email address as the SOA RNAME needs
+// it, with an internationalized domain in A-labels (punycode), such as
+// hostmaster@xn--bcher-kva.example for hostmaster@bücher.example. The
+// local part becomes the first label of the RNAME as it is, so it must be
+// ASCII: encoding it like the domain would name another mailbox.
+func encodeContact(contact string) (string, error) {
+	i := strings.Index(contact, "@")
+	if i <= 0 || i == len(contact)-1 || strings.Count(contact, "@") != 1 {
+		return "", fmt.Errorf("%s must be an email address, got %q", metaContact, contact)
+	}
+	local, domain := contact[:i], contact[i+1:]
+	for _, r := range local {
+		if r > unicode.MaxASCII {
+			return "", fmt.Errorf("%s %q: the SOA RNAME can't hold the non-ASCII local part %q; use an ASCII address", metaContact, contact, local)
+		}
+	}
+	domain, err := idna.ToASCII(domain)
+	if err != nil {
+		return "", fmt.Errorf("%s %q: %w", metaContact, contact, err)
+	}
+	return local + "@" + domain, nil
+}
+
+// getDNSSECCorrections returns corrections that update a domain's DNSSEC state.
+// AutoDNSSEC is only set if the CanAutoDNSSEC capability was enabled by the user.
+func (c *gcoreProvider) getDNSSECCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	if dc.AutoDNSSEC == "" {
+		return nil, nil
+	}
+
+	zone, err := c.zoneInfo(dc.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	uri := path.Join("/v2/zones", strings.Trim(dc.Name, "."), "dnssec")
+
+	if zone.DNSSECEnabled && dc.AutoDNSSEC == "off" {
+		return []*models.Correction{
+			{
+				Msg:      "Disable DNSSEC",
+				IDs:      []string{idDNSSEC},
+				F:        func() error { return c.setDNSSEC(dc.Name, false) },
+				Requests: []models.HTTPRequest{c.describeRequest(http.MethodPatch, uri, map[string]bool{"enabled": false})},
+			},
+		}, nil
+	}
+
+	if !zone.DNSSECEnabled && dc.AutoDNSSEC == "on" {
+		return []*models.Correction{
+			{
+				Msg:      "Enable DNSSEC",
+				IDs:      []string{idDNSSEC},
+				F:        func() error { return c.setDNSSEC(dc.Name, true) },
+				Requests: []models.HTTPRequest{c.describeRequest(http.MethodPatch, uri, map[string]bool{"enabled": true})},
+			},
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// dnssecManagedTypes are the types of the records G-Core creates when
+// it signs a zone. They are left out of the zone's records, since
+// DNSControl can't manage them. DS, CDS and CDNSKEY records are still
+// included, since they are managed by the user.
+var dnssecManagedTypes = map[string]bool{
+	"DNSKEY":     true,
+	"NSEC":       true,
+	"NSEC3":      true,
+	"NSEC3PARAM": true,
+	"RRSIG":      true,
 }
 
 // GetZoneRecords gets the records of a zone and returns them in RecordConfig format.
 func (c *gcoreProvider) GetZoneRecords(domain string) (models.Records, error) {
+	if c.readExport {
+		return c.exportedRecords(domain)
+	}
+
 	zone, err := c.provider.Zone(c.ctx, domain)
 	if err != nil {
 		return nil, err
@@ -96,21 +493,74 @@ func (c *gcoreProvider) GetZoneRecords(domain string) (models.Records, error) {
 	// We cannot directly use Zone's ShortAnswers
 	// they aren't complete for CAA & SRV
 	for _, rec := range zone.Records {
-		rrset, err := c.provider.RRSet(c.ctx, zone.Name, rec.Name, rec.Type)
+		if dnssecManagedTypes[rec.Type] {
+			continue
+		}
+		rrset, err := c.getRRSet(zone.Name, rec.Name, rec.Type)
 		if err != nil {
 			return nil, err
 		}
-		nativeRecords, err := nativeToRecords(rrset, zone.Name, rec.Name, rec.Type)
+		nativeRecords, err := nativeToRecords(rrset.RRSet, zone.Name, rec.Name, rec.Type)
 		if err != nil {
 			return nil, err
 		}
+		if rrset.Enabled != nil && !*rrset.Enabled {
+			markDisabled(nativeRecords)
+		}
+		if err := markRaw(nativeRecords, rrset.Raw); err != nil {
+			return nil, err
+		}
 		existingRecords = append(existingRecords, nativeRecords...)
 	}
 
 	return existingRecords, nil
 }
 
-// EnsureDomainExists returns an error if domain doesn't exist.
+// GetZoneMetadata returns the zone's DNSSEC state and, if DNSSEC is
+// enabled, the DNSSEC parameters reported by G-Core (such as the key
+// tag, algorithm, and NSEC3 parameters if present).
+func (c *gcoreProvider) GetZoneMetadata(domain string) (map[string]string, error) {
+	zone, err := c.zoneInfo(domain)
+	if err != nil {
+		return nil, err
+	}
+	meta := map[string]string{"gcore_dnssec": fmt.Sprint(zone.DNSSECEnabled)}
+	if zone.Contact != "" {
+		meta[metaContact] = zone.Contact
+	}
+	if !zone.DNSSECEnabled {
+		return meta, nil
+	}
+
+	info, err := c.dnssecInfo(domain)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range info {
+		switch v.(type) {
+		case string, float64, bool:
+			meta["gcore_dnssec_"+k] = fmt.Sprint(v)
+		}
+	}
+	return meta, nil
+}
+
+// ListZones returns the names of all zones in the account.
+func (c *gcoreProvider) ListZones() ([]string, error) {
+	zones, err := c.provider.Zones(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		names = append(names, zone.Name)
+	}
+	return names, nil
+}
+
+// EnsureDomainExists creates domain if it doesn't exist. A new zone
+// gets a copy of the records of templateDomain, if it is set.
 func (c *gcoreProvider) EnsureDomainExists(domain string) error {
 	zones, err := c.provider.Zones(c.ctx)
 	if err != nil {
@@ -123,8 +573,63 @@ func (c *gcoreProvider) EnsureDomainExists(domain string) error {
 		}
 	}
 
-	_, err = c.provider.CreateZone(c.ctx, domain)
-	return err
+	if _, err := c.provider.CreateZone(c.ctx, domain); err != nil {
+		return err
+	}
+	if c.templateDomain == "" {
+		return nil
+	}
+	if err := c.copyZoneRecords(c.templateDomain, domain, false); err != nil {
+		return fmt.Errorf("copy records from template %s: %w", c.templateDomain, err)
+	}
+	return nil
+}
+
+// copyZoneRecords creates the records of the zone from in the zone to,
+// except for the NS and SOA records at the apex, which belong to each zone.
+// If rewriteTargets is set, targets in the zone from are changed to the
+// same names in the zone to.
+func (c *gcoreProvider) copyZoneRecords(from, to string, rewriteTargets bool) error {
+	records, err := c.GetZoneRecords(from)
+	if err != nil {
+		return err
+	}
+	dc := &models.DomainConfig{Name: to}
+	for _, rc := range records {
+		if !isApexNSOrSOA(rc) {
+			rc.SetLabel(rc.GetLabel(), to)
+			if rewriteTargets {
+				rewriteTarget(rc, from, to)
+			}
+			dc.Records = append(dc.Records, rc)
+		}
+	}
+
+	all, err := c.GetZoneRecords(to)
+	if err != nil {
+		return err
+	}
+	var existing models.Records
+	for _, rc := range all {
+		if !isApexNSOrSOA(rc) {
+			existing = append(existing, rc)
+		}
+	}
+
+	corrections, err := c.GenerateDomainCorrections(dc, existing)
+	if err != nil {
+		return err
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isApexNSOrSOA(rc *models.RecordConfig) bool {
+	return (rc.Type == "NS" || rc.Type == "SOA") && rc.GetLabel() == "@"
 }
 
 // PrepFoundRecords munges any records to make them compatible with
@@ -136,14 +641,113 @@ func PrepFoundRecords(recs models.Records) models.Records {
 }
 
 // PrepDesiredRecords munges any records to best suit this provider.
-func PrepDesiredRecords(dc *models.DomainConfig) {
-	dc.Punycode()
+// Labels and hostname targets (such as those of CNAME and MX records)
+// are converted to A-labels, since G-Core only accepts ASCII names.
+func PrepDesiredRecords(dc *models.DomainConfig) error {
+	if err := dc.Punycode(); err != nil {
+		return fmt.Errorf("domain %s: %w", dc.Name, err)
+	}
+	return nil
 }
 
 func generateChangeMsg(updates []string) string {
 	return strings.Join(updates, "\n")
 }
 
+// maxListedChanges is the most changes to an RRset that are listed one
+// by one. An RRset with more is summarized, so that a change to a large
+// RRset doesn't print hundreds of lines.
+const maxListedChanges = 10
+
+// summarizeChanges returns msgs, the changes to the RRset key with
+// answers answers, or a summary of them followed by the first
+// maxListedChanges of them if there are more.
+func summarizeChanges(key models.RecordKey, answers int, msgs []string) []string {
+	if len(msgs) <= maxListedChanges {
+		return msgs
+	}
+	summary := make([]string, 0, maxListedChanges+2)
+	summary = append(summary, fmt.Sprintf("%s %s: %d answers, %d changed", key.Type, key.NameFQDN, answers, len(msgs)))
+	summary = append(summary, msgs[:maxListedChanges]...)
+	return append(summary, fmt.Sprintf("(and %d more)", len(msgs)-maxListedChanges))
+}
+
+// answerChangesMsg returns a message listing the answers that are in
+// desired but not existing, and the other way around, or "" if there are
+// none, such as when only the TTL changes.
+func answerChangesMsg(key models.RecordKey, existing, desired models.Records) string {
+	if len(desired) == 0 {
+		return ""
+	}
+	count := map[string]int{}
+	for _, rc := range existing {
+		count[rc.GetTargetCombined()]++
+	}
+	var added, removed []string
+	for _, rc := range desired {
+		answer := rc.GetTargetCombined()
+		if count[answer] > 0 {
+			count[answer]--
+			continue
+		}
+		added = append(added, answer)
+	}
+	for _, rc := range existing {
+		answer := rc.GetTargetCombined()
+		if count[answer] > 0 {
+			count[answer]--
+			removed = append(removed, answer)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return ""
+	}
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, "add "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, "remove "+strings.Join(removed, ", "))
+	}
+	return fmt.Sprintf("UPDATE %s %s: %s", key.Type, key.NameFQDN, strings.Join(parts, "; "))
+}
+
+// changedGroups is like differ.ChangedGroups, for the changes found by
+// IncrementalDiff, but each change ends with the reasons for it, such
+// as "MODIFY A www.example.com: (...) -> (...) [ttl]".
+func changedGroups(create, toDelete, modify diff.Changeset) map[models.RecordKey][]string {
+	changes := map[models.RecordKey][]string{}
+	for _, cs := range []diff.Changeset{create, toDelete, modify} {
+		for _, c := range cs {
+			key := c.Desired
+			if key == nil {
+				key = c.Existing
+			}
+			msg := fmt.Sprintf("%s [%s]", c, strings.Join(c.Reasons(), ","))
+			changes[key.Key()] = append(changes[key.Key()], msg)
+		}
+	}
+	return changes
+}
+
+// prepareDiff returns the records of dc and existing that are compared,
+// and the metadata compared with them.
+func (c *gcoreProvider) prepareDiff(dc *models.DomainConfig, existing models.Records) (*models.DomainConfig, models.Records, func(*models.RecordConfig) map[string]string) {
+	// Leave out the records that are outside the managed scope, if any.
+	dc, existing = applyScope(dc, existing)
+	// And those that belong to other tools.
+	dc, existing = applyOwnership(dc, existing)
+	// Also leave out the records beneath delegated subdomains.
+	dc, existing = applyDelegations(dc, existing)
+	// And the records G-Core created with the zone, if they're kept.
+	if c.preserveDefaults {
+		existing = preserveDefaults(dc, existing)
+	}
+	// Don't report the values G-Core normalized as changes.
+	existing = normalizeExisting(dc.Records, existing)
+	return dc, existing, getCompareMetadata
+}
+
 // GenerateDomainCorrections takes the desired and existing records
 // and produces a Correction list.  The correction list is simply
 // a list of functions to call to actually make the desired
@@ -153,61 +757,216 @@ func (c *gcoreProvider) GenerateDomainCorrections(dc *models.DomainConfig, exist
 
 	var corrections = []*models.Correction{}
 
+	// The RRsets at each name, including those prepareDiff leaves out.
+	existingTypes := map[string]int{}
+	for key := range existing.GroupedByKey() {
+		existingTypes[key.NameFQDN]++
+	}
+
+	dc, existing, compare := c.prepareDiff(dc, existing)
+
+	// Replace the staged changes with their phase that is due.
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	dc, stageMsgs, err := stageChanges(dc, existing, now())
+	if err != nil {
+		return nil, err
+	}
+
 	// diff existing vs. current.
-	differ := diff.New(dc)
-	keysToUpdate, err := differ.ChangedGroups(existing)
+	differ := diff.New(dc, compare)
+	unchanged, create, toDelete, modify, err := differ.IncrementalDiff(existing)
+	if err != nil {
+		return nil, err
+	}
+	c.recordDiff(dc.Name, recordedDiff{unchanged, create, toDelete, modify})
+	keysToUpdate := changedGroups(create, toDelete, modify)
+
+	desiredRecords := dc.Records.GroupedByKey()
+	existingRecords := existing.GroupedByKey()
+
+	// Enabling or disabling an RRset changes it too.
+	for key, msg := range enabledChanges(desiredRecords, existingRecords) {
+		keysToUpdate[key] = append(keysToUpdate[key], msg)
+	}
+	// So does changing its gcore_raw fields.
+	for key, msg := range rawChanges(desiredRecords, existingRecords) {
+		keysToUpdate[key] = append(keysToUpdate[key], msg)
+	}
+	for key, msg := range stageMsgs {
+		keysToUpdate[key] = append([]string{msg}, keysToUpdate[key]...)
+	}
+
+	// Skip updates and deletions of protected RRsets
+	protected, err := protectedKeys(dc)
 	if err != nil {
 		return nil, err
 	}
+	for label := range keysToUpdate {
+		if _, ok := existingRecords[label]; ok && protected[label] {
+			printer.Warnf("%s %s is protected, not changing it:\n%s\n", label.NameFQDN, label.Type, generateChangeMsg(keysToUpdate[label]))
+			delete(keysToUpdate, label)
+		}
+	}
+
 	if len(keysToUpdate) == 0 {
 		return nil, nil
 	}
 
-	desiredRecords := dc.Records.GroupedByKey()
-	existingRecords := existing.GroupedByKey()
+	// Summarize the changes to large RRsets.
+	for label, msgs := range keysToUpdate {
+		answers := len(desiredRecords[label])
+		if answers == 0 {
+			answers = len(existingRecords[label])
+		}
+		summary := summarizeChanges(label, answers, msgs)
+		// List the answers added to and removed from an updated RRset,
+		// unless the changes are summarized.
+		if _, ok := existingRecords[label]; ok && len(summary) == len(msgs) {
+			if msg := answerChangesMsg(label, existingRecords[label], desiredRecords[label]); msg != "" {
+				summary = append([]string{msg}, summary...)
+			}
+		}
+		keysToUpdate[label] = summary
+	}
 
-	// First pass: delete records to avoid coexisting of conflicting types
+	// Sort the keys so the corrections are in the same order every run
+	keys := make([]models.RecordKey, 0, len(keysToUpdate))
 	for label := range keysToUpdate {
+		keys = append(keys, label)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].NameFQDN != keys[j].NameFQDN {
+			return keys[i].NameFQDN < keys[j].NameFQDN
+		}
+		return keys[i].Type < keys[j].Type
+	})
+
+	// First pass: delete records to avoid coexisting of conflicting types.
+	// If all the RRsets at a name are deleted, delete them together. The
+	// RRsets that aren't managed count too, so they're never deleted with
+	// the rest.
+	deletedTypes := map[string][]string{}
+	for _, label := range keys {
+		if _, ok := desiredRecords[label]; !ok {
+			deletedTypes[label.NameFQDN] = append(deletedTypes[label.NameFQDN], label.Type)
+		}
+	}
+	for _, label := range keys {
 		if _, ok := desiredRecords[label]; !ok {
 			// record deleted in update
 			// Copy all params to avoid overwrites
 			zone := dc.Name
-			name := label.NameFQDN
+			name := nativeName(label)
 			typ := label.Type
+
+			if types := deletedTypes[label.NameFQDN]; len(types) > 1 && len(types) == existingTypes[label.NameFQDN] {
+				if types[0] != typ {
+					continue // already deleted with the first type
+				}
+				var msgs []string
+				var deleted []models.RecordKey
+				changes := map[models.RecordKey]models.Records{}
+				for _, t := range types {
+					key := models.RecordKey{NameFQDN: label.NameFQDN, Type: t}
+					msgs = append(msgs, keysToUpdate[key]...)
+					deleted = append(deleted, key)
+					changes[key] = nil
+				}
+				corrections = append(corrections, &models.Correction{
+					Msg: generateChangeMsg(msgs),
+					IDs: rrsetIDs(deleted...),
+					F: c.tracked(zone, changes, func() error {
+						return c.deleteName(zone, name, types)
+					}),
+					Requests: c.describeDeleteName(zone, name, types),
+				})
+				continue
+			}
+
 			msg := generateChangeMsg(keysToUpdate[label])
 			corrections = append(corrections, &models.Correction{
 				Msg: msg,
-				F: func() error {
+				IDs: rrsetIDs(label),
+				F: c.tracked(zone, map[models.RecordKey]models.Records{label: nil}, func() error {
 					return c.provider.DeleteRRSet(c.ctx, zone, name, typ)
-				},
+				}),
+				Requests: []models.HTTPRequest{c.describeRequest(http.MethodDelete, rrsetURI(zone, name, typ), nil)},
 			})
 		}
 	}
 
-	// Second pass: create and update records
-	for label := range keysToUpdate {
+	// Second pass: create and update records.
+	// If more than one RRset at a name changes, change them together.
+	changedTypes := map[string][]models.RecordKey{}
+	for _, label := range keys {
+		if _, ok := desiredRecords[label]; ok {
+			changedTypes[label.NameFQDN] = append(changedTypes[label.NameFQDN], label)
+		}
+	}
+	for _, label := range keys {
 		if _, ok := desiredRecords[label]; !ok {
 			// record deleted in update
 			// do nothing here
 
+		} else if labels := changedTypes[label.NameFQDN]; len(labels) > 1 {
+			if labels[0] != label {
+				continue // already changed with the first type
+			}
+
+			// Copy all params to avoid overwrites
+			zone := dc.Name
+			name := nativeName(label)
+			var rrsets []nameRRSet
+			create := map[string]bool{}
+			changes := map[models.RecordKey]models.Records{}
+			var msgs []string
+			for _, l := range dsLast(labels) {
+				record := recordsToNative(desiredRecords[l], l)
+				if record == nil {
+					panic("No records matching label")
+				}
+				c.stamp(record)
+				rrset := rrsetBody{RRSet: *record, Enabled: enabledField(desiredRecords[l], existingRecords[l]), Raw: rawFields(desiredRecords[l])}
+				rrsets = append(rrsets, nameRRSet{Type: l.Type, rrsetBody: rrset})
+				if _, ok := existingRecords[l]; !ok {
+					create[l.Type] = true
+				}
+				msgs = append(msgs, keysToUpdate[l]...)
+				changes[l] = desiredRecords[l]
+			}
+			corrections = append(corrections, &models.Correction{
+				Msg: generateChangeMsg(msgs),
+				IDs: rrsetIDs(labels...),
+				F: c.tracked(zone, changes, func() error {
+					return c.updateName(zone, name, rrsets, create)
+				}),
+				Requests: c.describeUpdateName(zone, name, rrsets, create),
+			})
+
 		} else if _, ok := existingRecords[label]; !ok {
 			// record created in update
 			record := recordsToNative(desiredRecords[label], label)
 			if record == nil {
 				panic("No records matching label")
 			}
+			c.stamp(record)
 
 			// Copy all params to avoid overwrites
 			zone := dc.Name
-			name := label.NameFQDN
+			name := nativeName(label)
 			typ := label.Type
-			rec := *record
+			rec := rrsetBody{RRSet: *record, Enabled: enabledField(desiredRecords[label], existingRecords[label]), Raw: rawFields(desiredRecords[label])}
 			msg := generateChangeMsg(keysToUpdate[label])
 			corrections = append(corrections, &models.Correction{
 				Msg: msg,
-				F: func() error {
-					return c.provider.CreateRRSet(c.ctx, zone, name, typ, rec)
-				},
+				IDs: rrsetIDs(label),
+				F: c.tracked(zone, map[models.RecordKey]models.Records{label: desiredRecords[label]}, func() error {
+					return c.upsertRRSet(zone, name, typ, rec, true)
+				}),
+				Requests: []models.HTTPRequest{c.describeRequest(http.MethodPost, rrsetURI(zone, name, typ), rec)},
 			})
 
 		} else {
@@ -216,18 +975,21 @@ func (c *gcoreProvider) GenerateDomainCorrections(dc *models.DomainConfig, exist
 			if record == nil {
 				panic("No records matching label")
 			}
+			c.stamp(record)
 
 			// Copy all params to avoid overwrites
 			zone := dc.Name
-			name := label.NameFQDN
+			name := nativeName(label)
 			typ := label.Type
-			rec := *record
+			rec := rrsetBody{RRSet: *record, Enabled: enabledField(desiredRecords[label], existingRecords[label]), Raw: rawFields(desiredRecords[label])}
 			msg := generateChangeMsg(keysToUpdate[label])
 			corrections = append(corrections, &models.Correction{
 				Msg: msg,
-				F: func() error {
-					return c.provider.UpdateRRSet(c.ctx, zone, name, typ, rec)
-				},
+				IDs: rrsetIDs(label),
+				F: c.tracked(zone, map[models.RecordKey]models.Records{label: desiredRecords[label]}, func() error {
+					return c.upsertRRSet(zone, name, typ, rec, false)
+				}),
+				Requests: []models.HTTPRequest{c.describeRequest(http.MethodPut, rrsetURI(zone, name, typ), rec)},
 			})
 		}
 	}
@@ -0,0 +1,1191 @@
+package gcore
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/providers"
+)
+
+func TestListZonesAndGetZoneRecords(t *testing.T) {
+	api := newFakeAPI()
+	for _, zone := range []string{"example.com", "example.net", "example.org"} {
+		api.addZone(zone)
+		api.addRRSet(zone, "www."+zone, "A", 300, "192.0.2.1")
+	}
+	api.failRequest("GET /v2/zones/example.net/www.example.net/A", http.StatusInternalServerError)
+	c := newTestProvider(t, api)
+
+	zones, err := c.ListZones()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := strings.Join(zones, ","), "example.com,example.net,example.org"; got != exp {
+		t.Fatalf("ListZones returned %s, expected %s", got, exp)
+	}
+
+	for _, zone := range zones {
+		recs, err := c.GetZoneRecords(zone)
+		if zone == "example.net" {
+			if err == nil {
+				t.Errorf("%s: expected error, got none", zone)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", zone, err)
+			continue
+		}
+		if len(recs) != 1 || recs[0].GetLabel() != "www" || recs[0].GetTargetField() != "192.0.2.1" {
+			t.Errorf("%s: unexpected records %v", zone, recs)
+		}
+	}
+}
+
+func TestDNSSECManagedRecords(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com").DNSSECEnabled = true
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1")
+	api.addRRSet("example.com", "www.example.com", "RRSIG", 300, "A 13 3 300 20261101000000 20261015000000 12345 example.com. c2ln")
+	api.addRRSet("example.com", "www.example.com", "NSEC", 300, "example.com. A RRSIG NSEC")
+	api.addRRSet("example.com", "example.com", "DNSKEY", 300, "257 3 13 a2V5")
+	api.addRRSet("example.com", "sub.example.com", "DS", 300, "2371 13 2 1f987cc6583e92df0890718c42c5f6d4ed4c4e2c6d9e2f2f4e7c1b5bc1a2e0f3")
+	c := newTestProvider(t, api)
+
+	recs, err := c.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rc := range recs {
+		got = append(got, rc.GetLabel()+" "+rc.Type)
+	}
+	sort.Strings(got)
+	if exp := "sub DS,www A"; strings.Join(got, ",") != exp {
+		t.Errorf("got records %q, expected %q", strings.Join(got, ","), exp)
+	}
+
+	www := makeRC("www", "A", "192.0.2.1")
+	ds := &models.RecordConfig{Type: "DS", TTL: 300}
+	ds.SetLabel("sub", "example.com")
+	if err := ds.SetTargetDS(2371, 13, 2, "1f987cc6583e92df0890718c42c5f6d4ed4c4e2c6d9e2f2f4e7c1b5bc1a2e0f3"); err != nil {
+		t.Fatal(err)
+	}
+	corrections, err := (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(&models.DomainConfig{Name: "example.com", Records: models.Records{www, ds}}, recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		t.Errorf("unexpected correction: %s", correction.Msg)
+	}
+}
+
+func TestTypeChangeCorrections(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1")
+	api.addRRSet("example.com", "mail.example.com", "A", 300, "192.0.2.2")
+	c := newTestProvider(t, api)
+
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "CNAME", "target.example.net."),
+		makeRC("mail", "A", "192.0.2.2"),
+	}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 2 {
+		t.Fatalf("expected 2 corrections, got %d", len(corrections))
+	}
+
+	// The A RRset must be gone before the CNAME can be created.
+	if !strings.Contains(corrections[0].Msg, "DELETE") || !strings.Contains(corrections[1].Msg, "CREATE") {
+		t.Errorf("expected a delete then a create, got:\n%s\n%s", corrections[0].Msg, corrections[1].Msg)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for req, exp := range map[string]int{
+		"DELETE /v2/zones/example.com/www.example.com/A":     1,
+		"POST /v2/zones/example.com/www.example.com/CNAME":   1,
+		"PUT /v2/zones/example.com/":                         0,
+		"DELETE /v2/zones/example.com/mail.example.com/A":    0,
+		"DELETE /v2/zones/example.com/www.example.com/CNAME": 0,
+	} {
+		if n := api.countRequests(req); n != exp {
+			t.Errorf("got %d %s requests, expected %d", n, req, exp)
+		}
+	}
+	if got := strings.Join(api.requests, "\n"); strings.Index(got, "DELETE") > strings.Index(got, "POST") {
+		t.Errorf("expected the delete before the create, got:\n%s", got)
+	}
+
+	recs, err := c.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range recs {
+		if rec.GetLabel() == "www" && (rec.Type != "CNAME" || rec.GetTargetField() != "target.example.net.") {
+			t.Errorf("unexpected record at www: %s %s", rec.Type, rec.GetTargetField())
+		}
+	}
+}
+
+func TestRRSetNames(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	c := newTestProvider(t, api)
+
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "A", "192.0.2.1"),
+		makeRC("@", "A", "192.0.2.2"),
+	}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for req, exp := range map[string]int{
+		"POST /v2/zones/example.com/www.example.com/A":             1,
+		"POST /v2/zones/example.com/example.com/A":                 1,
+		"POST /v2/zones/example.com/www.example.com.example.com/A": 0,
+		"POST /v2/zones/example.com/www/A":                         0,
+	} {
+		if n := api.countRequests(req); n != exp {
+			t.Errorf("got %d %s requests, expected %d", n, req, exp)
+		}
+	}
+
+	// Reading the zone back must give the same labels.
+	recs, err := c.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	for _, rec := range recs {
+		labels = append(labels, rec.GetLabel())
+	}
+	sort.Strings(labels)
+	if got := strings.Join(labels, ","); got != "@,www" {
+		t.Errorf("got labels %s, expected @,www", got)
+	}
+}
+
+func TestZoneEnabledCorrections(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com").Disabled = true
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1")
+	c := newTestProvider(t, api)
+
+	for _, tc := range []struct {
+		meta string
+		msg  string
+	}{
+		{"", ""},
+		{"false", ""},
+		{"true", "Enable zone"},
+		{"true", ""},
+		{"false", "Disable zone"},
+	} {
+		dc := &models.DomainConfig{
+			Name:     "example.com",
+			Metadata: map[string]string{metaEnabled: tc.meta},
+			Records:  models.Records{makeRC("www", "A", "192.0.2.1")},
+		}
+		corrections, err := c.GetDomainCorrections(dc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.msg == "" {
+			if len(corrections) != 0 {
+				t.Errorf("%s=%q: expected no corrections, got %d", metaEnabled, tc.meta, len(corrections))
+			}
+			continue
+		}
+		if len(corrections) != 1 || corrections[0].Msg != tc.msg {
+			t.Fatalf("%s=%q: expected a %q correction, got %v", metaEnabled, tc.meta, tc.msg, corrections)
+		}
+		if err := corrections[0].F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !api.zones["example.com"].Disabled {
+		t.Error("expected the zone to be disabled")
+	}
+}
+
+func TestZoneEnabledInvalid(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	c := newTestProvider(t, api)
+
+	dc := &models.DomainConfig{Name: "example.com", Metadata: map[string]string{metaEnabled: "yes"}}
+	if _, err := c.GetDomainCorrections(dc); err == nil {
+		t.Error("expected error, got none")
+	}
+}
+
+func TestContactCorrections(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com").Contact = "support@gcore.com"
+	c := newTestProvider(t, api)
+
+	dc := &models.DomainConfig{Name: "example.com", Metadata: map[string]string{metaContact: "hostmaster@example.com"}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 || corrections[0].Msg != `Change contact (SOA RNAME) from "support@gcore.com" to "hostmaster@example.com"` {
+		t.Fatalf("expected a contact correction, got %v", corrections)
+	}
+	if err := corrections[0].F(); err != nil {
+		t.Fatal(err)
+	}
+	if got := api.zones["example.com"].Contact; got != "hostmaster@example.com" {
+		t.Errorf("contact is %q, expected hostmaster@example.com", got)
+	}
+
+	meta, err := c.GetZoneMetadata("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := meta[metaContact]; got != "hostmaster@example.com" {
+		t.Errorf("got %s=%q, expected hostmaster@example.com", metaContact, got)
+	}
+	if corrections, err := c.GetDomainCorrections(dc); err != nil || len(corrections) != 0 {
+		t.Errorf("expected no corrections once the contact is set, got %v (%v)", corrections, err)
+	}
+
+	dc.Metadata[metaContact] = "hostmaster.example.com"
+	if _, err := c.GetDomainCorrections(dc); err == nil {
+		t.Error("expected an error for a contact that isn't an email address")
+	}
+}
+
+func TestContactIDN(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com").Contact = "support@gcore.com"
+	c := newTestProvider(t, api)
+
+	// The domain of the address is sent in A-labels.
+	dc := &models.DomainConfig{Name: "example.com", Metadata: map[string]string{metaContact: "hostmaster@bücher.example"}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 || corrections[0].Msg != `Change contact (SOA RNAME) from "support@gcore.com" to "hostmaster@xn--bcher-kva.example" (hostmaster@bücher.example)` {
+		t.Fatalf("expected a contact correction, got %v", corrections)
+	}
+	if got := string(corrections[0].Requests[0].Body); got != `{"contact":"hostmaster@xn--bcher-kva.example"}` {
+		t.Errorf("got request body %s, expected the A-label form", got)
+	}
+	if err := corrections[0].F(); err != nil {
+		t.Fatal(err)
+	}
+	if got := api.zones["example.com"].Contact; got != "hostmaster@xn--bcher-kva.example" {
+		t.Errorf("contact is %q, expected hostmaster@xn--bcher-kva.example", got)
+	}
+	// The A-label form G-Core has matches.
+	if corrections, err := c.GetDomainCorrections(dc); err != nil || len(corrections) != 0 {
+		t.Errorf("expected no corrections once the contact is set, got %v (%v)", corrections, err)
+	}
+
+	// The local part can't be encoded.
+	dc.Metadata[metaContact] = "hôte@example.com"
+	if _, err := c.GetDomainCorrections(dc); err == nil || !strings.Contains(err.Error(), "non-ASCII local part") {
+		t.Errorf("got error %v, expected the local part to be rejected", err)
+	}
+}
+
+func TestDeleteName(t *testing.T) {
+	for _, bulk := range []bool{true, false} {
+		api := newFakeAPI()
+		api.noBulkDelete = !bulk
+		api.addZone("example.com")
+		api.addRRSet("example.com", "old.example.com", "A", 300, "192.0.2.1")
+		api.addRRSet("example.com", "old.example.com", "AAAA", 300, "2001:db8::1")
+		api.addRRSet("example.com", "old.example.com", "TXT", 300, "hello")
+		api.addRRSet("example.com", "mixed.example.com", "A", 300, "192.0.2.1")
+		api.addRRSet("example.com", "mixed.example.com", "TXT", 300, "hello")
+		c := newTestProvider(t, api)
+
+		// All of old is removed, but only some of mixed.
+		txt := makeRC("mixed", "TXT", "")
+		txt.SetTargetTXT("hello")
+		dc := &models.DomainConfig{Name: "example.com", Records: models.Records{txt}}
+		corrections, err := c.GetDomainCorrections(dc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(corrections) != 2 {
+			t.Fatalf("bulk=%v: expected 2 corrections, got %d", bulk, len(corrections))
+		}
+		for _, correction := range corrections {
+			if err := correction.F(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		perType := 0
+		if !bulk {
+			perType = 1
+		}
+		for req, exp := range map[string]int{
+			"DELETE /v2/zones/example.com/old.example.com":       1,
+			"DELETE /v2/zones/example.com/old.example.com/A":     perType,
+			"DELETE /v2/zones/example.com/old.example.com/AAAA":  perType,
+			"DELETE /v2/zones/example.com/old.example.com/TXT":   perType,
+			"DELETE /v2/zones/example.com/mixed.example.com":     0,
+			"DELETE /v2/zones/example.com/mixed.example.com/A":   1,
+			"DELETE /v2/zones/example.com/mixed.example.com/TXT": 0,
+		} {
+			n := 0
+			for _, r := range api.requests {
+				if r == req {
+					n++
+				}
+			}
+			if n != exp {
+				t.Errorf("bulk=%v: got %d %s requests, expected %d", bulk, n, req, exp)
+			}
+		}
+
+		recs, err := c.GetZoneRecords("example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(recs) != 1 || recs[0].GetLabel() != "mixed" || recs[0].Type != "TXT" {
+			t.Errorf("bulk=%v: unexpected records left: %v", bulk, recs)
+		}
+	}
+}
+
+func TestUpdateName(t *testing.T) {
+	for _, bulk := range []bool{true, false} {
+		api := newFakeAPI()
+		api.noBulkUpdate = !bulk
+		api.addZone("example.com")
+		api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1")
+		api.addRRSet("example.com", "other.example.com", "A", 300, "192.0.2.1")
+		c := newTestProvider(t, api)
+
+		// www changes its A and gains a TXT, so they are changed together.
+		txt := makeRC("www", "TXT", "")
+		txt.SetTargetTXT("hello")
+		dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+			makeRC("www", "A", "192.0.2.2"),
+			txt,
+			makeRC("other", "A", "192.0.2.2"),
+		}}
+		corrections, err := c.GetDomainCorrections(dc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(corrections) != 2 {
+			t.Fatalf("bulk=%v: expected 2 corrections, got %d", bulk, len(corrections))
+		}
+		if msg := corrections[1].Msg; !strings.Contains(msg, "A www.example.com") || !strings.Contains(msg, "TXT www.example.com") {
+			t.Errorf("bulk=%v: expected a correction for both www RRsets, got %s", bulk, msg)
+		}
+		for _, correction := range corrections {
+			if err := correction.F(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		perType := 0
+		if !bulk {
+			perType = 1
+		}
+		for req, exp := range map[string]int{
+			"PUT /v2/zones/example.com/www.example.com":      1,
+			"PUT /v2/zones/example.com/www.example.com/A":    perType,
+			"POST /v2/zones/example.com/www.example.com/TXT": perType,
+			"PUT /v2/zones/example.com/other.example.com":    0,
+			"PUT /v2/zones/example.com/other.example.com/A":  1,
+		} {
+			n := 0
+			for _, r := range api.requests {
+				if r == req {
+					n++
+				}
+			}
+			if n != exp {
+				t.Errorf("bulk=%v: got %d %s requests, expected %d", bulk, n, req, exp)
+			}
+		}
+
+		recs, err := c.GetZoneRecords("example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, rc := range recs {
+			got = append(got, rc.GetLabel()+" "+rc.Type+" "+rc.GetTargetCombined())
+		}
+		sort.Strings(got)
+		if exp := "other A 192.0.2.2, www A 192.0.2.2, www TXT \"hello\""; strings.Join(got, ", ") != exp {
+			t.Errorf("bulk=%v: got records %s, expected %s", bulk, strings.Join(got, ", "), exp)
+		}
+	}
+}
+
+func TestChangeReasons(t *testing.T) {
+	ttl := makeRC("ttl", "A", "192.0.2.1")
+	ttl.TTL = 600
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		ttl,
+		makeRC("target", "A", "192.0.2.2"),
+		makeRC("new", "A", "192.0.2.1"),
+	}}
+	existing := models.Records{
+		makeRC("ttl", "A", "192.0.2.1"),
+		makeRC("target", "A", "192.0.2.1"),
+	}
+	corrections, err := (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(dc, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []string
+	for _, correction := range corrections {
+		msgs = append(msgs, correction.Msg)
+	}
+	got := strings.Join(msgs, "\n")
+	for _, exp := range []string{
+		"CREATE A new.example.com 192.0.2.1 ttl=300 [added]",
+		"MODIFY A target.example.com: (192.0.2.1 ttl=300) -> (192.0.2.2 ttl=300) [target]",
+		"MODIFY A ttl.example.com: (192.0.2.1 ttl=300) -> (192.0.2.1 ttl=600) [ttl]",
+	} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("expected %q in corrections:\n%s", exp, got)
+		}
+	}
+}
+
+func TestAnswerChanges(t *testing.T) {
+	ttl := makeRC("ttl", "A", "192.0.2.1")
+	ttl.TTL = 600
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("pool", "A", "192.0.2.1"),
+		makeRC("pool", "A", "192.0.2.3"),
+		makeRC("pool", "A", "192.0.2.4"),
+		ttl,
+	}}
+	existing := models.Records{
+		makeRC("pool", "A", "192.0.2.1"),
+		makeRC("pool", "A", "192.0.2.2"),
+		makeRC("pool", "A", "192.0.2.3"),
+		makeRC("ttl", "A", "192.0.2.1"),
+	}
+	corrections, err := (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(dc, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 2 {
+		t.Fatalf("got %d corrections, expected 2", len(corrections))
+	}
+	lines := strings.Split(corrections[0].Msg, "\n")
+	if exp := "UPDATE A pool.example.com: add 192.0.2.4; remove 192.0.2.2"; lines[0] != exp {
+		t.Errorf("got first line %q, expected %q", lines[0], exp)
+	}
+	// Only the TTL changes, so no answers are added or removed.
+	if strings.Contains(corrections[1].Msg, "UPDATE") {
+		t.Errorf("expected no answer changes in %q", corrections[1].Msg)
+	}
+}
+
+func TestCheckAuth(t *testing.T) {
+	api := newFakeAPI()
+	c := newTestProvider(t, api)
+	if err := c.checkAuth(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	api.failRequest("GET /v2/zones", http.StatusUnauthorized)
+	err := c.checkAuth()
+	if err == nil || !strings.Contains(err.Error(), "rejected the API key") {
+		t.Errorf("expected an invalid key error, got %v", err)
+	}
+}
+
+func TestDiscoverCapabilities(t *testing.T) {
+	api := newFakeAPI()
+	c := newTestProvider(t, api)
+
+	// Disabled unless discover-capabilities is set.
+	if caps, err := c.DiscoverCapabilities(); err != nil || caps != nil {
+		t.Fatalf("expected nothing, got %v, %v", caps, err)
+	}
+	if n := len(api.requests); n != 0 {
+		t.Errorf("expected no requests, got %d", n)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		status int
+		exp    string
+	}{
+		{"available", 0, "CanAutoDNSSEC=true"},
+		{"unavailable", http.StatusForbidden, "CanAutoDNSSEC=false"},
+	} {
+		api := newFakeAPI()
+		api.addZone("example.com")
+		if tc.status != 0 {
+			api.failRequest("GET /v2/zones/example.com/dnssec", tc.status)
+		}
+		c := newTestProvider(t, api)
+		c.discover = true
+
+		for i := 0; i < 2; i++ {
+			caps, err := c.DiscoverCapabilities()
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			if got := providers.FormatCapabilityOverrides(caps); got != tc.exp {
+				t.Errorf("%s: got %q, expected %q", tc.name, got, tc.exp)
+			}
+		}
+		if n := api.countRequests("GET /v2/zones/example.com/dnssec"); n != 1 {
+			t.Errorf("%s: expected the result to be cached, got %d requests", tc.name, n)
+		}
+	}
+
+	// Other errors fall back to the static capabilities.
+	api = newFakeAPI()
+	api.addZone("example.com")
+	api.failRequest("GET /v2/zones/example.com/dnssec", http.StatusInternalServerError)
+	c = newTestProvider(t, api)
+	c.discover = true
+	if _, err := c.DiscoverCapabilities(); err == nil {
+		t.Error("expected error, got none")
+	}
+}
+
+func TestGetZoneMetadata(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	z := api.addZone("example.net")
+	z.DNSSECEnabled = true
+	z.DNSSECInfo = map[string]interface{}{
+		"key_tag":          12345,
+		"nsec3_iterations": 0,
+		"nsec3_salt":       "",
+		"nsec3_flags":      1,
+		"ds":               []string{"not a scalar"},
+	}
+	c := newTestProvider(t, api)
+
+	meta, err := c.GetZoneMetadata("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := formatMeta(meta); got != "gcore_dnssec=false" {
+		t.Errorf("got %s, expected gcore_dnssec=false", got)
+	}
+
+	meta, err = c.GetZoneMetadata("example.net")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "gcore_dnssec=true,gcore_dnssec_enabled=true,gcore_dnssec_key_tag=12345,gcore_dnssec_nsec3_flags=1,gcore_dnssec_nsec3_iterations=0,gcore_dnssec_nsec3_salt="
+	if got := formatMeta(meta); got != exp {
+		t.Errorf("got %s, expected %s", got, exp)
+	}
+}
+
+// formatMeta returns meta as sorted k=v pairs.
+func formatMeta(meta map[string]string) string {
+	var items []string
+	for k, v := range meta {
+		items = append(items, k+"="+v)
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+func TestRRSetOrder(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	// G-Core may return the answers of an RRset in any order.
+	api.addRRSet("example.com", "example.com", "MX", 300, "20 mx2.example.com.", "10 mx1.example.com.")
+	api.addRRSet("example.com", "_sip._tcp.example.com", "SRV", 300, "10 20 5060 sip2.example.com.", "10 10 5060 sip1.example.com.")
+	c := newTestProvider(t, api)
+
+	mx := func(pref uint16, target string) *models.RecordConfig {
+		rc := makeRC("@", "MX", "")
+		rc.SetTargetMX(pref, target)
+		return rc
+	}
+	srv := func(weight uint16, target string) *models.RecordConfig {
+		rc := makeRC("_sip._tcp", "SRV", "")
+		rc.SetTargetSRV(10, weight, 5060, target)
+		return rc
+	}
+
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		mx(10, "mx1.example.com."),
+		mx(20, "mx2.example.com."),
+		srv(10, "sip1.example.com."),
+		srv(20, "sip2.example.com."),
+	}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Fatalf("expected no corrections for reordered answers, got %d:\n%s", len(corrections), corrections[0].Msg)
+	}
+
+	// When the RRset is changed, the answers are written in the declared order.
+	dc.Records = append(dc.Records, mx(5, "mx0.example.com."))
+	corrections, err = c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 {
+		t.Fatalf("expected 1 correction, got %d", len(corrections))
+	}
+	if err := corrections[0].F(); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rr := range api.zones["example.com"].RRSets[fakeRRSetKey{"example.com", "MX"}].Records {
+		got = append(got, rr.ContentToString())
+	}
+	if got, exp := strings.Join(got, ","), "10 mx1.example.com.,20 mx2.example.com.,5 mx0.example.com."; got != exp {
+		t.Errorf("got answers %s, expected %s", got, exp)
+	}
+}
+
+func TestCNAMEFlattening(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "www.example.com", "CNAME", 300, "target.example.net.")
+	c := newTestProvider(t, api)
+
+	flattened := func() interface{} {
+		rrset := api.zones["example.com"].RRSets[fakeRRSetKey{"www.example.com", "CNAME"}]
+		return rrset.Records[0].Meta[answerMetaFlatten]
+	}
+
+	for _, tc := range []struct {
+		meta string
+		exp  interface{}
+	}{
+		{"true", true},
+		{"true", true}, // unchanged
+		{"false", nil},
+		{"", nil}, // unchanged, since unset means not flattened
+	} {
+		rc := makeRC("www", "CNAME", "target.example.net.")
+		if tc.meta != "" {
+			rc.Metadata = map[string]string{metaCNAMEFlatten: tc.meta}
+		}
+		before := flattened()
+		dc := &models.DomainConfig{Name: "example.com", Records: models.Records{rc}}
+		corrections, err := c.GetDomainCorrections(dc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		changes := 0
+		if before != tc.exp {
+			changes = 1
+		}
+		if len(corrections) != changes {
+			t.Fatalf("%s=%q: expected %d corrections, got %d", metaCNAMEFlatten, tc.meta, changes, len(corrections))
+		}
+		for _, correction := range corrections {
+			if !strings.Contains(correction.Msg, "[meta]") {
+				t.Errorf("%s=%q: expected a metadata change, got %s", metaCNAMEFlatten, tc.meta, correction.Msg)
+			}
+			if err := correction.F(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := flattened(); got != tc.exp {
+			t.Errorf("%s=%q: got %s %v, expected %v", metaCNAMEFlatten, tc.meta, answerMetaFlatten, got, tc.exp)
+		}
+	}
+}
+
+func TestCorrectionRequests(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "old.example.com", "A", 300, "192.0.2.9")
+	c := newTestProvider(t, api)
+
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "A", "192.0.2.1"),
+	}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 2 {
+		t.Fatalf("expected 2 corrections, got %d", len(corrections))
+	}
+
+	base := c.provider.BaseURL.String()
+	for i, exp := range []struct {
+		method, url, body string
+	}{
+		{http.MethodDelete, base + "/v2/zones/example.com/old.example.com/A", ""},
+		{http.MethodPost, base + "/v2/zones/example.com/www.example.com/A", `{"ttl":300,"resource_records":[{"content":["192.0.2.1"],"meta":null,"enabled":true}],"filters":null}`},
+	} {
+		reqs := corrections[i].Requests
+		if len(reqs) != 1 {
+			t.Fatalf("#%d: expected 1 request, got %d", i+1, len(reqs))
+		}
+		req := reqs[0]
+		if req.Method != exp.method || req.URL != exp.url || string(req.Body) != exp.body {
+			t.Errorf("#%d: got %s %s %s, expected %s %s %s", i+1, req.Method, req.URL, req.Body, exp.method, exp.url, exp.body)
+		}
+		if req.Header["Authorization"] != "APIKey $GCORE_API_KEY" {
+			t.Errorf("#%d: got Authorization %q, expected the API key to be left as a variable", i+1, req.Header["Authorization"])
+		}
+	}
+}
+
+func TestTTLOnlyChange(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1", "192.0.2.2")
+	c := newTestProvider(t, api)
+
+	withTTL := func(rc *models.RecordConfig) *models.RecordConfig {
+		rc.TTL = 3600
+		return rc
+	}
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		withTTL(makeRC("www", "A", "192.0.2.1")),
+		withTTL(makeRC("www", "A", "192.0.2.2")),
+	}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 {
+		t.Fatalf("expected 1 correction, got %d", len(corrections))
+	}
+	reqs := corrections[0].Requests
+	if len(reqs) != 1 || reqs[0].Method != http.MethodPut || !strings.Contains(string(reqs[0].Body), `"ttl":3600`) {
+		t.Errorf("expected an update with the new TTL, got %+v", reqs)
+	}
+	if err := corrections[0].F(); err != nil {
+		t.Fatal(err)
+	}
+	rrset := api.zones["example.com"].RRSets[fakeRRSetKey{"www.example.com", "A"}]
+	if rrset.TTL != 3600 || len(rrset.Records) != 2 {
+		t.Errorf("got %+v, expected the 2 answers with TTL 3600", rrset)
+	}
+}
+
+func TestRRSetBatching(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	var old []string
+	for i := 1; i <= 10; i++ {
+		old = append(old, fmt.Sprintf("192.0.2.%d", i))
+	}
+	api.addRRSet("example.com", "pool.example.com", "A", 300, old...)
+	c := newTestProvider(t, api)
+
+	// www is created with 10 answers, and one of pool's 10 answers changes.
+	var recs models.Records
+	for i := 1; i <= 10; i++ {
+		recs = append(recs, makeRC("www", "A", fmt.Sprintf("198.51.100.%d", i)))
+		if i != 10 {
+			recs = append(recs, makeRC("pool", "A", fmt.Sprintf("192.0.2.%d", i)))
+		}
+	}
+	recs = append(recs, makeRC("pool", "A", "192.0.2.11"))
+	corrections, err := c.GetDomainCorrections(&models.DomainConfig{Name: "example.com", Records: recs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := len(api.requests)
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exp := []string{
+		"PUT /v2/zones/example.com/pool.example.com/A",
+		"POST /v2/zones/example.com/www.example.com/A",
+	}
+	if got := api.requests[n:]; strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		t.Errorf("got requests:\n%s\nexpected each RRset to be written in one request:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+	for name, count := range map[string]int{"www.example.com": 10, "pool.example.com": 10} {
+		if got := len(api.zones["example.com"].RRSets[fakeRRSetKey{name, "A"}].Records); got != count {
+			t.Errorf("%s has %d answers, expected %d", name, got, count)
+		}
+	}
+}
+
+func TestUpsertStaleRecords(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "old.example.com", "A", 300, "192.0.2.1")
+	c := newTestProvider(t, api)
+
+	corrections, err := c.GetDomainCorrections(&models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("new", "A", "192.0.2.2"),
+		makeRC("old", "A", "192.0.2.3"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The zone changes after it was read: new is created, and old is
+	// deleted, by someone else.
+	api.addRRSet("example.com", "new.example.com", "A", 300, "192.0.2.9")
+	delete(api.zones["example.com"].RRSets, fakeRRSetKey{"old.example.com", "A"})
+
+	n := len(api.requests)
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatalf("%s: %v", correction.Msg, err)
+		}
+	}
+	exp := []string{
+		"POST /v2/zones/example.com/new.example.com/A",
+		"PUT /v2/zones/example.com/new.example.com/A",
+		"PUT /v2/zones/example.com/old.example.com/A",
+		"POST /v2/zones/example.com/old.example.com/A",
+	}
+	if got := api.requests[n:]; strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		t.Errorf("got requests:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+	for name, target := range map[string]string{"new.example.com": "192.0.2.2", "old.example.com": "192.0.2.3"} {
+		rrset, ok := api.zones["example.com"].RRSets[fakeRRSetKey{name, "A"}]
+		if !ok || len(rrset.Records) != 1 || rrset.Records[0].ContentToString() != target {
+			t.Errorf("%s is %+v, expected %s", name, rrset, target)
+		}
+	}
+}
+
+// largeRRSet returns the desired and existing records of an RRset with
+// n answers, of which changed have a different target.
+func largeRRSet(n, changed int) (*models.DomainConfig, models.Records) {
+	dc := &models.DomainConfig{Name: "example.com"}
+	var existing models.Records
+	for i := 0; i < n; i++ {
+		ip := fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+		existing = append(existing, makeRC("pool", "A", ip))
+		if i < changed {
+			ip = fmt.Sprintf("10.1.%d.%d", i/256, i%256)
+		}
+		dc.Records = append(dc.Records, makeRC("pool", "A", ip))
+	}
+	return dc, existing
+}
+
+func TestLargeRRSetMessage(t *testing.T) {
+	dc, existing := largeRRSet(500, 120)
+	corrections, err := (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(dc, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 {
+		t.Fatalf("got %d corrections, expected 1", len(corrections))
+	}
+	lines := strings.Split(corrections[0].Msg, "\n")
+	if exp := "A pool.example.com: 500 answers, 120 changed"; lines[0] != exp {
+		t.Errorf("got first line %q, expected %q", lines[0], exp)
+	}
+	if exp := "(and 110 more)"; lines[len(lines)-1] != exp {
+		t.Errorf("got last line %q, expected %q", lines[len(lines)-1], exp)
+	}
+	if len(lines) != maxListedChanges+2 {
+		t.Errorf("got %d lines, expected %d", len(lines), maxListedChanges+2)
+	}
+}
+
+func BenchmarkLargeRRSet(b *testing.B) {
+	dc, existing := largeRRSet(500, 3)
+	c := &gcoreProvider{provider: offlineClient()}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.GenerateDomainCorrections(dc, existing); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCompareOnly(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "old.example.com", "A", 300, "192.0.2.9")
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1")
+	c := newTestProvider(t, api)
+	c.SetCompareOnly()
+
+	dc := &models.DomainConfig{
+		Name:     "example.com",
+		Metadata: map[string]string{metaContact: "hostmaster@example.com"},
+		Records: models.Records{
+			makeRC("www", "A", "192.0.2.2"),
+			makeRC("new", "A", "192.0.2.3"),
+		},
+	}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 4 {
+		t.Errorf("expected 4 corrections, got %d", len(corrections))
+	}
+	for _, correction := range corrections {
+		if correction.F != nil {
+			t.Errorf("correction %q can make changes", correction.Msg)
+		}
+	}
+	for _, req := range api.requests {
+		if !strings.HasPrefix(req, "GET ") {
+			t.Errorf("unexpected request %s", req)
+		}
+	}
+}
+
+func TestEnsureDomainExistsTemplate(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("template.com")
+	api.addRRSet("template.com", "template.com", "NS", 300, "ns1.gcorelabs.net.")
+	api.addRRSet("template.com", "template.com", "MX", 300, "10 mx.example.net.")
+	api.addRRSet("template.com", "www.template.com", "A", 600, "192.0.2.1", "192.0.2.2")
+	api.addRRSet("template.com", "_dmarc.template.com", "TXT", 300, "v=DMARC1; p=reject")
+	c := newTestProvider(t, api)
+	c.templateDomain = "template.com"
+
+	if err := c.EnsureDomainExists("new.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := c.GetZoneRecords("new.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rc := range recs {
+		got = append(got, rc.GetLabelFQDN()+" "+rc.Type+" "+rc.ToDiffable())
+	}
+	sort.Strings(got)
+	exp := []string{
+		`_dmarc.new.com TXT "v=DMARC1; p=reject" ttl=300`,
+		"new.com MX 10 mx.example.net. ttl=300",
+		"www.new.com A 192.0.2.1 ttl=600",
+		"www.new.com A 192.0.2.2 ttl=600",
+	}
+	if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+
+	// An existing zone is left alone.
+	api.addRRSet("template.com", "ftp.template.com", "A", 300, "192.0.2.3")
+	if err := c.EnsureDomainExists("new.com"); err != nil {
+		t.Fatal(err)
+	}
+	if recs, _ := c.GetZoneRecords("new.com"); len(recs) != len(exp) {
+		t.Errorf("expected the existing zone to be unchanged, got %d records", len(recs))
+	}
+}
+
+func TestIDNTargets(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	c := newTestProvider(t, api)
+
+	corrections, err := c.GetDomainCorrections(&models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "CNAME", "bücher.example.net."),
+		makeRC("@", "MX", "mail.bücher.example.net."),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatalf("%s: %v", correction.Msg, err)
+		}
+	}
+	for _, tc := range []struct{ name, typ, content string }{
+		{"www.example.com", "CNAME", "xn--bcher-kva.example.net."},
+		{"example.com", "MX", "0 mail.xn--bcher-kva.example.net."},
+	} {
+		rrset, ok := api.zones["example.com"].RRSets[fakeRRSetKey{tc.name, tc.typ}]
+		if !ok || len(rrset.Records) != 1 || rrset.Records[0].ContentToString() != tc.content {
+			t.Errorf("%s %s is %+v, expected %s", tc.name, tc.typ, rrset, tc.content)
+		}
+	}
+}
+
+func TestCAARoundTrip(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	c := newTestProvider(t, api)
+
+	caa := func(label, tag, value string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "CAA", TTL: 300}
+		rc.SetLabel(label, "example.com")
+		if err := rc.SetTargetCAA(0, tag, value); err != nil {
+			t.Fatal(err)
+		}
+		return rc
+	}
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		caa("@", "issue", "letsencrypt.org"),
+		caa("@", "iodef", "mailto:security@example.com"),
+		caa("shop", "issue", "digicert.com; cansignhttpexchanges=yes"),
+		caa("shop", "issuewild", ";"),
+	}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each label keeps its own policy.
+	recs, err := c.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rc := range recs {
+		got = append(got, rc.GetLabel()+" "+rc.GetTargetCombined())
+	}
+	sort.Strings(got)
+	exp := []string{
+		`@ 0 iodef "mailto:security@example.com"`,
+		`@ 0 issue "letsencrypt.org"`,
+		`shop 0 issue "digicert.com; cansignhttpexchanges=yes"`,
+		`shop 0 issuewild ";"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+
+	corrections, err = c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected no corrections, got %d", len(corrections))
+	}
+}
+
+func TestTXTCaseOnly(t *testing.T) {
+	existing := makeRC("@", "TXT", "")
+	existing.SetTargetTXT("google-site-verification=ABCdef123")
+	desired := makeRC("@", "TXT", "")
+	desired.SetTargetTXT("google-site-verification=abcdef123")
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{desired}}
+
+	corrections, err := (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(dc, models.Records{existing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 {
+		t.Fatalf("expected 1 correction, got %d", len(corrections))
+	}
+	if exp := "(only the case of the text differs; TXT records are case-sensitive)"; !strings.Contains(corrections[0].Msg, exp) {
+		t.Errorf("expected the message to explain the change, got %q", corrections[0].Msg)
+	}
+
+	// A change that isn't only the case isn't explained.
+	desired.SetTargetTXT("google-site-verification=xyz789")
+	corrections, err = (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(dc, models.Records{existing})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 || strings.Contains(corrections[0].Msg, "only the case") {
+		t.Errorf("expected 1 correction without the explanation, got %v", corrections)
+	}
+}
+
+// TestConcurrentDomains checks, when run with -race, that the provider
+// can be used for several domains at once, as --parallel-domains does.
+func TestConcurrentDomains(t *testing.T) {
+	api := newFakeAPI()
+	zones := []string{"a.example", "b.example", "c.example", "d.example", "e.example"}
+	for _, zone := range zones {
+		api.addZone(zone)
+		api.addRRSet(zone, "www."+zone, "A", 300, "192.0.2.1")
+		api.addRRSet(zone, "old."+zone, "A", 300, "192.0.2.2")
+		api.addRRSet(zone, "old."+zone, "TXT", 300, "old")
+	}
+	api.noBulkDelete = true // so the fallback is taken concurrently
+	c := newTestProvider(t, api)
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(zones))
+	for i, zone := range zones {
+		wg.Add(1)
+		go func(i int, zone string) {
+			defer wg.Done()
+			rc := func(label, typ, target string) *models.RecordConfig {
+				r := &models.RecordConfig{Type: typ, TTL: 300}
+				r.SetLabel(label, zone)
+				r.SetTarget(target)
+				return r
+			}
+			if err := c.EnsureDomainExists(zone); err != nil {
+				errs[i] = err
+				return
+			}
+			corrections, err := c.GetDomainCorrections(&models.DomainConfig{Name: zone, Records: models.Records{
+				rc("www", "A", "192.0.2.3"),
+				rc("new", "MX", "mail."+zone+"."),
+			}})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			for _, correction := range corrections {
+				if err := correction.F(); err != nil {
+					errs[i] = fmt.Errorf("%s: %w", correction.Msg, err)
+					return
+				}
+			}
+		}(i, zone)
+	}
+	wg.Wait()
+
+	for i, zone := range zones {
+		if errs[i] != nil {
+			t.Errorf("%s: %v", zone, errs[i])
+			continue
+		}
+		z := api.zones[zone]
+		if rrset := z.RRSets[fakeRRSetKey{"www." + zone, "A"}]; len(rrset.Records) != 1 || rrset.Records[0].ContentToString() != "192.0.2.3" {
+			t.Errorf("%s: www A is %+v", zone, rrset)
+		}
+		if _, ok := z.RRSets[fakeRRSetKey{"new." + zone, "MX"}]; !ok {
+			t.Errorf("%s: new MX was not created", zone)
+		}
+		for _, typ := range []string{"A", "TXT"} {
+			if _, ok := z.RRSets[fakeRRSetKey{"old." + zone, typ}]; ok {
+				t.Errorf("%s: old %s was not deleted", zone, typ)
+			}
+		}
+	}
+}
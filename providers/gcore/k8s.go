@@ -0,0 +1,76 @@
+package gcore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/k8simport"
+	"github.com/StackExchange/dnscontrol/v3/pkg/normalize"
+)
+
+// metaK8sSnapshot is set on a domain to the path of a JSON snapshot of
+// Kubernetes services and ingresses, whose records are added to the
+// domain's (see k8simport.ParseRecords). A relative path is relative to
+// the file that declares the domain, like DATA().
+const metaK8sSnapshot = "gcore_k8s_snapshot"
+
+// k8sSnapshotPath returns the path of the domain's metaK8sSnapshot.
+func k8sSnapshotPath(dc *models.DomainConfig) string {
+	path := dc.Metadata[metaK8sSnapshot]
+	if path == "" || filepath.IsAbs(path) || dc.Source == "" {
+		return path
+	}
+	// Source is "file:line".
+	file := dc.Source
+	if i := strings.LastIndex(file, ":"); i != -1 {
+		file = file[:i]
+	}
+	return filepath.Join(filepath.Dir(file), path)
+}
+
+// addK8sRecords adds the records of the domain's metaK8sSnapshot, if it
+// has one, to dc. Records from a previous call are replaced, so that it
+// can be called more than once with the same dc. The records are added
+// after dc is normalized, so they are checked here as normalization
+// would have, together with dc's.
+func addK8sRecords(dc *models.DomainConfig) error {
+	path := k8sSnapshotPath(dc)
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", metaK8sSnapshot, err)
+	}
+	defer f.Close()
+	imported, err := k8simport.ParseRecords(f, dc.Name)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", metaK8sSnapshot, path, err)
+	}
+
+	var recs models.Records
+	declared := map[models.RecordKey]bool{}
+	for _, rc := range dc.Records {
+		if _, ok := rc.Metadata[k8simport.MetaSource]; ok {
+			continue // added by a previous call
+		}
+		recs = append(recs, rc)
+		declared[rc.Key()] = true
+	}
+	for _, rc := range imported {
+		if declared[rc.Key()] {
+			return fmt.Errorf("%s %s is both in dnsconfig.js and %s (from %s)", rc.GetLabelFQDN(), rc.Type, path, rc.Metadata[k8simport.MetaSource])
+		}
+	}
+	merged := &models.DomainConfig{Name: dc.Name, Records: append(recs, imported...)}
+	errs := normalize.CheckCNAMEs(merged)
+	errs = append(errs, AuditRecords(merged.Records)...)
+	if len(errs) != 0 {
+		return fmt.Errorf("%s %s: %w", metaK8sSnapshot, path, errs[0])
+	}
+	dc.Records = merged.Records
+	return nil
+}
@@ -0,0 +1,95 @@
+package gcore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestK8sSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k8s.json")
+	if err := os.WriteFile(path, []byte(`{"items": [
+	  {"kind": "Service", "metadata": {"name": "web", "namespace": "default",
+	    "annotations": {"external-dns.alpha.kubernetes.io/hostname": "www.example.com,app.example.com"}},
+	   "status": {"loadBalancer": {"ingress": [{"ip": "192.0.2.1"}]}}}
+	]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "app.example.com", "A", 300, "192.0.2.9")
+	c := newTestProvider(t, api)
+	dc := &models.DomainConfig{
+		Name:     "example.com",
+		Metadata: map[string]string{metaK8sSnapshot: path},
+		Records:  models.Records{makeRC("mail", "A", "192.0.2.25")},
+	}
+
+	// The records of the snapshot are pushed along with those of
+	// dnsconfig.js.
+	for i := 0; i < 2; i++ {
+		corrections, err := c.GetDomainCorrections(dc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var msgs []string
+		for _, correction := range corrections {
+			msgs = append(msgs, correction.Msg)
+			if err := correction.F(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if i == 1 {
+			if len(msgs) != 0 {
+				t.Errorf("got corrections %q on the second push, expected none", msgs)
+			}
+			break
+		}
+		got := strings.Join(msgs, "\n")
+		for _, exp := range []string{"CREATE A mail.example.com 192.0.2.25", "CREATE A www.example.com 192.0.2.1", "app.example.com: add 192.0.2.1; remove 192.0.2.9"} {
+			if !strings.Contains(got, exp) {
+				t.Errorf("got corrections %q, expected one containing %q", got, exp)
+			}
+		}
+	}
+	if len(dc.Records) != 3 {
+		t.Errorf("got %d records, expected the snapshot's to be added only once", len(dc.Records))
+	}
+
+	// A name can't be in both.
+	dc.Records = append(dc.Records, makeRC("www", "A", "192.0.2.80"))
+	if _, err := c.GetDomainCorrections(dc); err == nil || !strings.Contains(err.Error(), "www.example.com A is both in dnsconfig.js and") {
+		t.Errorf("got error %v, expected www A to conflict", err)
+	}
+}
+
+func TestK8sSnapshotCNAME(t *testing.T) {
+	// A CNAME from the snapshot can't share a name with dnsconfig.js's records.
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "k8s.json"), []byte(`{"items": [
+	  {"kind": "Service", "metadata": {"name": "web", "namespace": "default",
+	    "annotations": {"external-dns.alpha.kubernetes.io/hostname": "www.example.com"}},
+	   "status": {"loadBalancer": {"ingress": [{"hostname": "lb.example.net"}]}}}
+	]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The path is relative to the file that declares the domain.
+	dc := &models.DomainConfig{
+		Name:     "example.com",
+		Source:   filepath.Join(dir, "dnsconfig.js") + ":3",
+		Metadata: map[string]string{metaK8sSnapshot: "k8s.json"},
+		Records:  models.Records{makeRC("www", "A", "192.0.2.1")},
+	}
+	err := addK8sRecords(dc)
+	if err == nil || !strings.Contains(err.Error(), "cannot have CNAME and A record with same name: www.example.com") {
+		t.Errorf("got error %v, expected the CNAME to conflict with the A", err)
+	}
+	if len(dc.Records) != 1 {
+		t.Errorf("got %d records, expected the snapshot's not to be added", len(dc.Records))
+	}
+}
@@ -0,0 +1,132 @@
+package gcore
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// adaptiveLimiter limits the G-Core API requests that are in flight at
+// once, and how often they are sent. When G-Core signals that the quota
+// is nearly used up (X-RateLimit-Remaining is 10% of X-RateLimit-Limit
+// or less) or rejects a request with HTTP 429, the limit is halved and
+// the interval between requests is doubled. After recoverAfter responses
+// in a row without either signal, the limit goes up by one and the
+// interval is halved, until they are back to max and 0.
+type adaptiveLimiter struct {
+	max          int           // the most requests in flight at once
+	step         time.Duration // the interval after the first signal
+	maxInterval  time.Duration // the longest interval
+	recoverAfter int           // responses without a signal before recovering
+
+	mu       sync.Mutex
+	limit    int           // requests allowed in flight now
+	interval time.Duration // the time between requests now
+	inFlight int
+	next     time.Time     // when the next request may be sent
+	calm     int           // responses in a row without a signal
+	wake     chan struct{} // closed when a request finishes
+	lowest   int           // the lowest limit so far, for Diagnostics
+}
+
+func newAdaptiveLimiter(max int) *adaptiveLimiter {
+	return &adaptiveLimiter{
+		max:          max,
+		step:         100 * time.Millisecond,
+		maxInterval:  5 * time.Second,
+		recoverAfter: 20,
+		limit:        max,
+		lowest:       max,
+		wake:         make(chan struct{}),
+	}
+}
+
+// acquire waits until a request may be sent. Each successful acquire
+// must be followed by a release.
+func (l *adaptiveLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < l.limit {
+			now := time.Now()
+			if wait := l.next.Sub(now); wait > 0 {
+				l.mu.Unlock()
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(wait):
+				}
+				continue
+			}
+			l.inFlight++
+			l.next = now.Add(l.interval)
+			l.mu.Unlock()
+			return nil
+		}
+		wake := l.wake
+		l.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wake:
+		}
+	}
+}
+
+// release records the response to a request, and lets another request
+// be sent.
+func (l *adaptiveLimiter) release(resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	if nearLimit(resp) {
+		l.calm = 0
+		if l.limit = l.limit / 2; l.limit < 1 {
+			l.limit = 1
+		}
+		if l.limit < l.lowest {
+			l.lowest = l.limit
+		}
+		if l.interval *= 2; l.interval == 0 {
+			l.interval = l.step
+		}
+		if l.interval > l.maxInterval {
+			l.interval = l.maxInterval
+		}
+	} else if resp != nil {
+		if l.calm++; l.calm >= l.recoverAfter {
+			l.calm = 0
+			if l.limit < l.max {
+				l.limit++
+			}
+			if l.interval /= 2; l.interval < l.step {
+				l.interval = 0
+			}
+		}
+	}
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// current returns the limit and interval now, and the lowest limit so
+// far.
+func (l *adaptiveLimiter) current() (limit, lowest int, interval time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit, l.lowest, l.interval
+}
+
+// nearLimit reports whether resp signals that the quota is used up or
+// nearly so.
+func nearLimit(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	limit, err1 := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	remaining, err2 := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	return err1 == nil && err2 == nil && limit > 0 && remaining*10 <= limit
+}
@@ -0,0 +1,112 @@
+package gcore
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// throttlingTransport rejects every every'th request with HTTP 429.
+type throttlingTransport struct {
+	mu    sync.Mutex
+	n     int
+	every int // 0 never rejects
+}
+
+func (t *throttlingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.n++
+	status := http.StatusOK
+	if t.every != 0 && t.n%t.every == 0 {
+		status = http.StatusTooManyRequests
+	}
+	t.mu.Unlock()
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+}
+
+func TestAdaptiveLimiter(t *testing.T) {
+	base := &throttlingTransport{}
+	l := newAdaptiveLimiter(8)
+	l.step, l.maxInterval = time.Microsecond, time.Millisecond
+	rt := &retryTransport{base: base, limiter: l}
+
+	send := func(n int) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < n/8; j++ {
+					req, _ := http.NewRequest(http.MethodGet, "https://api.example/v2/zones", nil)
+					resp, err := rt.RoundTrip(req)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					resp.Body.Close()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	// The rate of 429s rises: none, then 1 in 10, 1 in 2, and all.
+	var limits []int
+	for _, every := range []int{0, 10, 2, 1} {
+		base.mu.Lock()
+		base.every = every
+		base.mu.Unlock()
+		send(80)
+		limit, _, _ := l.current()
+		limits = append(limits, limit)
+	}
+	if limits[0] != 8 {
+		t.Errorf("the limit is %d without 429s, expected 8", limits[0])
+	}
+	for i := 1; i < len(limits); i++ {
+		if limits[i] > limits[i-1] {
+			t.Errorf("the limit rose from %d to %d as the 429s rose: %v", limits[i-1], limits[i], limits)
+		}
+	}
+	if limits[1] >= 8 || limits[len(limits)-1] != 1 {
+		t.Errorf("the limits are %v, expected them to drop to 1", limits)
+	}
+	if _, _, interval := l.current(); interval == 0 {
+		t.Error("the interval between requests wasn't raised")
+	}
+
+	// Once the 429s stop, the limit recovers.
+	base.mu.Lock()
+	base.every = 0
+	base.mu.Unlock()
+	send(8 * 8 * l.recoverAfter)
+	if limit, lowest, interval := l.current(); limit != 8 || lowest != 1 || interval != 0 {
+		t.Errorf("after recovering, got limit %d, lowest %d, interval %s; expected 8, 1, 0", limit, lowest, interval)
+	}
+}
+
+func TestNearLimit(t *testing.T) {
+	for _, tc := range []struct {
+		status           int
+		limit, remaining string
+		exp              bool
+	}{
+		{http.StatusOK, "", "", false},
+		{http.StatusOK, "100", "42", false},
+		{http.StatusOK, "100", "10", true},
+		{http.StatusOK, "100", "0", true},
+		{http.StatusTooManyRequests, "", "", true},
+	} {
+		resp := &http.Response{StatusCode: tc.status, Header: http.Header{}}
+		if tc.limit != "" {
+			resp.Header.Set("X-RateLimit-Limit", tc.limit)
+			resp.Header.Set("X-RateLimit-Remaining", tc.remaining)
+		}
+		if got := nearLimit(resp); got != tc.exp {
+			t.Errorf("nearLimit(%d, %s/%s) = %v, expected %v", tc.status, tc.remaining, tc.limit, got, tc.exp)
+		}
+	}
+}
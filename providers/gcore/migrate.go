@@ -0,0 +1,65 @@
+package gcore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// MigrateZone creates the zone to with the records of the zone from,
+// for when a zone is renamed. Targets in the zone from, such as the
+// CNAME www.from.example. or the MX mail.from.example., are changed to
+// the same names in the zone to. The zone to must not exist yet. If
+// deleteOld is set, the zone from is deleted once its records are
+// copied.
+func (c *gcoreProvider) MigrateZone(from, to string, deleteOld bool) error {
+	from, to = strings.TrimSuffix(from, "."), strings.TrimSuffix(to, ".")
+	zones, err := c.provider.Zones(c.ctx)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, zone := range zones {
+		switch zone.Name {
+		case from:
+			found = true
+		case to:
+			return fmt.Errorf("zone %s already exists", to)
+		}
+	}
+	if !found {
+		return fmt.Errorf("zone %s does not exist", from)
+	}
+
+	if _, err := c.provider.CreateZone(c.ctx, to); err != nil {
+		return fmt.Errorf("create zone %s: %w", to, err)
+	}
+	if err := c.copyZoneRecords(from, to, true); err != nil {
+		return fmt.Errorf("copy records from %s to %s: %w", from, to, err)
+	}
+	if deleteOld {
+		if err := c.provider.DeleteZone(c.ctx, from); err != nil {
+			return fmt.Errorf("delete zone %s: %w", from, err)
+		}
+	}
+	return nil
+}
+
+// rewriteTarget changes the target of rc, if it is a name in the zone
+// from, to the same name in the zone to.
+func rewriteTarget(rc *models.RecordConfig, from, to string) {
+	switch rc.Type {
+	case "CNAME", "DNAME", "HTTPS", "NS", "MX", "SRV", "SVCB", "PTR":
+	default:
+		return
+	}
+	target := rc.GetTargetField()
+	lower := strings.ToLower(target)
+	switch {
+	case lower == from+".":
+		rc.SetTarget(to + ".")
+	case strings.HasSuffix(lower, "."+from+"."):
+		rc.SetTarget(target[:len(target)-len(from)-1] + to + ".")
+	}
+}
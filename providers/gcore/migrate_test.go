@@ -0,0 +1,58 @@
+package gcore
+
+import (
+	"testing"
+)
+
+func TestMigrateZone(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("old.example")
+	api.addRRSet("old.example", "old.example", "NS", 300, "ns1.gcorelabs.net.")
+	api.addRRSet("old.example", "old.example", "A", 300, "192.0.2.1")
+	api.addRRSet("old.example", "old.example", "MX", 300, "10 mail.old.example.")
+	api.addRRSet("old.example", "www.old.example", "CNAME", 300, "old.example.")
+	api.addRRSet("old.example", "ftp.old.example", "CNAME", 300, "ftp.elsewhere.example.")
+	api.addRRSet("old.example", "_sip._tcp.old.example", "SRV", 300, "10 20 5060 sip.old.example.")
+	c := newTestProvider(t, api)
+
+	if err := c.MigrateZone("old.example", "new.example", true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := api.zones["old.example"]; ok {
+		t.Errorf("old.example was not deleted")
+	}
+	z, ok := api.zones["new.example"]
+	if !ok {
+		t.Fatal("new.example was not created")
+	}
+	for _, tc := range []struct{ name, typ, content string }{
+		{"new.example", "A", "192.0.2.1"},
+		{"new.example", "MX", "10 mail.new.example."},
+		{"www.new.example", "CNAME", "new.example."},
+		{"ftp.new.example", "CNAME", "ftp.elsewhere.example."},
+		{"_sip._tcp.new.example", "SRV", "10 20 5060 sip.new.example."},
+	} {
+		rrset, ok := z.RRSets[fakeRRSetKey{tc.name, tc.typ}]
+		if !ok || len(rrset.Records) != 1 || rrset.Records[0].ContentToString() != tc.content {
+			t.Errorf("%s %s is %+v, expected %s", tc.name, tc.typ, rrset, tc.content)
+		}
+	}
+	if rrset, ok := z.RRSets[fakeRRSetKey{"new.example", "NS"}]; ok {
+		t.Errorf("the apex NS records were copied: %+v", rrset)
+	}
+}
+
+func TestMigrateZoneExists(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("old.example")
+	api.addZone("new.example")
+	c := newTestProvider(t, api)
+
+	if err := c.MigrateZone("old.example", "new.example", true); err == nil {
+		t.Fatal("expected an error, as new.example exists")
+	}
+	if _, ok := api.zones["old.example"]; !ok {
+		t.Errorf("old.example was deleted")
+	}
+}
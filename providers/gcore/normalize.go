@@ -0,0 +1,62 @@
+package gcore
+
+// G-Core normalizes some values when they are stored, so that what it
+// returns is equivalent to, but not the same as, what DNSControl sent.
+// Without accounting for this, every preview would show these records
+// as changed. The normalizations handled are:
+//
+//   - Hostnames (the targets of CNAME, DNAME, NS, MX and SRV records) are
+//     lowercased.
+//   - The answers of an RRset are returned in another order. The diff
+//     compares answers as a set, so this needs no handling here.
+
+import (
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// hostnameTypes are the types whose targets G-Core lowercases.
+var hostnameTypes = map[string]bool{"CNAME": true, "DNAME": true, "NS": true, "MX": true, "SRV": true}
+
+// normalizeExisting returns existing with each record that is a desired
+// record after G-Core's normalizations replaced by a copy with the
+// desired value, so that the diff sees them as equal.
+func normalizeExisting(desired, existing models.Records) models.Records {
+	byKey := map[models.RecordKey]map[string]*models.RecordConfig{}
+	for _, d := range desired {
+		if !hostnameTypes[d.Type] {
+			continue
+		}
+		folded := byKey[d.Key()]
+		if folded == nil {
+			folded = map[string]*models.RecordConfig{}
+			byKey[d.Key()] = folded
+		}
+		if f := strings.ToLower(d.ToDiffable()); folded[f] == nil {
+			folded[f] = d
+		}
+	}
+	result := make(models.Records, 0, len(existing))
+	for _, rc := range existing {
+		result = append(result, normalizedRecord(rc, byKey[rc.Key()]))
+	}
+	return result
+}
+
+// normalizedRecord returns rc, or a copy with the value of the desired
+// record that it is equivalent to. folded is the desired records of rc's
+// key by their lowercased value.
+func normalizedRecord(rc *models.RecordConfig, folded map[string]*models.RecordConfig) *models.RecordConfig {
+	if !hostnameTypes[rc.Type] {
+		return rc
+	}
+	v := rc.ToDiffable()
+	d := folded[strings.ToLower(v)]
+	if d == nil || d.ToDiffable() == v {
+		return rc
+	}
+	n := *rc
+	n.SetTarget(d.GetTargetField())
+	return &n
+}
@@ -0,0 +1,40 @@
+package gcore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestNormalizedValues(t *testing.T) {
+	mx := func(label string, pref uint16, target string) *models.RecordConfig {
+		rc := makeRC(label, "MX", "")
+		rc.SetTargetMX(pref, target)
+		return rc
+	}
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "CNAME", "CDN.Example.NET."),
+		mx("@", 10, "Mail1.example.com."),
+		mx("@", 20, "mail2.example.com."),
+		makeRC("changed", "CNAME", "New.Example.NET."),
+	}}
+	existing := models.Records{
+		makeRC("www", "CNAME", "cdn.example.net."), // lowercased
+		mx("@", 20, "mail2.example.com."),          // reordered
+		mx("@", 10, "mail1.example.com."),
+		makeRC("changed", "CNAME", "old.example.net."),
+	}
+
+	corrections, err := (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(dc, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 || !strings.Contains(corrections[0].Msg, "changed.example.com") {
+		var msgs []string
+		for _, c := range corrections {
+			msgs = append(msgs, c.Msg)
+		}
+		t.Errorf("expected only changed.example.com to change, got:\n%s", strings.Join(msgs, "\n"))
+	}
+}
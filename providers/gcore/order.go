@@ -0,0 +1,137 @@
+package gcore
+
+// Some corrections must run in a certain order, which they declare with
+// their IDs and After (see models.OrderCorrections):
+//
+//   - DNSSEC: CDS and CDNSKEY records, which tell the parent zone to
+//     update its DS records, are only published once DNSSEC is enabled
+//     and G-Core has created the DNSKEY records they match. When DNSSEC
+//     is disabled, they are changed first, so that the parent can be told
+//     to remove its DS records (RFC 8078) while the zone is still signed.
+//   - Delegations: the glue (A and AAAA records at or below a delegated
+//     name) that NS records need is created or updated before the NS
+//     records, and glue that is no longer needed is only deleted after
+//     them. DS records, which are only valid at a delegation, are
+//     changed after its NS records are created, and before they are
+//     deleted. As the NS and DS records share a name, they are usually
+//     changed by the same correction, in which the DS records go last.
+
+import (
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// idDNSSEC is the ID of the correction that enables or disables DNSSEC.
+const idDNSSEC = "dnssec"
+
+// rrsetIDs returns the IDs of a correction that changes the RRsets with
+// keys.
+func rrsetIDs(keys ...models.RecordKey) []string {
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		ids[i] = "rrset " + key.NameFQDN + " " + key.Type
+	}
+	return ids
+}
+
+// parseRRSetID returns the key of the RRset of an ID from rrsetIDs.
+func parseRRSetID(id string) (models.RecordKey, bool) {
+	if !strings.HasPrefix(id, "rrset ") {
+		return models.RecordKey{}, false
+	}
+	rest := strings.TrimPrefix(id, "rrset ")
+	i := strings.LastIndexByte(rest, ' ')
+	if i < 0 {
+		return models.RecordKey{}, false
+	}
+	return models.RecordKey{NameFQDN: rest[:i], Type: rest[i+1:]}, true
+}
+
+// orderCorrections sets After on the corrections of dc that must run
+// after others.
+func orderCorrections(dc *models.DomainConfig, corrections []*models.Correction) {
+	desired := map[models.RecordKey]bool{}
+	for _, rc := range dc.Records {
+		desired[rc.Key()] = true
+	}
+	var dnssec *models.Correction
+	delegations := map[string]models.RecordKey{} // the NS RRsets changed, by name
+	changed := map[models.RecordKey]*models.Correction{}
+	for _, c := range corrections {
+		for _, id := range c.IDs {
+			if id == idDNSSEC {
+				dnssec = c
+			}
+			key, ok := parseRRSetID(id)
+			if !ok {
+				continue
+			}
+			changed[key] = c
+			if key.Type == "NS" && !strings.EqualFold(strings.TrimSuffix(key.NameFQDN, "."), dc.Name) {
+				delegations[strings.ToLower(key.NameFQDN)] = key
+			}
+		}
+	}
+
+	for key, c := range changed {
+		switch key.Type {
+		case "CDS", "CDNSKEY":
+			switch {
+			case dnssec == nil:
+			case dc.AutoDNSSEC == "on":
+				c.After = append(c.After, idDNSSEC)
+			case dc.AutoDNSSEC == "off":
+				dnssec.After = append(dnssec.After, rrsetIDs(key)...)
+			}
+		case "A", "AAAA":
+			ns, ok := delegationOf(key.NameFQDN, delegations)
+			if !ok {
+				continue
+			}
+			if desired[key] {
+				changed[ns].After = append(changed[ns].After, rrsetIDs(key)...)
+			} else {
+				c.After = append(c.After, rrsetIDs(ns)...)
+			}
+		case "DS":
+			ns, ok := delegations[strings.ToLower(key.NameFQDN)]
+			if !ok {
+				continue
+			}
+			if desired[ns] {
+				c.After = append(c.After, rrsetIDs(ns)...)
+			} else {
+				changed[ns].After = append(changed[ns].After, rrsetIDs(key)...)
+			}
+		}
+	}
+}
+
+// delegationOf returns the key of the NS RRset in delegations at name or
+// the closest name above it.
+func delegationOf(name string, delegations map[string]models.RecordKey) (models.RecordKey, bool) {
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i := range labels {
+		if key, ok := delegations[strings.Join(labels[i:], ".")]; ok {
+			return key, true
+		}
+	}
+	return models.RecordKey{}, false
+}
+
+// dsLast returns keys, the RRsets at a name, with the DS RRset, if there
+// is one, moved to the end, so that it is created after the NS RRset it
+// belongs to when the RRsets are created one at a time.
+func dsLast(keys []models.RecordKey) []models.RecordKey {
+	ordered := make([]models.RecordKey, 0, len(keys))
+	var ds []models.RecordKey
+	for _, key := range keys {
+		if key.Type == "DS" {
+			ds = append(ds, key)
+		} else {
+			ordered = append(ordered, key)
+		}
+	}
+	return append(ordered, ds...)
+}
@@ -0,0 +1,105 @@
+package gcore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// orderedMsgs returns the messages of the corrections of dc, in the
+// order they run.
+func orderedMsgs(t *testing.T, c *gcoreProvider, dc *models.DomainConfig) []string {
+	t.Helper()
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrections, err = models.OrderCorrections(corrections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, correction := range corrections {
+		msgs = append(msgs, correction.Msg)
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return msgs
+}
+
+// indexOf returns the index of the first of msgs that contains s.
+func indexOf(t *testing.T, msgs []string, s string) int {
+	t.Helper()
+	for i, msg := range msgs {
+		if strings.Contains(msg, s) {
+			return i
+		}
+	}
+	t.Fatalf("no correction contains %q: %q", s, msgs)
+	return -1
+}
+
+func TestOrderDNSSEC(t *testing.T) {
+	cds := func() *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "CDS", TTL: 300}
+		rc.SetLabel("@", "example.com")
+		if err := rc.SetTargetCDS(2371, 13, 2, "1f987cc6583e92df0890718c42c5f6d4ed4c4e2c6d9e2f2f4e7c1b5bc1a2e0f3"); err != nil {
+			t.Fatal(err)
+		}
+		return rc
+	}
+
+	// The CDS record, from which the parent updates its DS record, is
+	// published after DNSSEC is enabled, which creates the DNSKEY.
+	api := newFakeAPI()
+	api.addZone("example.com")
+	c := newTestProvider(t, api)
+	msgs := orderedMsgs(t, c, &models.DomainConfig{Name: "example.com", AutoDNSSEC: "on", Records: models.Records{cds()}})
+	if indexOf(t, msgs, "CDS") < indexOf(t, msgs, "Enable DNSSEC") {
+		t.Errorf("the CDS record is published before DNSSEC is enabled: %q", msgs)
+	}
+	if !api.zones["example.com"].DNSSECEnabled {
+		t.Error("DNSSEC wasn't enabled")
+	}
+
+	// When DNSSEC is disabled, the CDS record is changed first, while
+	// the zone is still signed.
+	api = newFakeAPI()
+	api.addZone("example.com").DNSSECEnabled = true
+	c = newTestProvider(t, api)
+	msgs = orderedMsgs(t, c, &models.DomainConfig{Name: "example.com", AutoDNSSEC: "off", Records: models.Records{cds()}})
+	if indexOf(t, msgs, "CDS") > indexOf(t, msgs, "Disable DNSSEC") {
+		t.Errorf("the CDS record is changed after DNSSEC is disabled: %q", msgs)
+	}
+}
+
+func TestOrderDelegation(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "old.example.com", "NS", 300, "ns1.old.example.com.")
+	api.addRRSet("example.com", "ns1.old.example.com", "A", 300, "192.0.2.9")
+	c := newTestProvider(t, api)
+
+	ds := &models.RecordConfig{Type: "DS", TTL: 300}
+	ds.SetLabel("sub", "example.com")
+	if err := ds.SetTargetDS(2371, 13, 2, "1f987cc6583e92df0890718c42c5f6d4ed4c4e2c6d9e2f2f4e7c1b5bc1a2e0f3"); err != nil {
+		t.Fatal(err)
+	}
+	msgs := orderedMsgs(t, c, &models.DomainConfig{Name: "example.com", Records: models.Records{
+		ds,
+		makeRC("sub", "NS", "ns1.sub.example.com."),
+		makeRC("ns1.sub", "A", "192.0.2.1"),
+	}})
+	// The glue and then the NS records are created before the DS record.
+	all := strings.Join(msgs, "\n")
+	glue, ns, dsIndex := strings.Index(all, "A ns1.sub.example.com"), strings.Index(all, "NS sub.example.com"), strings.Index(all, "DS sub.example.com")
+	if !(glue < ns && ns < dsIndex) {
+		t.Errorf("got corrections in the order %q, expected the glue, NS, then DS", msgs)
+	}
+	// The old delegation is removed before its glue.
+	if indexOf(t, msgs, "NS old.example.com") > indexOf(t, msgs, "ns1.old.example.com") {
+		t.Errorf("the glue of old.example.com is deleted before its NS records: %q", msgs)
+	}
+}
@@ -0,0 +1,92 @@
+package gcore
+
+// In a zone shared with other tools, the RRsets that belong to them are
+// left out of the diff, so that DNSControl neither changes nor deletes
+// them. An RRset belongs to another tool if its answers are marked with
+// the tool's name in their managed_by meta field, or if its label starts
+// with one of the tool's prefixes.
+
+import (
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+)
+
+// answerMetaManagedBy is the answer meta field that names the tool that
+// manages the answer.
+const answerMetaManagedBy = "managed_by"
+
+// metaManagedBy is set on records read from G-Core to the value of
+// answerMetaManagedBy, if it is set.
+const metaManagedBy = "gcore_managed_by"
+
+// metaExcludeOwners is set on a domain to a comma-separated list of the
+// managed_by names of other tools, or "*" for any name.
+const metaExcludeOwners = "gcore_exclude_owners"
+
+// metaExcludePrefixes is set on a domain to a comma-separated list of
+// the label prefixes of the records of other tools, such as "extdns-".
+const metaExcludePrefixes = "gcore_exclude_prefixes"
+
+// splitList returns the items of a comma-separated list, in lower case.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// applyOwnership returns dc and existing without the RRsets that belong
+// to other tools. dc is copied rather than changed, and desired records
+// that belong to other tools are reported as warnings.
+func applyOwnership(dc *models.DomainConfig, existing models.Records) (*models.DomainConfig, models.Records) {
+	owners, prefixes := splitList(dc.Metadata[metaExcludeOwners]), splitList(dc.Metadata[metaExcludePrefixes])
+	if len(owners) == 0 && len(prefixes) == 0 {
+		return dc, existing
+	}
+	foreign := map[models.RecordKey]string{} // the RRsets marked as another tool's, and its name
+	for _, rc := range existing {
+		managedBy := rc.Metadata[metaManagedBy]
+		if managedBy == "" {
+			continue
+		}
+		for _, owner := range owners {
+			if owner == "*" || owner == strings.ToLower(managedBy) {
+				foreign[rc.Key()] = managedBy
+			}
+		}
+	}
+	// owner returns the other tool that rc's RRset belongs to, if any.
+	owner := func(rc *models.RecordConfig) string {
+		if managedBy, ok := foreign[rc.Key()]; ok {
+			return managedBy
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(rc.GetLabel(), prefix) {
+				return "the prefix " + prefix
+			}
+		}
+		return ""
+	}
+
+	owned := *dc
+	owned.Records = nil
+	for _, rc := range dc.Records {
+		if o := owner(rc); o == "" {
+			owned.Records = append(owned.Records, rc)
+		} else {
+			printer.Warnf("%s %s belongs to %s, not managing it\n", rc.GetLabelFQDN(), rc.Type, o)
+		}
+	}
+	var ownedExisting models.Records
+	for _, rc := range existing {
+		if owner(rc) == "" {
+			ownedExisting = append(ownedExisting, rc)
+		}
+	}
+	return &owned, ownedExisting
+}
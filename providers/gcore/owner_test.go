@@ -0,0 +1,118 @@
+package gcore
+
+import (
+	"strings"
+	"testing"
+
+	dnssdk "github.com/G-Core/gcore-dns-sdk-go"
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestOwnership(t *testing.T) {
+	managedBy := func(rc *models.RecordConfig, owner string) *models.RecordConfig {
+		rc.Metadata = map[string]string{metaManagedBy: owner}
+		return rc
+	}
+	dc := &models.DomainConfig{
+		Name:     "example.com",
+		Metadata: map[string]string{metaExcludeOwners: "external-dns, Certbot", metaExcludePrefixes: "extdns-"},
+		Records: models.Records{
+			makeRC("www", "A", "192.0.2.2"),             // changed
+			makeRC("api", "A", "192.0.2.9"),             // external-dns's, not changed
+			makeRC("extdns-new", "TXT", "heritage=ext"), // excluded by prefix, not created
+		},
+	}
+	existing := models.Records{
+		makeRC("www", "A", "192.0.2.1"),
+		managedBy(makeRC("api", "A", "192.0.2.1"), "external-dns"),
+		managedBy(makeRC("_acme-challenge", "TXT", "token"), "certbot"), // not deleted
+		makeRC("extdns-api", "TXT", "heritage=ext"),                     // not deleted
+		managedBy(makeRC("old", "A", "192.0.2.1"), "terraform"),         // deleted
+		makeRC("stale", "A", "192.0.2.1"),                               // deleted
+	}
+
+	var corrections []*models.Correction
+	out := captureWarnings(t, func() {
+		var err error
+		corrections, err = (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(dc, existing)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var msgs []string
+	for _, c := range corrections {
+		msgs = append(msgs, c.Msg)
+	}
+	got := strings.Join(msgs, "\n")
+	for _, name := range []string{"www.example.com", "old.example.com", "stale.example.com"} {
+		if !strings.Contains(got, name) {
+			t.Errorf("expected a correction for %s, got:\n%s", name, got)
+		}
+	}
+	for _, name := range []string{"api.example.com", "_acme-challenge", "extdns-"} {
+		if strings.Contains(got, name) {
+			t.Errorf("unexpected correction for %s, which another tool owns:\n%s", name, got)
+		}
+	}
+	for _, exp := range []string{"api.example.com A belongs to external-dns", "extdns-new.example.com TXT belongs to the prefix extdns-"} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected a warning %q, got:\n%s", exp, out)
+		}
+	}
+	if len(dc.Records) != 3 {
+		t.Errorf("expected dc to be unchanged, got %d records", len(dc.Records))
+	}
+}
+
+func TestReadManagedBy(t *testing.T) {
+	rrset := dnssdk.RRSet{TTL: 300, Records: []dnssdk.ResourceRecord{
+		{Content: []interface{}{"192.0.2.1"}, Meta: map[string]interface{}{answerMetaManagedBy: "external-dns"}, Enabled: true},
+	}}
+	recs, err := nativeToRecords(rrset, "example.com", "api.example.com", "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0].Metadata[metaManagedBy] != "external-dns" {
+		t.Errorf("got %v, expected %s=external-dns", recs, metaManagedBy)
+	}
+}
+
+func TestOwnershipDeleteName(t *testing.T) {
+	// Deleting all of our RRsets at a name keeps the one another tool owns.
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "app.example.com", "A", 300, "192.0.2.1")
+	api.addRRSet("example.com", "app.example.com", "AAAA", 300, "2001:db8::1")
+	api.addRRSet("example.com", "app.example.com", "TXT", 300, "heritage=external-dns")
+	txt := fakeRRSetKey{"app.example.com", "TXT"}
+	api.zones["example.com"].RRSets[txt].Records[0].Meta = map[string]interface{}{answerMetaManagedBy: "external-dns"}
+	c := newTestProvider(t, api)
+
+	corrections, err := c.GetDomainCorrections(&models.DomainConfig{
+		Name:     "example.com",
+		Metadata: map[string]string{metaExcludeOwners: "external-dns"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, req := range api.requests {
+		if req == "DELETE /v2/zones/example.com/app.example.com" {
+			t.Errorf("deleted the whole name, expected only the A and AAAA RRsets:\n%s", strings.Join(api.requests, "\n"))
+		}
+	}
+	rrsets := api.zones["example.com"].RRSets
+	if _, ok := rrsets[txt]; !ok {
+		t.Errorf("external-dns's TXT was deleted")
+	}
+	for _, typ := range []string{"A", "AAAA"} {
+		if _, ok := rrsets[fakeRRSetKey{"app.example.com", typ}]; ok {
+			t.Errorf("%s wasn't deleted", typ)
+		}
+	}
+}
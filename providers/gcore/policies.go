@@ -0,0 +1,213 @@
+package gcore
+
+// DMARC and SPF policies are TXT records that receivers ignore, or treat
+// as a permanent error, if they are malformed, which is only noticed when
+// mail starts failing. Mistakes that make a policy invalid are rejected
+// by AuditRecords; ones that are valid but unwise are warnings.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// maxSPFLookups is the most DNS lookups an SPF check may need (RFC 7208
+// section 4.6.4).
+const maxSPFLookups = 10
+
+// isDMARCName reports whether rc is at a name where receivers look for
+// a DMARC policy.
+func isDMARCName(rc *models.RecordConfig) bool {
+	label := rc.GetLabel()
+	return label == "_dmarc" || strings.HasPrefix(label, "_dmarc.")
+}
+
+// isSPF reports whether txt is an SPF policy. The version is
+// case-insensitive (RFC 7208 section 4.5).
+func isSPF(txt string) bool {
+	return len(txt) >= 6 && strings.EqualFold(txt[:6], "v=spf1") && (len(txt) == 6 || txt[6] == ' ')
+}
+
+// checkDMARC checks that a TXT record at a DMARC name is a valid DMARC
+// policy (RFC 7489 section 6.3).
+func checkDMARC(rc *models.RecordConfig) error {
+	if !isDMARCName(rc) {
+		return nil
+	}
+	txt := rc.GetTargetTXTJoined()
+	tags := strings.Split(txt, ";")
+	if strings.TrimSpace(tags[0]) != "v=DMARC1" {
+		return fmt.Errorf("%s is not a valid DMARC policy: it must start with v=DMARC1, so receivers ignore it: %q", rc.GetLabelFQDN(), txt)
+	}
+	values := map[string]string{}
+	for _, tag := range tags[1:] {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(tag, "=")
+		if !ok {
+			return fmt.Errorf("%s is not a valid DMARC policy: %q isn't a tag=value pair", rc.GetLabelFQDN(), tag)
+		}
+		values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	if _, ok := values["p"]; !ok {
+		return fmt.Errorf("%s is not a valid DMARC policy: it has no p= tag; add p=none, p=quarantine or p=reject after v=DMARC1", rc.GetLabelFQDN())
+	}
+	for _, k := range []string{"p", "sp"} {
+		switch v, ok := values[k]; {
+		case !ok, v == "none", v == "quarantine", v == "reject":
+		default:
+			return fmt.Errorf("%s is not a valid DMARC policy: %s=%s must be none, quarantine or reject", rc.GetLabelFQDN(), k, v)
+		}
+	}
+	for _, k := range []string{"adkim", "aspf"} {
+		switch v, ok := values[k]; {
+		case !ok, v == "r", v == "s":
+		default:
+			return fmt.Errorf("%s is not a valid DMARC policy: %s=%s must be r or s", rc.GetLabelFQDN(), k, v)
+		}
+	}
+	if v, ok := values["pct"]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n < 0 || n > 100 {
+			return fmt.Errorf("%s is not a valid DMARC policy: pct=%s must be a whole number from 0 to 100", rc.GetLabelFQDN(), v)
+		}
+	}
+	for _, k := range []string{"rua", "ruf"} {
+		v, ok := values[k]
+		if !ok {
+			continue
+		}
+		for _, uri := range strings.Split(v, ",") {
+			if !strings.HasPrefix(strings.TrimSpace(uri), "mailto:") {
+				return fmt.Errorf("%s is not a valid DMARC policy: the %s address %q must be a mailto: URI", rc.GetLabelFQDN(), k, uri)
+			}
+		}
+	}
+	return nil
+}
+
+// spfTerm is a term of an SPF policy.
+type spfTerm struct {
+	qualifier byte   // '+', '-', '~' or '?'; '+' if there is none
+	name      string // the mechanism or modifier, in lower case
+	modifier  bool   // name=value rather than a mechanism
+}
+
+// spfMechanisms are the SPF mechanisms, and whether each needs a DNS
+// lookup (RFC 7208 section 5).
+var spfMechanisms = map[string]bool{
+	"all":     false,
+	"include": true,
+	"a":       true,
+	"mx":      true,
+	"ptr":     true,
+	"ip4":     false,
+	"ip6":     false,
+	"exists":  true,
+}
+
+// parseSPF splits an SPF policy into its terms.
+func parseSPF(txt string) ([]spfTerm, error) {
+	var terms []spfTerm
+	for _, s := range strings.Fields(txt)[1:] {
+		term := spfTerm{qualifier: '+'}
+		if k, _, ok := strings.Cut(s, "="); ok && !strings.ContainsAny(k, ":/") {
+			term.name, term.modifier = strings.ToLower(k), true
+			terms = append(terms, term)
+			continue
+		}
+		if strings.ContainsRune("+-~?", rune(s[0])) {
+			term.qualifier, s = s[0], s[1:]
+		}
+		term.name = strings.ToLower(s)
+		if i := strings.IndexAny(term.name, ":/"); i >= 0 {
+			term.name = term.name[:i]
+		}
+		if _, ok := spfMechanisms[term.name]; !ok {
+			return nil, fmt.Errorf("%q isn't an SPF mechanism", s)
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// spfLookups returns the DNS lookups the terms need themselves. Each
+// include: and redirect= needs more for the policy it names.
+func spfLookups(terms []spfTerm) int {
+	n := 0
+	for _, term := range terms {
+		if (!term.modifier && spfMechanisms[term.name]) || (term.modifier && term.name == "redirect") {
+			n++
+		}
+	}
+	return n
+}
+
+// checkSPF checks that a TXT record that is an SPF policy is valid.
+func checkSPF(rc *models.RecordConfig) error {
+	txt := rc.GetTargetTXTJoined()
+	if !isSPF(txt) {
+		return nil
+	}
+	terms, err := parseSPF(txt)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid SPF policy: %w", rc.GetLabelFQDN(), err)
+	}
+	if n := spfLookups(terms); n > maxSPFLookups {
+		return fmt.Errorf("%s has an SPF policy that needs %d DNS lookups, not counting those of its includes, more than the %d allowed (RFC 7208 section 4.6.4); replace some of its a, mx or include: mechanisms with ip4: and ip6:, such as with SPF_BUILDER", rc.GetLabelFQDN(), n, maxSPFLookups)
+	}
+	return nil
+}
+
+// checkPolicyCount returns an error for each name with more than one SPF
+// or DMARC policy, which receivers treat as having none.
+func checkPolicyCount(records []*models.RecordConfig) (errs []error) {
+	seen := map[string]bool{}
+	for _, rc := range records {
+		if rc.Type != "TXT" {
+			continue
+		}
+		var kind string
+		switch txt := rc.GetTargetTXTJoined(); {
+		case isSPF(txt):
+			kind = "SPF"
+		case isDMARCName(rc) && strings.HasPrefix(txt, "v=DMARC1"):
+			kind = "DMARC"
+		default:
+			continue
+		}
+		key := rc.GetLabelFQDN() + " " + kind
+		if seen[key] {
+			errs = append(errs, rejected(rc, fmt.Sprintf("%s has more than one %s policy, so receivers ignore all of them; merge them into one TXT record", rc.GetLabelFQDN(), kind)))
+		}
+		seen[key] = true
+	}
+	return errs
+}
+
+// policyWarnings returns warnings about SPF policies of records that are
+// valid, but allow more than was likely intended.
+func policyWarnings(records models.Records) []string {
+	var warnings []string
+	for _, rc := range records {
+		if rc.Type != "TXT" || !isSPF(rc.GetTargetTXTJoined()) {
+			continue
+		}
+		terms, err := parseSPF(rc.GetTargetTXTJoined())
+		if err != nil {
+			continue // rejected by checkSPF
+		}
+		for _, term := range terms {
+			switch {
+			case term.name == "all" && term.qualifier == '+':
+				warnings = append(warnings, fmt.Sprintf("the SPF policy of %s has all or +all, which lets any server send mail as it; use -all or ~all", rc.GetLabelFQDN()))
+			case term.name == "ptr":
+				warnings = append(warnings, fmt.Sprintf("the SPF policy of %s uses ptr, which is slow and unreliable (RFC 7208 section 5.5); use ip4:, ip6: or a instead", rc.GetLabelFQDN()))
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,95 @@
+package gcore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestAuditPolicies(t *testing.T) {
+	txt := func(label, s string) *models.RecordConfig {
+		rc := makeRC(label, "TXT", "")
+		rc.SetTargetTXT(s)
+		return rc
+	}
+	for _, tc := range []struct {
+		name    string
+		records []*models.RecordConfig
+		err     string // "" if valid
+	}{
+		{
+			name:    "DMARC",
+			records: []*models.RecordConfig{txt("_dmarc", "v=DMARC1; p=reject; sp=quarantine; pct=50; rua=mailto:dmarc@example.com")},
+		},
+		{
+			name:    "DMARC without version",
+			records: []*models.RecordConfig{txt("_dmarc", "p=reject; v=DMARC1")},
+			err:     "_dmarc.example.com is not a valid DMARC policy: it must start with v=DMARC1",
+		},
+		{
+			name:    "DMARC without policy",
+			records: []*models.RecordConfig{txt("_dmarc", "v=DMARC1; rua=mailto:dmarc@example.com")},
+			err:     "_dmarc.example.com is not a valid DMARC policy: it has no p= tag",
+		},
+		{
+			name:    "DMARC with invalid policy",
+			records: []*models.RecordConfig{txt("_dmarc.shop", "v=DMARC1; p=block")},
+			err:     "_dmarc.shop.example.com is not a valid DMARC policy: p=block must be none, quarantine or reject",
+		},
+		{
+			name:    "DMARC with invalid report address",
+			records: []*models.RecordConfig{txt("_dmarc", "v=DMARC1; p=none; rua=dmarc@example.com")},
+			err:     `the rua address "dmarc@example.com" must be a mailto: URI`,
+		},
+		{
+			name:    "SPF",
+			records: []*models.RecordConfig{txt("@", "v=spf1 ip4:192.0.2.0/24 a mx include:_spf.example.net redirect=_spf.example.com")},
+		},
+		{
+			name:    "SPF over the lookup limit",
+			records: []*models.RecordConfig{txt("@", "v=spf1 a mx include:a.example.net include:b.example.net include:c.example.net include:d.example.net include:e.example.net include:f.example.net include:g.example.net include:h.example.net exists:%{i}.example.net -all")},
+			err:     "example.com has an SPF policy that needs 11 DNS lookups, not counting those of its includes, more than the 10 allowed",
+		},
+		{
+			name:    "SPF with an unknown mechanism",
+			records: []*models.RecordConfig{txt("@", "v=spf1 ip:192.0.2.1 -all")},
+			err:     `example.com is not a valid SPF policy: "ip:192.0.2.1" isn't an SPF mechanism`,
+		},
+		{
+			name:    "two SPF policies",
+			records: []*models.RecordConfig{txt("@", "v=spf1 mx -all"), txt("@", "v=spf1 a -all")},
+			err:     "example.com has more than one SPF policy, so receivers ignore all of them",
+		},
+		{
+			name:    "other TXT",
+			records: []*models.RecordConfig{txt("@", "google-site-verification=abc"), txt("@", "v=spf1 -all")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := AuditRecords(tc.records)
+			if tc.err == "" {
+				if len(errs) != 0 {
+					t.Errorf("got errors %v, expected none", errs)
+				}
+				return
+			}
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tc.err) {
+				t.Errorf("got errors %v, expected one containing %q", errs, tc.err)
+			}
+		})
+	}
+}
+
+func TestPolicyWarnings(t *testing.T) {
+	rc := makeRC("@", "TXT", "")
+	rc.SetTargetTXT("v=spf1 ptr +all")
+	got := policyWarnings(models.Records{rc})
+	exp := []string{
+		"the SPF policy of example.com uses ptr, which is slow and unreliable (RFC 7208 section 5.5); use ip4:, ip6: or a instead",
+		"the SPF policy of example.com has all or +all, which lets any server send mail as it; use -all or ~all",
+	}
+	if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		t.Errorf("got warnings:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+}
@@ -0,0 +1,41 @@
+package gcore
+
+// Protected RRsets are never updated or deleted by DNSControl. They
+// are still created if they don't exist yet. Changes that would touch
+// them are reported as warnings instead of corrections.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// metaProtect is set to "true" on a record to protect its RRset, or
+// on a domain to a comma-separated list of "label TYPE" RRsets.
+const metaProtect = "gcore_protect"
+
+// protectedKeys returns the RRsets of dc which are protected.
+func protectedKeys(dc *models.DomainConfig) (map[models.RecordKey]bool, error) {
+	protected := map[models.RecordKey]bool{}
+	for _, rec := range dc.Records {
+		if rec.Metadata[metaProtect] == "true" {
+			protected[rec.Key()] = true
+		}
+	}
+
+	list := dc.Metadata[metaProtect]
+	if list == "" {
+		return protected, nil
+	}
+	for _, item := range strings.Split(list, ",") {
+		fields := strings.Fields(item)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s: expected \"label TYPE\", got %q", metaProtect, strings.TrimSpace(item))
+		}
+		rec := &models.RecordConfig{Type: strings.ToUpper(fields[1])}
+		rec.SetLabel(fields[0], dc.Name)
+		protected[rec.Key()] = true
+	}
+	return protected, nil
+}
@@ -0,0 +1,94 @@
+package gcore
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+)
+
+// captureWarnings returns the output printed while f runs.
+func captureWarnings(t *testing.T, f func()) string {
+	t.Helper()
+	var buf bytes.Buffer
+	orig := printer.DefaultPrinter.Writer
+	printer.DefaultPrinter.Writer = &buf
+	defer func() { printer.DefaultPrinter.Writer = orig }()
+	f()
+	return buf.String()
+}
+
+func TestProtectedRecords(t *testing.T) {
+	mail := makeRC("mail", "A", "192.0.2.10")
+	mail.Metadata = map[string]string{metaProtect: "true"}
+	dc := &models.DomainConfig{
+		Name:     "example.com",
+		Metadata: map[string]string{metaProtect: "legacy A, @ TXT"},
+		Records: models.Records{
+			mail,
+			makeRC("www", "A", "192.0.2.2"),
+		},
+	}
+	existing := models.Records{
+		makeRC("mail", "A", "192.0.2.1"),
+		makeRC("www", "A", "192.0.2.1"),
+		makeRC("legacy", "A", "192.0.2.1"),
+		makeRC("@", "TXT", "v=spf1 -all"),
+		makeRC("old", "A", "192.0.2.1"),
+	}
+
+	var corrections []*models.Correction
+	out := captureWarnings(t, func() {
+		var err error
+		corrections, err = (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(dc, existing)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var msgs []string
+	for _, c := range corrections {
+		msgs = append(msgs, c.Msg)
+	}
+	got := strings.Join(msgs, "\n")
+	for _, name := range []string{"www.example.com", "old.example.com"} {
+		if !strings.Contains(got, name) {
+			t.Errorf("expected a correction for %s, got:\n%s", name, got)
+		}
+	}
+	for _, name := range []string{"mail.example.com", "legacy.example.com", "TXT"} {
+		if strings.Contains(got, name) {
+			t.Errorf("unexpected correction for protected %s:\n%s", name, got)
+		}
+		if !strings.Contains(out, name) {
+			t.Errorf("expected a warning for protected %s, got:\n%s", name, out)
+		}
+	}
+}
+
+func TestProtectedRecordsCreated(t *testing.T) {
+	// Protection only prevents changes to RRsets that already exist.
+	rec := makeRC("mail", "A", "192.0.2.10")
+	rec.Metadata = map[string]string{metaProtect: "true"}
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{rec}}
+
+	corrections, err := (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(dc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 {
+		t.Errorf("expected 1 correction, got %d", len(corrections))
+	}
+}
+
+func TestProtectedKeysInvalid(t *testing.T) {
+	dc := &models.DomainConfig{
+		Name:     "example.com",
+		Metadata: map[string]string{metaProtect: "legacy"},
+	}
+	if _, err := protectedKeys(dc); err == nil {
+		t.Error("expected error, got none")
+	}
+}
@@ -0,0 +1,23 @@
+package gcore
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// newBaseTransport returns the transport that G-Core API requests are
+// sent with. If proxy is empty, the proxy is taken from the HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY environment variables, like any other request.
+func newBaseTransport(proxy string) (http.RoundTripper, error) {
+	if proxy == "" {
+		return http.DefaultTransport, nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid G-Core proxy %q: expected a URL like http://proxy.example.com:3128", proxy)
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyURL(u)
+	return t, nil
+}
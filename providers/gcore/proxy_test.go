@@ -0,0 +1,47 @@
+package gcore
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestProxy(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A proxied request has the absolute URL of the destination.
+		proxied = append(proxied, r.URL.Scheme+"://"+r.URL.Host+r.URL.Path)
+		api.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	p, err := NewGCore(map[string]string{"api-key": "test", "proxy": proxy.URL}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := p.(*gcoreProvider)
+	c.provider.BaseURL, _ = url.Parse("http://api.gcore.invalid")
+
+	zones, err := c.ListZones()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zones) != 1 || zones[0] != "example.com" {
+		t.Errorf("got zones %v, expected [example.com]", zones)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://api.gcore.invalid/v2/zones" {
+		t.Errorf("got proxied requests %q, expected [http://api.gcore.invalid/v2/zones]", proxied)
+	}
+}
+
+func TestProxyInvalid(t *testing.T) {
+	for _, proxy := range []string{"proxy.example.com:3128", "::"} {
+		if _, err := NewGCore(map[string]string{"api-key": "test", "proxy": proxy}, nil); err == nil {
+			t.Errorf("%q: expected an error, got none", proxy)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package gcore
+
+// For G-Core features that DNSControl doesn't model, the gcore_raw
+// metadata of an RRset's records is a JSON object whose fields are added
+// to the RRset's request body as they are. Only the fields it sets are
+// managed: they are compared with the same fields of the RRset read from
+// G-Core, and the RRset is updated if any differ. Fields it stops
+// setting are left as they are.
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// metaRaw is the JSON object of extra fields of a record's RRset.
+// Records read from G-Core have it set to the RRset's fields that
+// DNSControl doesn't model, if there are any.
+const metaRaw = "gcore_raw"
+
+// rrsetFields are the fields of an RRset that DNSControl manages itself,
+// so they can't be set with metaRaw.
+var rrsetFields = map[string]bool{
+	"name":             true,
+	"type":             true,
+	"ttl":              true,
+	"resource_records": true,
+	"filters":          true,
+	"enabled":          true,
+}
+
+// parseRaw parses the value of metaRaw.
+func parseRaw(s string) (map[string]json.RawMessage, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON object: %w", metaRaw, err)
+	}
+	for k := range raw {
+		if rrsetFields[k] {
+			return nil, fmt.Errorf("%s can't set %q, which DNSControl manages", metaRaw, k)
+		}
+	}
+	return raw, nil
+}
+
+// rawFields returns the extra fields of the RRset of recs, from the
+// metaRaw of the first of recs that has it, or nil if none do.
+func rawFields(recs models.Records) map[string]json.RawMessage {
+	for _, rc := range recs {
+		if s, ok := rc.Metadata[metaRaw]; ok {
+			raw, _ := parseRaw(s) // checked by checkRaw
+			return raw
+		}
+	}
+	return nil
+}
+
+// markRaw sets metaRaw on recs, which were read from an RRset with the
+// extra fields raw.
+func markRaw(recs []*models.RecordConfig, raw map[string]json.RawMessage) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	bs, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	for _, rc := range recs {
+		if rc.Metadata == nil {
+			rc.Metadata = map[string]string{}
+		}
+		rc.Metadata[metaRaw] = string(bs)
+	}
+	return nil
+}
+
+// rawChanges returns, for each RRset in both desired and existing whose
+// metaRaw fields differ from those of the existing RRset, a message
+// saying so.
+func rawChanges(desired, existing map[models.RecordKey]models.Records) map[models.RecordKey]string {
+	msgs := map[models.RecordKey]string{}
+	for key, recs := range desired {
+		if _, ok := existing[key]; !ok {
+			continue
+		}
+		want, have := rawFields(recs), rawFields(existing[key])
+		var changed []string
+		for k, v := range want {
+			if !sameJSON(v, have[k]) {
+				changed = append(changed, fmt.Sprintf("%s=%s", k, v))
+			}
+		}
+		if len(changed) != 0 {
+			sort.Strings(changed)
+			msgs[key] = fmt.Sprintf("RAW %s %s: %s", key.NameFQDN, key.Type, strings.Join(changed, " "))
+		}
+	}
+	return msgs
+}
+
+// sameJSON reports whether a and b are the same JSON value, regardless
+// of whitespace and the order of object keys. A missing value is only
+// the same as another missing value.
+func sameJSON(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	ba, _ := json.Marshal(va)
+	bb, _ := json.Marshal(vb)
+	return string(ba) == string(bb)
+}
+
+// checkRaw checks the value of metaRaw.
+func checkRaw(rc *models.RecordConfig) error {
+	s, ok := rc.Metadata[metaRaw]
+	if !ok {
+		return nil
+	}
+	_, err := parseRaw(s)
+	return err
+}
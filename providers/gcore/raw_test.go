@@ -0,0 +1,104 @@
+package gcore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestRawFields(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	c := newTestProvider(t, api)
+
+	// desired returns the www A RRset with the gcore_raw raw.
+	desired := func(raw string) *models.DomainConfig {
+		rc := makeRC("www", "A", "192.0.2.1")
+		rc.Metadata = map[string]string{metaRaw: raw}
+		return &models.DomainConfig{Name: "example.com", Records: models.Records{rc}}
+	}
+	// push pushes dc, checking that it has one correction with msg, if
+	// msg isn't empty, and none otherwise.
+	push := func(dc *models.DomainConfig, msg string) {
+		t.Helper()
+		existing, err := c.GetZoneRecords("example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		corrections, err := c.GenerateDomainCorrections(dc, existing)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg == "" {
+			for _, correction := range corrections {
+				t.Errorf("unexpected correction: %s", correction.Msg)
+			}
+			return
+		}
+		if len(corrections) != 1 {
+			t.Fatalf("got %d corrections, expected 1", len(corrections))
+		}
+		if !strings.Contains(corrections[0].Msg, msg) {
+			t.Errorf("got correction %q, expected it to contain %q", corrections[0].Msg, msg)
+		}
+		if err := corrections[0].F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// check checks the meta field of the RRset at G-Core.
+	check := func(exp string) {
+		t.Helper()
+		rrset, ok := api.zones["example.com"].RRSets[fakeRRSetKey{"www.example.com", "A"}]
+		if !ok {
+			t.Fatal("www A doesn't exist")
+		}
+		if got := rrset.Raw["meta"]; !sameJSON(got, []byte(exp)) {
+			t.Errorf("www A has meta %s, expected %s", got, exp)
+		}
+	}
+
+	push(desired(`{"meta": {"note": "edge"}}`), "CREATE A www.example.com")
+	check(`{"note": "edge"}`)
+
+	// The field is read back, so the same value (in another form) isn't
+	// a change.
+	push(desired(`{"meta":{"note":"edge"}}`), "")
+	existing, err := c.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(existing) != 1 || existing[0].Metadata[metaRaw] != `{"meta":{"note":"edge"}}` {
+		t.Errorf("got records %v, expected www A with %s", existing, metaRaw)
+	}
+
+	push(desired(`{"meta": {"note": "core"}}`), `RAW www.example.com A: meta={"note": "core"}`)
+	check(`{"note": "core"}`)
+
+	// Fields that gcore_raw stops setting are left as they are.
+	push(desired(`{}`), "")
+	check(`{"note": "core"}`)
+}
+
+func TestAuditRaw(t *testing.T) {
+	for raw, exp := range map[string]string{
+		`{"meta": {}}`:   "",
+		`[1]`:            "gcore_raw must be a JSON object",
+		`{"ttl": 60}`:    `gcore_raw can't set "ttl", which DNSControl manages`,
+		`{"meta": {}`:    "gcore_raw must be a JSON object",
+		`{"enabled": 1}`: `gcore_raw can't set "enabled", which DNSControl manages`,
+	} {
+		rc := makeRC("www", "A", "192.0.2.1")
+		rc.Metadata = map[string]string{metaRaw: raw}
+		errs := AuditRecords([]*models.RecordConfig{rc})
+		if exp == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: got errors %v, expected none", raw, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), exp) {
+			t.Errorf("%s: got errors %v, expected one containing %q", raw, errs, exp)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package gcore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	dnssdk "github.com/G-Core/gcore-dns-sdk-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// retryTransport retries requests that fail with a transient error. It
+// waits delay before the first retry, doubling it before each following
+// one, unless the response has a Retry-After header in seconds.
+//
+// Requests (including retries) go through limiter, if it is set, which
+// slows them down when G-Core signals that the quota is nearly used up.
+//
+// It also counts the requests, and keeps the last quota headers
+// (X-RateLimit-*) that G-Core returned, for Diagnostics. If tracer is
+// set, each request, including its retries, is a span (see tracing.go).
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+	delay   time.Duration
+	limiter *adaptiveLimiter
+	tracer  trace.Tracer
+
+	mu       sync.Mutex
+	requests int               // requests sent, including retries
+	retried  int               // requests that were retries
+	quota    map[string]string // last value of each quota header
+}
+
+// newRetryTransport returns the transport used for G-Core API requests.
+func newRetryTransport(base http.RoundTripper) *retryTransport {
+	return &retryTransport{base: base, retries: 3, delay: time.Second, limiter: newAdaptiveLimiter(8), tracer: otel.Tracer(tracerName)}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.tracer == nil {
+		return t.send(req)
+	}
+	ctx, span := t.tracer.Start(req.Context(), spanName(req), trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(spanAttributes(req)...))
+	defer span.End()
+	resp, err := t.send(req.WithContext(ctx))
+	endSpan(span, resp, err)
+	return resp, err
+}
+
+// send sends req, and retries it if it fails with a transient error.
+func (t *retryTransport) send(req *http.Request) (*http.Response, error) {
+	delay := t.delay
+	for attempt := 0; ; attempt++ {
+		if t.limiter != nil {
+			if err := t.limiter.acquire(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := t.base.RoundTrip(req)
+		if t.limiter != nil {
+			t.limiter.release(resp)
+		}
+		t.record(attempt > 0, resp)
+		if attempt == t.retries || !isTransient(req, resp, err) || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+		wait := delay
+		if resp != nil {
+			if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s >= 0 {
+				wait = time.Duration(s) * time.Second
+			}
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+		delay *= 2
+
+		// The body was consumed by the previous attempt.
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// record updates the request count and quota after a request.
+func (t *retryTransport) record(retry bool, resp *http.Response) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requests++
+	if retry {
+		t.retried++
+	}
+	if resp == nil {
+		return
+	}
+	for k, v := range resp.Header {
+		if strings.HasPrefix(k, "X-Ratelimit-") && len(v) != 0 {
+			if t.quota == nil {
+				t.quota = map[string]string{}
+			}
+			t.quota[k] = v[0]
+		}
+	}
+}
+
+// Diagnostics describes the requests made and the remaining quota.
+func (t *retryTransport) Diagnostics() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lines := []string{fmt.Sprintf("API requests: %d (%d retries)", t.requests, t.retried)}
+	if t.limiter != nil {
+		if limit, lowest, interval := t.limiter.current(); lowest < t.limiter.max {
+			lines = append(lines, fmt.Sprintf("API concurrency: slowed down to %d (of %d); now %d, %s between requests", lowest, t.limiter.max, limit, interval))
+		}
+	}
+	if len(t.quota) == 0 {
+		return append(lines, "API quota: not reported")
+	}
+	keys := make([]string, 0, len(t.quota))
+	for k := range t.quota {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s", k, t.quota[k]))
+	}
+	return lines
+}
+
+// isTransient reports whether req, which got resp and err, may succeed
+// if it is retried. HTTP 429 and 503, and a refused connection, mean
+// that the request wasn't processed, so they are always retried. After
+// another server error (5xx), a timeout or a dropped connection, the
+// request may have been processed, so only requests which can safely be
+// repeated are retried. Client errors (4xx), and other errors such as
+// an invalid certificate, will fail again, so they aren't retried.
+func isTransient(req *http.Request, resp *http.Response, err error) bool {
+	idempotent := req.Method != http.MethodPost && req.Method != http.MethodPatch
+	if err != nil {
+		if req.Context().Err() != nil {
+			return false // cancelled or timed out by the caller
+		}
+		switch {
+		case errors.Is(err, syscall.ECONNREFUSED):
+			return true
+		case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+			return idempotent
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout() && idempotent
+	}
+	switch code := resp.StatusCode; {
+	case code == http.StatusTooManyRequests, code == http.StatusServiceUnavailable:
+		return true
+	case code == http.StatusNotImplemented, code == http.StatusHTTPVersionNotSupported:
+		return false
+	case code >= 500:
+		return idempotent
+	}
+	return false
+}
+
+// describeAPIError returns e, the error of a method request to uri, with
+// whether it was retried.
+func describeAPIError(method, uri string, e dnssdk.APIError) error {
+	switch code := e.StatusCode; {
+	case code == http.StatusTooManyRequests, code >= 500:
+		return fmt.Errorf("%s %s: G-Core failed with HTTP %d (%s), which may be temporary, and was retried where safe: %w", method, uri, code, http.StatusText(code), e)
+	case code >= 400:
+		return fmt.Errorf("%s %s: G-Core rejected the request with HTTP %d (%s), which isn't retried: %w", method, uri, code, http.StatusText(code), e)
+	}
+	return e
+}
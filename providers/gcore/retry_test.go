@@ -0,0 +1,123 @@
+package gcore
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestRetryTransport(t *testing.T) {
+	const rrset = "/v2/zones/example.com/www.example.com/A"
+	for _, tc := range []struct {
+		name     string
+		existing bool // whether www exists, so it's updated rather than created
+		method   string
+		status   int
+		failures int
+		ok       bool
+		requests int
+	}{
+		{"rate limited", true, http.MethodPut, http.StatusTooManyRequests, 2, true, 3},
+		{"unavailable", false, http.MethodPost, http.StatusServiceUnavailable, 1, true, 2},
+		{"gateway timeout", true, http.MethodPut, http.StatusGatewayTimeout, 1, true, 2},
+		{"gateway timeout on create", false, http.MethodPost, http.StatusGatewayTimeout, 1, false, 1},
+		{"too many failures", true, http.MethodPut, http.StatusServiceUnavailable, 10, false, 4},
+		{"not transient", true, http.MethodPut, http.StatusBadRequest, 1, false, 1},
+		{"server error", true, http.MethodPut, http.StatusInternalServerError, 1, true, 2},
+		{"server error on create", false, http.MethodPost, http.StatusInternalServerError, 1, false, 1},
+		{"not implemented", true, http.MethodPut, http.StatusNotImplemented, 1, false, 1},
+		{"unprocessable", false, http.MethodPost, http.StatusUnprocessableEntity, 1, false, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			api := newFakeAPI()
+			api.addZone("example.com")
+			if tc.existing {
+				api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1")
+			}
+			c := newTestProvider(t, api)
+			c.provider.HTTPClient.Transport = &retryTransport{base: http.DefaultTransport, retries: 3, delay: time.Millisecond}
+
+			dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+				makeRC("www", "A", "192.0.2.2"),
+			}}
+			corrections, err := c.GetDomainCorrections(dc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(corrections) != 1 {
+				t.Fatalf("expected 1 correction, got %d", len(corrections))
+			}
+
+			api.failRequestN(tc.method+" "+rrset, tc.status, tc.failures)
+			err = corrections[0].F()
+			if tc.ok && err != nil {
+				t.Errorf("expected success, got %v", err)
+			} else if !tc.ok && err == nil {
+				t.Error("expected an error, got none")
+			} else if tc.status < 500 && err != nil && !strings.Contains(err.Error(), "which isn't retried") {
+				t.Errorf("got error %v, expected it to say that it isn't retried", err)
+			}
+			if n := api.countRequests(tc.method + " " + rrset); n != tc.requests {
+				t.Errorf("got %d requests, expected %d", n, tc.requests)
+			}
+		})
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	c := newTestProvider(t, api)
+	c.transport = &retryTransport{base: http.DefaultTransport, retries: 3, delay: time.Millisecond}
+	c.provider.HTTPClient.Transport = c.transport
+
+	if _, err := c.ListZones(); err != nil {
+		t.Fatal(err)
+	}
+	api.headers = http.Header{}
+	api.headers.Set("X-RateLimit-Limit", "100")
+	api.headers.Set("X-RateLimit-Remaining", "42")
+	api.failRequestN("GET /v2/zones", http.StatusTooManyRequests, 1)
+	if _, err := c.ListZones(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(c.Diagnostics(), "\n")
+	exp := "API requests: 3 (1 retries)\nX-Ratelimit-Limit: 100\nX-Ratelimit-Remaining: 42"
+	if got != exp {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, exp)
+	}
+}
+
+func TestIsTransientNetworkError(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, tc := range []struct {
+		name   string
+		ctx    context.Context
+		method string
+		err    error
+		exp    bool
+	}{
+		{"refused", context.Background(), http.MethodPost, &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, true},
+		{"reset", context.Background(), http.MethodPut, &net.OpError{Op: "read", Err: syscall.ECONNRESET}, true},
+		{"reset on create", context.Background(), http.MethodPost, &net.OpError{Op: "read", Err: syscall.ECONNRESET}, false},
+		{"timeout", context.Background(), http.MethodGet, &net.DNSError{Err: "timeout", IsTimeout: true}, true},
+		{"no such host", context.Background(), http.MethodGet, &net.DNSError{Err: "no such host", IsNotFound: true}, false},
+		{"bad certificate", context.Background(), http.MethodGet, errors.New("x509: certificate signed by unknown authority"), false},
+		{"cancelled", cancelled, http.MethodGet, context.Canceled, false},
+	} {
+		req, _ := http.NewRequestWithContext(tc.ctx, tc.method, "https://api.gcore.com/dns/v2/zones", nil)
+		if got := isTransient(req, nil, &url.Error{Op: tc.method, URL: req.URL.String(), Err: tc.err}); got != tc.exp {
+			t.Errorf("%s: got transient=%t, expected %t", tc.name, got, tc.exp)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package gcore
+
+// PTR records can be created automatically for A and AAAA records, in
+// the reverse zones at G-Core. The reverse zone of an address is the
+// longest zone at G-Core that contains its reverse name, so both the
+// usual /24 (and /64) reverse zones and larger ones are used.
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+	"github.com/miekg/dns"
+
+	dnssdk "github.com/G-Core/gcore-dns-sdk-go"
+)
+
+// metaPTR is set to "true" on an A or AAAA record to create (or update)
+// the PTR record of its address in the reverse zone at G-Core.
+const metaPTR = "gcore_ptr"
+
+// reversePTR is the PTR record wanted for an address.
+type reversePTR struct {
+	addr   string
+	target string // the forward name, with a trailing dot
+	ttl    uint32
+}
+
+// getReverseCorrections returns corrections that create or update the
+// PTR records of the domain's A and AAAA records that have metaPTR set.
+// Other records in the reverse zones are left as they are.
+func (c *gcoreProvider) getReverseCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	wanted := map[string]reversePTR{} // by the reverse name, without the trailing dot
+	for _, rc := range dc.Records {
+		if (rc.Type != "A" && rc.Type != "AAAA") || rc.Metadata[metaPTR] != "true" {
+			continue
+		}
+		addr := rc.GetTargetIP().String()
+		rev, err := dns.ReverseAddr(addr)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", rc.Type, rc.GetLabelFQDN(), err)
+		}
+		name := strings.TrimSuffix(rev, ".")
+		ptr := reversePTR{addr: addr, target: rc.GetLabelFQDN() + ".", ttl: rc.TTL}
+		if prev, ok := wanted[name]; ok && prev.target != ptr.target {
+			printer.Warnf("%s and %s both have the address %s and %s; its PTR record is %s\n", prev.target, ptr.target, addr, metaPTR, prev.target)
+			continue
+		}
+		wanted[name] = ptr
+	}
+	if len(wanted) == 0 {
+		return nil, nil
+	}
+
+	zones, err := c.provider.Zones(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	var zoneNames []string
+	for _, z := range zones {
+		zoneNames = append(zoneNames, strings.ToLower(strings.TrimSuffix(z.Name, ".")))
+	}
+
+	byZone := map[string][]string{}
+	for name, ptr := range wanted {
+		zone := reverseZone(name, zoneNames)
+		if zone == "" {
+			printer.Warnf("There is no reverse zone at G-Core for %s, so its PTR record (%s) can't be created\n", ptr.addr, ptr.target)
+			continue
+		}
+		byZone[zone] = append(byZone[zone], name)
+	}
+
+	var corrections []*models.Correction
+	for _, zone := range sortedKeys(byZone) {
+		existing, err := c.GetZoneRecords(zone)
+		if err != nil {
+			return nil, err
+		}
+		targets := map[string][]string{} // the existing PTR targets, by name
+		for _, rc := range existing {
+			if rc.Type == "PTR" {
+				name := strings.ToLower(rc.GetLabelFQDN())
+				targets[name] = append(targets[name], strings.ToLower(rc.GetTargetField()))
+			}
+		}
+
+		names := byZone[zone]
+		sort.Strings(names)
+		for _, name := range names {
+			ptr := wanted[name]
+			current, exists := targets[name]
+			if len(current) == 1 && current[0] == strings.ToLower(ptr.target) {
+				continue
+			}
+			zone, name, create := zone, name, !exists
+			rrset := rrsetBody{RRSet: dnssdk.RRSet{
+				TTL:     int(ptr.ttl),
+				Records: []dnssdk.ResourceRecord{{Content: []interface{}{ptr.target}, Enabled: true}},
+			}}
+			c.stamp(&rrset.RRSet)
+			rc := &models.RecordConfig{Type: "PTR", TTL: ptr.ttl}
+			rc.SetLabelFromFQDN(name, zone)
+			if err := rc.SetTarget(ptr.target); err != nil {
+				return nil, err
+			}
+			key := rc.Key()
+			method := http.MethodPut
+			if create {
+				method = http.MethodPost
+			}
+			corrections = append(corrections, &models.Correction{
+				Msg: fmt.Sprintf("PTR %s %s (for %s, in %s)", name, ptr.target, ptr.addr, zone),
+				IDs: rrsetIDs(key),
+				F: c.trackedIn(dc.Name, zone, map[models.RecordKey]models.Records{key: {rc}}, func() error {
+					return c.upsertRRSet(zone, name, "PTR", rrset, create)
+				}),
+				Requests: []models.HTTPRequest{c.describeRequest(method, rrsetURI(zone, name, "PTR"), rrset)},
+			})
+		}
+	}
+	return corrections, nil
+}
+
+// reverseZone returns the longest of zones that contains the reverse
+// name, or "" if none does.
+func reverseZone(name string, zones []string) string {
+	best := ""
+	for _, zone := range zones {
+		if (name == zone || strings.HasSuffix(name, "."+zone)) && len(zone) > len(best) {
+			best = zone
+		}
+	}
+	return best
+}
+
+// sortedKeys returns the keys of m, sorted.
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,166 @@
+package gcore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestReversePTR(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	// The /24 and /64 reverse zones, and larger ones that contain them.
+	api.addZone("0.192.in-addr.arpa")
+	api.addZone("2.0.192.in-addr.arpa")
+	api.addZone("1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa")
+	api.addZone("2.0.0.0.1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa")
+	api.addRRSet("2.0.192.in-addr.arpa", "1.2.0.192.in-addr.arpa", "PTR", 300, "old.example.com.")
+	c := newTestProvider(t, api)
+
+	ptr := func(rc *models.RecordConfig) *models.RecordConfig {
+		rc.Metadata = map[string]string{metaPTR: "true"}
+		return rc
+	}
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		ptr(makeRC("www", "A", "192.0.2.1")),
+		ptr(makeRC("www", "AAAA", "2001:db8:1:2::1")),
+		makeRC("mail", "A", "192.0.2.2"),
+	}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	requests := map[string]string{} // by ID
+	for _, correction := range corrections {
+		msgs = append(msgs, correction.Msg)
+		if strings.HasPrefix(correction.Msg, "PTR ") && len(correction.IDs) == 1 && len(correction.Requests) == 1 {
+			requests[correction.IDs[0]] = correction.Requests[0].Method + " " + correction.Requests[0].URL
+		}
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The existing PTR record is updated, and the missing one created.
+	base := c.provider.BaseURL.String()
+	for id, exp := range map[string]string{
+		"rrset 1.2.0.192.in-addr.arpa PTR":                                                   "PUT " + base + "/v2/zones/2.0.192.in-addr.arpa/1.2.0.192.in-addr.arpa/PTR",
+		"rrset 1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.0.0.0.1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa PTR": "POST " + base + "/v2/zones/2.0.0.0.1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa/1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.0.0.0.1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa/PTR",
+	} {
+		if got := requests[id]; got != exp {
+			t.Errorf("%s: got request %q, expected %q", id, got, exp)
+		}
+	}
+	for _, exp := range []string{
+		"PTR 1.2.0.192.in-addr.arpa www.example.com. (for 192.0.2.1, in 2.0.192.in-addr.arpa)",
+		"PTR 1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.0.0.0.1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa www.example.com. (for 2001:db8:1:2::1, in 2.0.0.0.1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa)",
+	} {
+		if !strings.Contains(strings.Join(msgs, "\n"), exp) {
+			t.Errorf("corrections don't include %q:\n%s", exp, strings.Join(msgs, "\n"))
+		}
+	}
+
+	for _, tc := range []struct{ zone, name string }{
+		{"2.0.192.in-addr.arpa", "1.2.0.192.in-addr.arpa"},
+		{"2.0.0.0.1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa", "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.0.0.0.1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa"},
+	} {
+		rrset, ok := api.zones[tc.zone].RRSets[fakeRRSetKey{tc.name, "PTR"}]
+		if !ok || len(rrset.Records) != 1 || rrset.Records[0].ContentToString() != "www.example.com." {
+			t.Errorf("%s PTR is %+v, expected www.example.com.", tc.name, rrset)
+		}
+	}
+	if len(api.zones["0.192.in-addr.arpa"].RRSets) != 0 {
+		t.Errorf("the /16 reverse zone was changed: %+v", api.zones["0.192.in-addr.arpa"].RRSets)
+	}
+
+	// The PTR records are up to date.
+	corrections, err = c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected no corrections, got %d", len(corrections))
+	}
+}
+
+func TestIPv6ReverseZone(t *testing.T) {
+	const zone = "8.b.d.0.1.0.0.2.ip6.arpa"                                 // 2001:db8::/32
+	const name1 = "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.0.0.0.1.0.0.0." + zone // 2001:db8:1:2::1
+	const name2 = "2.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.0.0.0.1.0.0.0." + zone // 2001:db8:1:2::2
+	api := newFakeAPI()
+	api.addZone(zone)
+	api.addRRSet(zone, name1, "PTR", 300, "old.example.com.")
+	c := newTestProvider(t, api)
+
+	// The labels are the 24 nibbles below the /32 zone, not shortened.
+	existing, err := c.GetZoneRecords(zone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(existing) != 1 || existing[0].GetLabelFQDN() != name1 || existing[0].GetLabel() != strings.TrimSuffix(name1, "."+zone) {
+		t.Fatalf("got records %v, expected the PTR %s", existing, name1)
+	}
+
+	ptr := func(fqdn, target string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "PTR", TTL: 300}
+		rc.SetLabelFromFQDN(fqdn, zone)
+		if err := rc.SetTarget(target); err != nil {
+			t.Fatal(err)
+		}
+		return rc
+	}
+	dc := &models.DomainConfig{Name: zone, Records: models.Records{
+		ptr(name1, "www.example.com."),
+		ptr(name2, "mail.example.com."),
+	}}
+	corrections, err := c.GenerateDomainCorrections(dc, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, correction := range corrections {
+		msgs = append(msgs, correction.Msg)
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, exp := range []string{
+		"MODIFY PTR " + name1 + ": (old.example.com. ttl=300) -> (www.example.com. ttl=300)",
+		"CREATE PTR " + name2 + " mail.example.com. ttl=300",
+	} {
+		if !strings.Contains(strings.Join(msgs, "\n"), exp) {
+			t.Errorf("corrections don't include %q:\n%s", exp, strings.Join(msgs, "\n"))
+		}
+	}
+
+	for name, target := range map[string]string{name1: "www.example.com.", name2: "mail.example.com."} {
+		rrset, ok := api.zones[zone].RRSets[fakeRRSetKey{name, "PTR"}]
+		if !ok || len(rrset.Records) != 1 || rrset.Records[0].ContentToString() != target {
+			t.Errorf("%s PTR is %+v, expected %s", name, rrset, target)
+		}
+	}
+	if n := len(api.zones[zone].RRSets); n != 2 {
+		t.Errorf("the zone has %d RRsets, expected 2: %+v", n, api.zones[zone].RRSets)
+	}
+}
+
+func TestReverseZone(t *testing.T) {
+	zones := []string{"example.com", "0.192.in-addr.arpa", "2.0.192.in-addr.arpa", "8.b.d.0.1.0.0.2.ip6.arpa", "1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa"}
+	for name, exp := range map[string]string{
+		"1.2.0.192.in-addr.arpa":  "2.0.192.in-addr.arpa",
+		"1.3.0.192.in-addr.arpa":  "0.192.in-addr.arpa",
+		"1.2.0.193.in-addr.arpa":  "",
+		"1.12.0.192.in-addr.arpa": "0.192.in-addr.arpa",
+		"1.2.10.192.in-addr.arpa": "",
+		"2.0.192.in-addr.arpa":    "2.0.192.in-addr.arpa",
+
+		"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.0.0.0.1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa": "1.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa",
+		"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.0.0.0.2.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa": "8.b.d.0.1.0.0.2.ip6.arpa",
+		"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.0.0.0.1.0.0.0.9.b.d.0.1.0.0.2.ip6.arpa": "",
+	} {
+		if got := reverseZone(name, zones); got != exp {
+			t.Errorf("reverseZone(%q) = %q, expected %q", name, got, exp)
+		}
+	}
+}
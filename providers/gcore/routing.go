@@ -0,0 +1,217 @@
+package gcore
+
+// G-Core can choose the answers of an RRset for each query: by the
+// resolver's location (geo routing), by the resolver's network
+// (split-horizon views), and randomly in proportion to each answer's
+// weight (weighted routing). These can be used together, to weight the
+// answers within each region or view. They are set per answer with
+// record metadata, which is stored in the answer's meta.
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+
+	dnssdk "github.com/G-Core/gcore-dns-sdk-go"
+)
+
+// Record metadata for routing.
+const (
+	metaWeight     = "gcore_weight"     // the answer's weight, from 0 to maxWeight
+	metaCountries  = "gcore_countries"  // comma-separated ISO 3166 country codes, such as "us,ca"
+	metaContinents = "gcore_continents" // comma-separated continent codes, such as "eu,as"
+	metaNetworks   = "gcore_networks"   // comma-separated client networks of the answer's view, such as "10.0.0.0/8"
+)
+
+// The answer meta fields G-Core uses for routing.
+const (
+	answerMetaWeight     = "weight"
+	answerMetaCountries  = "countries"
+	answerMetaContinents = "continents"
+	answerMetaNetworks   = "ip"
+)
+
+// maxWeight is the largest weight AuditRecords accepts.
+const maxWeight = 65535
+
+// continents are the continent codes G-Core accepts.
+var continents = map[string]bool{"af": true, "an": true, "as": true, "eu": true, "na": true, "oc": true, "sa": true}
+
+// normalizeList returns the comma-separated list s lowercased and
+// sorted, so that lists that only differ in order or case are equal.
+func normalizeList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	sort.Strings(items)
+	return items
+}
+
+// routingMetadata returns the record's routing metadata in the form it
+// is compared in.
+func routingMetadata(r *models.RecordConfig) map[string]string {
+	m := map[string]string{}
+	if w := r.Metadata[metaWeight]; w != "" {
+		m["weight"] = w
+	}
+	if c := normalizeList(r.Metadata[metaCountries]); len(c) != 0 {
+		m["countries"] = strings.Join(c, ",")
+	}
+	if c := normalizeList(r.Metadata[metaContinents]); len(c) != 0 {
+		m["continents"] = strings.Join(c, ",")
+	}
+	if n := normalizeNetworks(r.Metadata[metaNetworks]); len(n) != 0 {
+		m["networks"] = strings.Join(n, ",")
+	}
+	return m
+}
+
+// normalizeNetworks returns the comma-separated list of networks s in
+// canonical form and sorted, so that "10.1.2.3/8" and "10.0.0.0/8" are
+// the same view. A single address is a network of one address. Items
+// that aren't networks are kept as they are, for checkRouting to report.
+func normalizeNetworks(s string) []string {
+	var nets []string
+	for _, item := range normalizeList(s) {
+		if !strings.Contains(item, "/") {
+			if ip := net.ParseIP(item); ip != nil {
+				if ip.To4() != nil {
+					item += "/32"
+				} else {
+					item += "/128"
+				}
+			}
+		}
+		if _, n, err := net.ParseCIDR(item); err == nil {
+			item = n.String()
+		}
+		nets = append(nets, item)
+	}
+	sort.Strings(nets)
+	return nets
+}
+
+// addRoutingMeta adds the record's routing metadata to the answer's meta.
+func addRoutingMeta(rr *dnssdk.ResourceRecord, r *models.RecordConfig) {
+	set := func(k string, v interface{}) {
+		if rr.Meta == nil {
+			rr.Meta = map[string]interface{}{}
+		}
+		rr.Meta[k] = v
+	}
+	if w, err := strconv.Atoi(r.Metadata[metaWeight]); err == nil {
+		set(answerMetaWeight, w)
+	}
+	if c := normalizeList(r.Metadata[metaCountries]); len(c) != 0 {
+		set(answerMetaCountries, c)
+	}
+	if c := normalizeList(r.Metadata[metaContinents]); len(c) != 0 {
+		set(answerMetaContinents, c)
+	}
+	if n := normalizeNetworks(r.Metadata[metaNetworks]); len(n) != 0 {
+		set(answerMetaNetworks, n)
+	}
+}
+
+// readRoutingMeta sets the routing metadata in meta from the answer's meta.
+func readRoutingMeta(rr dnssdk.ResourceRecord, meta map[string]string) {
+	if w, ok := rr.Meta[answerMetaWeight].(float64); ok {
+		meta[metaWeight] = strconv.Itoa(int(w))
+	}
+	for k, field := range map[string]string{metaCountries: answerMetaCountries, metaContinents: answerMetaContinents} {
+		list, _ := rr.Meta[field].([]interface{})
+		var items []string
+		for _, item := range list {
+			items = append(items, fmt.Sprint(item))
+		}
+		if c := normalizeList(strings.Join(items, ",")); len(c) != 0 {
+			meta[k] = strings.Join(c, ",")
+		}
+	}
+	if list, ok := rr.Meta[answerMetaNetworks].([]interface{}); ok {
+		var items []string
+		for _, item := range list {
+			items = append(items, fmt.Sprint(item))
+		}
+		if n := normalizeNetworks(strings.Join(items, ",")); len(n) != 0 {
+			meta[metaNetworks] = strings.Join(n, ",")
+		}
+	}
+}
+
+// routingFilters returns the filters that make G-Core use the routing
+// meta of the RRset's answers: geo routing first, then weighted routing
+// of the answers for the resolver's location.
+func routingFilters(rrset *dnssdk.RRSet) []dnssdk.RecordFilter {
+	var geo, weighted bool
+	for _, rr := range rrset.Records {
+		_, countries := rr.Meta[answerMetaCountries]
+		_, continents := rr.Meta[answerMetaContinents]
+		_, networks := rr.Meta[answerMetaNetworks]
+		_, weight := rr.Meta[answerMetaWeight]
+		geo = geo || countries || continents || networks
+		weighted = weighted || weight
+	}
+	var filters []dnssdk.RecordFilter
+	if geo {
+		filters = append(filters, dnssdk.NewGeoDNSFilter(0, false))
+	}
+	if weighted {
+		filters = append(filters, dnssdk.RecordFilter{Type: "weighted_shuffle"})
+	}
+	return filters
+}
+
+// checkRouting returns an error if the record's routing metadata is
+// invalid.
+func checkRouting(rc *models.RecordConfig) error {
+	if w, ok := rc.Metadata[metaWeight]; ok {
+		n, err := strconv.Atoi(w)
+		if err != nil || n < 0 || n > maxWeight {
+			return fmt.Errorf("%s must be a whole number from 0 to %d, got %q", metaWeight, maxWeight, w)
+		}
+	}
+	for _, c := range normalizeList(rc.Metadata[metaCountries]) {
+		if len(c) != 2 || strings.Trim(c, "abcdefghijklmnopqrstuvwxyz") != "" {
+			return fmt.Errorf("%s must be a list of two-letter country codes, got %q", metaCountries, c)
+		}
+	}
+	for _, c := range normalizeList(rc.Metadata[metaContinents]) {
+		if !continents[c] {
+			return fmt.Errorf("%s must be a list of continent codes (af, an, as, eu, na, oc, sa), got %q", metaContinents, c)
+		}
+	}
+	for _, n := range normalizeNetworks(rc.Metadata[metaNetworks]) {
+		if _, _, err := net.ParseCIDR(n); err != nil {
+			return fmt.Errorf("%s must be a list of networks (such as 10.0.0.0/8) or addresses, got %q", metaNetworks, n)
+		}
+	}
+	return nil
+}
+
+// rejectedViews returns err, explaining that the account may not
+// support split-horizon views if G-Core refused an RRset that uses them.
+// Whether views are available depends on the account's plan, which
+// AuditRecords can't know.
+func rejectedViews(err error, name, typ string, rrset dnssdk.RRSet) error {
+	if err == nil {
+		return nil
+	}
+	if !isAPIStatus(err, http.StatusBadRequest) && !isAPIStatus(err, http.StatusForbidden) && !isAPIStatus(err, http.StatusUnprocessableEntity) {
+		return err
+	}
+	for _, rr := range rrset.Records {
+		if _, ok := rr.Meta[answerMetaNetworks]; ok {
+			return fmt.Errorf("G-Core rejected %s %s, which uses split-horizon views (%s); the account may not support them: %w", typ, name, metaNetworks, err)
+		}
+	}
+	return err
+}
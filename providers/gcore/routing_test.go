@@ -0,0 +1,200 @@
+package gcore
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestGeoWeightedAnswers(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	c := newTestProvider(t, api)
+
+	answer := func(ip, countries, continents, weight string) *models.RecordConfig {
+		rc := makeRC("www", "A", ip)
+		rc.Metadata = map[string]string{metaWeight: weight}
+		if countries != "" {
+			rc.Metadata[metaCountries] = countries
+		}
+		if continents != "" {
+			rc.Metadata[metaContinents] = continents
+		}
+		return rc
+	}
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		answer("192.0.2.1", "US,ca", "", "80"),
+		answer("192.0.2.2", "us,CA", "", "20"),
+		answer("192.0.2.3", "", "eu", "1"),
+	}}
+	if errs := AuditRecords(dc.Records); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rrset := api.zones["example.com"].RRSets[fakeRRSetKey{"www.example.com", "A"}]
+	var filters []string
+	for _, f := range rrset.Filters {
+		filters = append(filters, f.Type)
+	}
+	if got := strings.Join(filters, ","); got != "geodns,weighted_shuffle" {
+		t.Errorf("got filters %s, expected geodns,weighted_shuffle", got)
+	}
+
+	// Read back, each answer has both its region and its weight.
+	recs, err := c.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rc := range recs {
+		got = append(got, rc.GetTargetField()+" "+rc.Metadata[metaCountries]+rc.Metadata[metaContinents]+" "+rc.Metadata[metaWeight])
+	}
+	exp := "192.0.2.1 ca,us 80\n192.0.2.2 ca,us 20\n192.0.2.3 eu 1"
+	if strings.Join(got, "\n") != exp {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), exp)
+	}
+
+	corrections, err = c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected no corrections, got %d", len(corrections))
+	}
+
+	// Changing a weight updates the RRset.
+	dc.Records[1].Metadata[metaWeight] = "30"
+	corrections, err = c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 {
+		t.Errorf("expected 1 correction, got %d", len(corrections))
+	}
+}
+
+func TestAuditRouting(t *testing.T) {
+	for _, tc := range []struct {
+		meta map[string]string
+		ok   bool
+	}{
+		{map[string]string{metaWeight: "0"}, true},
+		{map[string]string{metaWeight: "65535", metaCountries: "us", metaContinents: "na"}, true},
+		{map[string]string{metaWeight: "-1"}, false},
+		{map[string]string{metaWeight: "65536"}, false},
+		{map[string]string{metaWeight: "1.5"}, false},
+		{map[string]string{metaCountries: "usa"}, false},
+		{map[string]string{metaContinents: "europe"}, false},
+		{map[string]string{metaNetworks: "10.0.0.0/8, 192.0.2.1,2001:db8::/32"}, true},
+		{map[string]string{metaNetworks: "10.0.0.0/33"}, false},
+		{map[string]string{metaNetworks: "internal"}, false},
+	} {
+		rc := makeRC("www", "A", "192.0.2.1")
+		rc.Metadata = tc.meta
+		errs := AuditRecords(models.Records{rc})
+		if tc.ok && len(errs) != 0 {
+			t.Errorf("%v: expected no errors, got %v", tc.meta, errs)
+		} else if !tc.ok && len(errs) == 0 {
+			t.Errorf("%v: expected an error, got none", tc.meta)
+		}
+	}
+}
+
+func TestViews(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	c := newTestProvider(t, api)
+
+	answer := func(ip, networks string) *models.RecordConfig {
+		rc := makeRC("www", "A", ip)
+		rc.Metadata = map[string]string{metaNetworks: networks}
+		return rc
+	}
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		answer("10.0.0.1", "10.0.0.0/8,192.168.0.0/16"),
+		answer("192.0.2.1", "0.0.0.0/0"),
+	}}
+	if errs := AuditRecords(dc.Records); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rrset := api.zones["example.com"].RRSets[fakeRRSetKey{"www.example.com", "A"}]
+	if len(rrset.Filters) != 1 || rrset.Filters[0].Type != "geodns" {
+		t.Errorf("got filters %+v, expected geodns", rrset.Filters)
+	}
+
+	// Read back, each answer is in its view.
+	recs, err := c.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rc := range recs {
+		got = append(got, rc.GetTargetField()+" "+rc.Metadata[metaNetworks])
+	}
+	exp := "10.0.0.1 10.0.0.0/8,192.168.0.0/16\n192.0.2.1 0.0.0.0/0"
+	if strings.Join(got, "\n") != exp {
+		t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), exp)
+	}
+
+	// The same networks written differently are the same view.
+	dc.Records[0].Metadata[metaNetworks] = "192.168.1.1/16, 10.0.0.0/8"
+	corrections, err = c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("expected no corrections, got %d", len(corrections))
+	}
+
+	// Moving an answer to another view updates the RRset.
+	dc.Records[0].Metadata[metaNetworks] = "10.0.0.0/8"
+	corrections, err = c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 {
+		t.Errorf("expected 1 correction, got %d", len(corrections))
+	}
+}
+
+func TestViewsUnsupported(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.failRequest("POST /v2/zones/example.com/www.example.com/A", http.StatusForbidden)
+	c := newTestProvider(t, api)
+
+	rc := makeRC("www", "A", "10.0.0.1")
+	rc.Metadata = map[string]string{metaNetworks: "10.0.0.0/8"}
+	corrections, err := c.GetDomainCorrections(&models.DomainConfig{Name: "example.com", Records: models.Records{rc}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 {
+		t.Fatalf("expected 1 correction, got %d", len(corrections))
+	}
+	err = corrections[0].F()
+	if err == nil || !strings.Contains(err.Error(), "the account may not support them") {
+		t.Errorf("expected an error about views, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package gcore
+
+// A domain with a managed scope is shared with other tools or manual
+// changes: DNSControl only manages the records at or under the scope's
+// labels, and ignores the rest of the zone.
+
+import (
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+)
+
+// metaManagedScope is set on a domain to a comma-separated list of
+// labels, such as "k8s" for k8s.example.com and everything under it.
+const metaManagedScope = "gcore_managed_scope"
+
+// applyScope returns dc and existing without the records outside dc's
+// managed scope. dc is copied rather than changed. Desired records
+// outside the scope are reported as warnings, except for the apex NS
+// records, which DNSControl adds itself.
+func applyScope(dc *models.DomainConfig, existing models.Records) (*models.DomainConfig, models.Records) {
+	list := dc.Metadata[metaManagedScope]
+	if list == "" {
+		return dc, existing
+	}
+	var scope []string
+	for _, label := range strings.Split(list, ",") {
+		if label = strings.Trim(strings.TrimSpace(label), "."); label != "" {
+			scope = append(scope, strings.ToLower(label))
+		}
+	}
+	inScope := func(rc *models.RecordConfig) bool {
+		label := rc.GetLabel()
+		for _, s := range scope {
+			if label == s || strings.HasSuffix(label, "."+s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	scoped := *dc
+	scoped.Records = nil
+	for _, rc := range dc.Records {
+		if inScope(rc) {
+			scoped.Records = append(scoped.Records, rc)
+		} else if !(rc.Type == "NS" && rc.GetLabel() == "@") {
+			printer.Warnf("%s %s is outside %s %q, not managing it\n", rc.GetLabelFQDN(), rc.Type, metaManagedScope, list)
+		}
+	}
+	var scopedExisting models.Records
+	for _, rc := range existing {
+		if inScope(rc) {
+			scopedExisting = append(scopedExisting, rc)
+		}
+	}
+	return &scoped, scopedExisting
+}
+
+// applyDelegations returns dc and existing without the records beneath
+// subdomains that are delegated elsewhere by NS records in the zone,
+// which belong to the zone of the delegated subdomain. Glue (A and AAAA
+// records for the delegation's name servers) is still managed. dc is
+// copied rather than changed, and desired records beneath a delegation
+// are reported as warnings.
+func applyDelegations(dc *models.DomainConfig, existing models.Records) (*models.DomainConfig, models.Records) {
+	delegated := map[string]bool{} // label
+	glue := map[string]bool{}      // FQDN
+	for _, recs := range []models.Records{dc.Records, existing} {
+		for _, rc := range recs {
+			if rc.Type == "NS" && rc.GetLabel() != "@" {
+				delegated[rc.GetLabel()] = true
+				glue[strings.TrimSuffix(strings.ToLower(rc.GetTargetField()), ".")] = true
+			}
+		}
+	}
+	if len(delegated) == 0 {
+		return dc, existing
+	}
+	// beneath returns the delegated label that rc is beneath, if any.
+	beneath := func(rc *models.RecordConfig) string {
+		label := rc.GetLabel()
+		for i := strings.IndexByte(label, '.'); i != -1; i = strings.IndexByte(label, '.') {
+			if label = label[i+1:]; delegated[label] {
+				return label
+			}
+		}
+		return ""
+	}
+	inScope := func(rc *models.RecordConfig) bool {
+		if (rc.Type == "A" || rc.Type == "AAAA") && glue[strings.ToLower(rc.GetLabelFQDN())] {
+			return true
+		}
+		return beneath(rc) == ""
+	}
+
+	scoped := *dc
+	scoped.Records = nil
+	for _, rc := range dc.Records {
+		if inScope(rc) {
+			scoped.Records = append(scoped.Records, rc)
+		} else {
+			printer.Warnf("%s %s is beneath the delegated subdomain %s, not managing it\n", rc.GetLabelFQDN(), rc.Type, beneath(rc)+"."+dc.Name)
+		}
+	}
+	var scopedExisting models.Records
+	for _, rc := range existing {
+		if inScope(rc) {
+			scopedExisting = append(scopedExisting, rc)
+		}
+	}
+	return &scoped, scopedExisting
+}
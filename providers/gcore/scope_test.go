@@ -0,0 +1,165 @@
+package gcore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestManagedScope(t *testing.T) {
+	dc := &models.DomainConfig{
+		Name:     "example.com",
+		Metadata: map[string]string{metaManagedScope: "k8s, _acme-challenge"},
+		Records: models.Records{
+			makeRC("@", "NS", "ns1.gcorelabs.net."),
+			makeRC("k8s", "A", "192.0.2.2"),     // changed
+			makeRC("api.k8s", "A", "192.0.2.3"), // created
+			makeRC("www", "A", "192.0.2.9"),     // out of scope, not changed
+			makeRC("new", "A", "192.0.2.9"),     // out of scope, not created
+			makeRC("notk8s", "A", "192.0.2.9"),  // out of scope, not created
+		},
+	}
+	existing := models.Records{
+		makeRC("@", "NS", "ns2.gcdn.services."),
+		makeRC("k8s", "A", "192.0.2.1"),
+		makeRC("old.k8s", "A", "192.0.2.1"),         // deleted
+		makeRC("_acme-challenge", "A", "192.0.2.1"), // deleted
+		makeRC("www", "A", "192.0.2.1"),
+		makeRC("manual", "A", "192.0.2.1"), // out of scope, not deleted
+	}
+
+	var corrections []*models.Correction
+	out := captureWarnings(t, func() {
+		var err error
+		corrections, err = (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(dc, existing)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var msgs []string
+	for _, c := range corrections {
+		msgs = append(msgs, c.Msg)
+	}
+	got := strings.Join(msgs, "\n")
+	for _, name := range []string{" k8s.example.com", "api.k8s.example.com", "old.k8s.example.com", "_acme-challenge.example.com"} {
+		if !strings.Contains(got, name) {
+			t.Errorf("expected a correction for %s, got:\n%s", name, got)
+		}
+	}
+	for _, name := range []string{"NS", "www.example.com", "new.example.com", "notk8s.example.com", "manual.example.com"} {
+		if strings.Contains(got, name) {
+			t.Errorf("unexpected correction for out of scope %s:\n%s", name, got)
+		}
+	}
+	for _, name := range []string{"www.example.com", "new.example.com", "notk8s.example.com"} {
+		if !strings.Contains(out, name) {
+			t.Errorf("expected a warning for out of scope %s, got:\n%s", name, out)
+		}
+	}
+	if strings.Contains(out, "NS") || strings.Contains(out, "manual") {
+		t.Errorf("unexpected warning:\n%s", out)
+	}
+	if len(dc.Records) != 6 {
+		t.Errorf("expected dc to be unchanged, got %d records", len(dc.Records))
+	}
+}
+
+func TestDelegatedSubdomain(t *testing.T) {
+	dc := &models.DomainConfig{
+		Name: "example.com",
+		Records: models.Records{
+			makeRC("sub", "NS", "ns1.sub.example.com."),
+			makeRC("ns1.sub", "A", "192.0.2.53"), // glue, created
+			makeRC("www.sub", "A", "192.0.2.9"),  // beneath the delegation, not created
+			makeRC("www", "A", "192.0.2.2"),      // changed
+		},
+	}
+	existing := models.Records{
+		makeRC("sub", "NS", "ns1.sub.example.com."),
+		makeRC("api.sub", "A", "192.0.2.1"),          // beneath the delegation, not deleted
+		makeRC("x.sub", "CNAME", "www.example.net."), // beneath the delegation, not deleted
+		makeRC("www", "A", "192.0.2.1"),
+	}
+
+	var corrections []*models.Correction
+	out := captureWarnings(t, func() {
+		var err error
+		corrections, err = (&gcoreProvider{provider: offlineClient()}).GenerateDomainCorrections(dc, existing)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	var msgs []string
+	for _, c := range corrections {
+		msgs = append(msgs, c.Msg)
+	}
+	got := strings.Join(msgs, "\n")
+	for _, name := range []string{" www.example.com", "ns1.sub.example.com"} {
+		if !strings.Contains(got, name) {
+			t.Errorf("expected a correction for %s, got:\n%s", name, got)
+		}
+	}
+	for _, name := range []string{"www.sub.example.com", "api.sub.example.com", "x.sub.example.com", "NS"} {
+		if strings.Contains(got, name) {
+			t.Errorf("unexpected correction for %s:\n%s", name, got)
+		}
+	}
+	if !strings.Contains(out, "www.sub.example.com A is beneath the delegated subdomain sub.example.com") {
+		t.Errorf("expected a warning for www.sub.example.com, got:\n%s", out)
+	}
+	if strings.Contains(out, "api.sub") || strings.Contains(out, "ns1.sub") {
+		t.Errorf("unexpected warning:\n%s", out)
+	}
+}
+
+func TestDelegationNSTTL(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "example.com", "NS", 86400, "ns1.gcorelabs.net.", "ns2.gcdn.services.")
+	api.addRRSet("example.com", "sub.example.com", "NS", 3600, "ns1.example.net.", "ns2.example.net.")
+	c := newTestProvider(t, api)
+
+	withTTL := func(rc *models.RecordConfig, ttl uint32) *models.RecordConfig {
+		rc.TTL = ttl
+		return rc
+	}
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		withTTL(makeRC("@", "NS", "ns1.gcorelabs.net."), 86400),
+		withTTL(makeRC("@", "NS", "ns2.gcdn.services."), 86400),
+		withTTL(makeRC("sub", "NS", "ns1.example.net."), 600),
+		withTTL(makeRC("sub", "NS", "ns2.example.net."), 600),
+	}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 {
+		t.Fatalf("got %d corrections, expected 1 for the delegation", len(corrections))
+	}
+	if msg := corrections[0].Msg; !strings.Contains(msg, "NS sub.example.com") || strings.Contains(msg, "NS example.com") {
+		t.Errorf("expected only the delegation to change, got %q", msg)
+	}
+	if err := corrections[0].F(); err != nil {
+		t.Fatal(err)
+	}
+
+	z := api.zones["example.com"]
+	if ttl := z.RRSets[fakeRRSetKey{"sub.example.com", "NS"}].TTL; ttl != 600 {
+		t.Errorf("got delegation TTL %d, expected 600", ttl)
+	}
+	if ttl := z.RRSets[fakeRRSetKey{"example.com", "NS"}].TTL; ttl != 86400 {
+		t.Errorf("got apex TTL %d, expected 86400", ttl)
+	}
+
+	// The distinct TTLs are kept without further changes.
+	corrections, err = c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("got %d corrections after the push, expected none", len(corrections))
+	}
+}
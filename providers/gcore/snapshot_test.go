@@ -0,0 +1,72 @@
+package gcore
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestSnapshotCorrections(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.80")
+	c := newTestProvider(t, api)
+
+	// The snapshot, as get-zones --format=json writes it, differs from
+	// the zone at G-Core, which must not be read.
+	var existing models.Records
+	if err := json.Unmarshal([]byte(`[
+	  {"type": "A", "name": "www", "ttl": 300, "target": "192.0.2.1"},
+	  {"type": "MX", "name": "@", "ttl": 300, "mxpreference": 10, "target": "mail.example.com."},
+	  {"type": "TXT", "name": "old", "ttl": 300, "txtstrings": ["gone"], "target": "gone"}
+	]`), &existing); err != nil {
+		t.Fatal(err)
+	}
+	for _, rc := range existing {
+		rc.SetLabel(rc.Name, "example.com")
+	}
+	mx := &models.RecordConfig{Type: "MX", TTL: 300}
+	mx.SetLabel("@", "example.com")
+	if err := mx.SetTargetMX(10, "mail.example.com."); err != nil {
+		t.Fatal(err)
+	}
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "A", "192.0.2.2"),
+		mx,
+	}}
+
+	corrections, err := c.GetSnapshotCorrections(dc, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, correction := range corrections {
+		msgs = append(msgs, correction.Msg)
+		if correction.F != nil {
+			t.Errorf("correction %q can be run", correction.Msg)
+		}
+	}
+	got := strings.Join(msgs, "\n")
+	for _, exp := range []string{"www.example.com: add 192.0.2.2; remove 192.0.2.1", "DELETE TXT old.example.com"} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("got corrections %q, expected one containing %q", got, exp)
+		}
+	}
+	if strings.Contains(got, "MX") {
+		t.Errorf("got corrections %q, expected none for the MX record, which is in the snapshot", got)
+	}
+	if len(api.requests) != 0 {
+		t.Errorf("made API requests %q, expected none", api.requests)
+	}
+}
+
+func TestNewGCoreFromSnapshot(t *testing.T) {
+	if _, err := NewGCore(map[string]string{}, nil); err == nil {
+		t.Error("expected an error without an API key")
+	}
+	if _, err := NewGCore(map[string]string{"_from_snapshot": "true", "validate-api-key": "true"}, nil); err != nil {
+		t.Errorf("expected no API key to be needed for a snapshot, got %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package gcore
+
+// A change to the answers of an RRset with gcore_staged is rolled out in
+// two phases, in separate runs. The first only lowers the RRset's TTL to
+// the value of gcore_staged, and records in the answers' meta the time
+// its old TTL will have expired from caches. Runs before then change
+// nothing. The first run after it makes the change itself, with the
+// TTL in dnsconfig.js, so that resolvers pick up the new answers within
+// the lowered TTL. The state is kept at G-Core, so that the runs can be
+// made from different machines.
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/printer"
+)
+
+// metaStaged is set on a record to the TTL, in seconds, to lower its
+// RRset to before its answers are changed.
+const metaStaged = "gcore_staged"
+
+// answerMetaStagedUntil is the answer meta field that is set, by the
+// first phase of a staged change, to the time the second can be made.
+const answerMetaStagedUntil = "dnscontrol_staged_until"
+
+// metaStagedUntil is set on records read from G-Core to the value of
+// answerMetaStagedUntil, if it is set.
+const metaStagedUntil = "gcore_staged_until"
+
+// stagedTTL returns the TTL of metaStaged of recs, an RRset, and whether
+// any of them set it.
+func stagedTTL(recs models.Records) (uint32, bool) {
+	for _, rc := range recs {
+		if v, ok := rc.Metadata[metaStaged]; ok {
+			ttl, _ := strconv.ParseUint(v, 10, 32) // checked by checkStaged
+			return uint32(ttl), true
+		}
+	}
+	return 0, false
+}
+
+// sameAnswers reports whether a and b have the same answers, regardless
+// of their TTLs and order.
+func sameAnswers(a, b models.Records) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := map[string]int{}
+	for _, rc := range a {
+		count[rc.GetTargetCombined()]++
+	}
+	for _, rc := range b {
+		answer := rc.GetTargetCombined()
+		if count[answer] == 0 {
+			return false
+		}
+		count[answer]--
+	}
+	return true
+}
+
+// stageChanges returns dc with the RRsets whose answers change in a
+// staged change replaced by the phase of it that is due at now, and a
+// message for each RRset whose TTL is lowered.
+func stageChanges(dc *models.DomainConfig, existing models.Records, now time.Time) (*models.DomainConfig, map[models.RecordKey]string, error) {
+	desiredRecords := dc.Records.GroupedByKey()
+	existingRecords := existing.GroupedByKey()
+	staged := map[models.RecordKey]models.Records{}
+	msgs := map[models.RecordKey]string{}
+	for key, recs := range desiredRecords {
+		ttl, ok := stagedTTL(recs)
+		have := existingRecords[key]
+		if !ok || len(have) == 0 || sameAnswers(recs, have) {
+			continue
+		}
+		if v := have[0].Metadata[metaStagedUntil]; v != "" {
+			until, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s %s: %s %q: %w", key.NameFQDN, key.Type, answerMetaStagedUntil, v, err)
+			}
+			if now.Before(until) {
+				printer.Warnf("%s %s is staged, not changing its answers until %s\n", key.NameFQDN, key.Type, v)
+				staged[key] = have
+			}
+			continue
+		}
+		if have[0].TTL <= ttl {
+			continue // already low enough
+		}
+		until := now.Add(time.Duration(have[0].TTL) * time.Second).UTC().Format(time.RFC3339)
+		var lowered models.Records
+		for _, rc := range have {
+			rc, err := rc.Copy()
+			if err != nil {
+				return nil, nil, err
+			}
+			rc.TTL = ttl
+			if rc.Metadata == nil {
+				rc.Metadata = map[string]string{}
+			}
+			rc.Metadata[metaStagedUntil] = until
+			lowered = append(lowered, rc)
+		}
+		staged[key] = lowered
+		msgs[key] = fmt.Sprintf("STAGE %s %s: lower ttl to %d, then change its answers from %s", key.Type, key.NameFQDN, ttl, until)
+	}
+	if len(staged) == 0 {
+		return dc, msgs, nil
+	}
+
+	records := make(models.Records, 0, len(dc.Records))
+	for _, rc := range dc.Records {
+		recs, ok := staged[rc.Key()]
+		if !ok {
+			records = append(records, rc)
+			continue
+		}
+		records = append(records, recs...)
+		delete(staged, rc.Key()) // only once for the RRset
+	}
+	out := *dc
+	out.Records = records
+	return &out, msgs, nil
+}
+
+// checkStaged checks the value of metaStaged.
+func checkStaged(rc *models.RecordConfig) error {
+	v, ok := rc.Metadata[metaStaged]
+	if !ok {
+		return nil
+	}
+	if ttl, err := strconv.ParseUint(v, 10, 32); err != nil || ttl < 1 {
+		return fmt.Errorf("%s must be a TTL of at least 1 second, got %q", metaStaged, v)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package gcore
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestStagedChange(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "example.com", "A", 3600, "192.0.2.1")
+	c := newTestProvider(t, api)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	c.now = func() time.Time { return now }
+
+	rc := makeRC("@", "A", "192.0.2.2")
+	rc.Metadata = map[string]string{metaStaged: "60"}
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{rc}}
+
+	// push runs the corrections of dc, returning their messages.
+	push := func() string {
+		t.Helper()
+		corrections, err := c.GetDomainCorrections(dc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var msgs []string
+		for _, correction := range corrections {
+			msgs = append(msgs, correction.Msg)
+			if err := correction.F(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return strings.Join(msgs, "\n")
+	}
+	// check checks the apex A RRset at G-Core.
+	check := func(ttl int, answer string, until interface{}) {
+		t.Helper()
+		rrset := api.zones["example.com"].RRSets[fakeRRSetKey{"example.com", "A"}]
+		if rrset.TTL != ttl || len(rrset.Records) != 1 || rrset.Records[0].ContentToString() != answer || rrset.Records[0].Meta[answerMetaStagedUntil] != until {
+			t.Errorf("got ttl=%d %+v, expected ttl=%d %s with %s=%v", rrset.TTL, rrset.Records, ttl, answer, answerMetaStagedUntil, until)
+		}
+	}
+
+	// The first phase only lowers the TTL.
+	msgs := push()
+	if !strings.Contains(msgs, "STAGE A example.com: lower ttl to 60, then change its answers from 2024-01-01T13:00:00Z") {
+		t.Errorf("got corrections %q, expected the TTL to be lowered", msgs)
+	}
+	check(60, "192.0.2.1", "2024-01-01T13:00:00Z")
+
+	// Until the old TTL has expired, nothing changes.
+	now = start.Add(30 * time.Minute)
+	if msgs := push(); msgs != "" {
+		t.Errorf("got corrections %q, expected none before the old TTL expired", msgs)
+	}
+	check(60, "192.0.2.1", "2024-01-01T13:00:00Z")
+
+	// Then the second phase changes the answers and restores the TTL.
+	now = start.Add(time.Hour)
+	msgs = push()
+	if !strings.Contains(msgs, "192.0.2.2") {
+		t.Errorf("got corrections %q, expected the answers to change", msgs)
+	}
+	check(300, "192.0.2.2", nil)
+	if msgs := push(); msgs != "" {
+		t.Errorf("got corrections %q, expected none once the change is made", msgs)
+	}
+}
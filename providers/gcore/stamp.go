@@ -0,0 +1,45 @@
+package gcore
+
+// With stamp-managed, the answers DNSControl writes are marked with the
+// time of the run, so that the drift command can tell records DNSControl
+// wrote from those added elsewhere, such as in G-Core's control panel.
+// Only the RRsets that a push changes are marked.
+
+import (
+	"github.com/StackExchange/dnscontrol/v3/models"
+
+	dnssdk "github.com/G-Core/gcore-dns-sdk-go"
+)
+
+// answerMetaManaged is the answer meta field that is set to the time
+// DNSControl wrote the answer.
+const answerMetaManaged = "dnscontrol_managed"
+
+// metaManaged is set on records read from G-Core to the time DNSControl
+// last wrote them, if it did and stamp-managed was enabled then.
+const metaManaged = "gcore_managed"
+
+// stamp marks the answers of rrset as written by DNSControl, if
+// stamp-managed is enabled.
+func (c *gcoreProvider) stamp(rrset *dnssdk.RRSet) {
+	if c.managedStamp == "" {
+		return
+	}
+	for i := range rrset.Records {
+		if rrset.Records[i].Meta == nil {
+			rrset.Records[i].Meta = map[string]interface{}{}
+		}
+		rrset.Records[i].Meta[answerMetaManaged] = c.managedStamp
+	}
+}
+
+// StampsManaged reports whether stamp-managed is enabled.
+func (c *gcoreProvider) StampsManaged() bool {
+	return c.managedStamp != ""
+}
+
+// LastManaged returns the time DNSControl last wrote rc, a record read
+// from G-Core, or "" if it didn't.
+func (c *gcoreProvider) LastManaged(rc *models.RecordConfig) string {
+	return rc.Metadata[metaManaged]
+}
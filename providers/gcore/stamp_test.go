@@ -0,0 +1,61 @@
+package gcore
+
+import (
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestManagedStamp(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "manual.example.com", "A", 300, "192.0.2.8")
+	c := newTestProvider(t, api)
+	c.managedStamp = "2026-10-15T00:00:00Z"
+
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("manual", "A", "192.0.2.8"),
+		makeRC("www", "A", "192.0.2.1"),
+	}}
+	corrections := func() []*models.Correction {
+		t.Helper()
+		existing, err := c.GetZoneRecords("example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		corrections, err := c.GenerateDomainCorrections(dc, existing)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return corrections
+	}
+	for _, correction := range corrections() {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rrset := api.zones["example.com"].RRSets[fakeRRSetKey{"www.example.com", "A"}]
+	if len(rrset.Records) != 1 || rrset.Records[0].Meta[answerMetaManaged] != c.managedStamp {
+		t.Errorf("www A answers weren't stamped: %+v", rrset.Records)
+	}
+
+	// The stamp is read back, and doesn't cause corrections itself.
+	if extra := corrections(); len(extra) != 0 {
+		t.Errorf("got %d corrections after the push, expected none: %s", len(extra), extra[0].Msg)
+	}
+	existing, err := c.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	managed := map[string]string{}
+	for _, rc := range existing {
+		managed[rc.GetLabel()+" "+rc.Type] = c.LastManaged(rc)
+	}
+	if got := managed["www A"]; got != c.managedStamp {
+		t.Errorf("www A last managed %q, expected %q", got, c.managedStamp)
+	}
+	if got := managed["manual A"]; got != "" {
+		t.Errorf("manual A last managed %q, expected none", got)
+	}
+}
@@ -0,0 +1,80 @@
+package gcore
+
+// G-Core's API has the content of an SVCB or HTTPS answer as the
+// priority, the target, and then one array for each SvcParam, of its key
+// followed by its values:
+//
+//	[1, ".", ["alpn", "h2", "h3"], ["port", 8443]]
+//
+// In AliasMode (priority 0) there are no SvcParams.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/miekg/dns"
+)
+
+// svcbContent returns the content of the SVCB or HTTPS answer of rc. The
+// SvcParams are sorted by key, so that the order they were declared in
+// doesn't matter.
+func svcbContent(rc *models.RecordConfig) []interface{} {
+	content := []interface{}{int64(rc.SvcPriority), rc.GetTargetField()}
+	params, _ := rc.GetSvcParams() // checked by normalization
+	for _, kv := range params {
+		param := []interface{}{kv.Key().String()}
+		switch v := kv.(type) {
+		case *dns.SVCBNoDefaultAlpn:
+		case *dns.SVCBPort:
+			param = append(param, int64(v.Port))
+		case *dns.SVCBAlpn, *dns.SVCBMandatory, *dns.SVCBIPv4Hint, *dns.SVCBIPv6Hint:
+			for _, s := range strings.Split(kv.String(), ",") {
+				param = append(param, s)
+			}
+		default:
+			param = append(param, kv.String())
+		}
+		content = append(content, param)
+	}
+	return content
+}
+
+// setTargetSVCBContent sets the fields of rc, an SVCB or HTTPS record,
+// from the content of a G-Core answer.
+func setTargetSVCBContent(rc *models.RecordConfig, content []interface{}) error {
+	if len(content) < 2 {
+		return fmt.Errorf("incorrect number of fields in G-Core's %s record", rc.Type)
+	}
+	var params []string
+	for _, c := range content[2:] {
+		param, ok := c.([]interface{})
+		if !ok || len(param) == 0 {
+			// A parameter in zonefile form, such as alpn=h2.
+			params = append(params, fmt.Sprint(c))
+			continue
+		}
+		key := fmt.Sprint(param[0])
+		if len(param) == 1 {
+			params = append(params, key)
+			continue
+		}
+		values := make([]string, len(param)-1)
+		for i, v := range param[1:] {
+			values[i] = contentString(v)
+		}
+		params = append(params, key+"="+strconv.Quote(strings.Join(values, ",")))
+	}
+	return rc.SetTargetSVCBStrings(contentString(content[0]), fmt.Sprint(content[1]), strings.Join(params, " "))
+}
+
+// contentString returns a value of an answer's content as a string.
+// Numbers decoded from JSON are float64, which fmt.Sprint would write
+// as 8443 but may write in exponent form if large.
+func contentString(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,69 @@
+package gcore
+
+// Each G-Core API request is an OpenTelemetry span, named for what it
+// does ("gcore read", "gcore create", "gcore update" or "gcore delete"),
+// with the zone, and the record name and type if it is about an RRset.
+// The spans go to the global tracer provider, so they are only recorded
+// if a program using DNSControl configures one; otherwise they are
+// no-ops.
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer of the G-Core API requests.
+const tracerName = "github.com/StackExchange/dnscontrol/v3/providers/gcore"
+
+// spanName returns the name of the span of req.
+func spanName(req *http.Request) string {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		return "gcore read"
+	case http.MethodPost:
+		return "gcore create"
+	case http.MethodDelete:
+		return "gcore delete"
+	default:
+		return "gcore update"
+	}
+}
+
+// spanAttributes returns the attributes of the span of req: the method,
+// and from its path, the zone, and the record name and type. G-Core's
+// paths are /v2/zones/ZONE, followed by /NAME/TYPE for an RRset, /NAME
+// for all the RRsets of a name, or an action on the zone such as
+// /dnssec. Record names are FQDNs, so they always have a dot.
+func spanAttributes(req *http.Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("http.method", req.Method)}
+	_, rest, ok := strings.Cut(req.URL.Path, "/v2/zones/")
+	if !ok || rest == "" {
+		return attrs
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	attrs = append(attrs, attribute.String("gcore.zone", parts[0]))
+	if len(parts) >= 2 && strings.Contains(parts[1], ".") {
+		attrs = append(attrs, attribute.String("gcore.record", parts[1]))
+		if len(parts) >= 3 {
+			attrs = append(attrs, attribute.String("gcore.type", parts[2]))
+		}
+	}
+	return attrs
+}
+
+// endSpan records the outcome of the request of span.
+func endSpan(span trace.Span, resp *http.Response, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+}
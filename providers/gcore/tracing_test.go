@@ -0,0 +1,85 @@
+package gcore
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "old.example.com", "A", 300, "192.0.2.9")
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1")
+	c := newTestProvider(t, api)
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	c.transport = &retryTransport{base: http.DefaultTransport, tracer: tp.Tracer(tracerName)}
+	c.provider.HTTPClient.Transport = c.transport
+
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "A", "192.0.2.2"),
+		makeRC("new", "A", "192.0.2.3"),
+	}}
+	existing, err := c.GetZoneRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrections, err := c.GenerateDomainCorrections(dc, existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exporter.Reset() // only the push's spans
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	for _, span := range exporter.GetSpans() {
+		attrs := map[string]string{}
+		for _, kv := range span.Attributes {
+			attrs[string(kv.Key)] = kv.Value.Emit()
+		}
+		got = append(got, strings.Join([]string{span.Name, attrs["gcore.zone"], attrs["gcore.record"], attrs["gcore.type"], attrs["http.status_code"]}, " "))
+	}
+	sort.Strings(got)
+	exp := []string{
+		"gcore create example.com new.example.com A 200",
+		"gcore delete example.com old.example.com A 200",
+		"gcore update example.com www.example.com A 200",
+	}
+	if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+		t.Errorf("got spans:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(exp, "\n"))
+	}
+}
+
+func TestTracingReads(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1")
+	c := newTestProvider(t, api)
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	c.transport = &retryTransport{base: http.DefaultTransport, tracer: tp.Tracer(tracerName)}
+	c.provider.HTTPClient.Transport = c.transport
+
+	if _, err := c.GetZoneRecords("example.com"); err != nil {
+		t.Fatal(err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, expected 2 (the zone and www A)", len(spans))
+	}
+	for _, span := range spans {
+		if span.Name != "gcore read" {
+			t.Errorf("got span %q, expected gcore read", span.Name)
+		}
+	}
+}
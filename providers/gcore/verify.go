@@ -0,0 +1,110 @@
+package gcore
+
+// After a push, VerifyPush re-reads the RRsets that the push changed,
+// and only those, to check that G-Core has the records that were sent.
+// This is a few RRset reads instead of reading the whole zone again.
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+
+	dnssdk "github.com/G-Core/gcore-dns-sdk-go"
+)
+
+// pushedRRset is an RRset changed by a correction, and the zone it is in.
+type pushedRRset struct {
+	zone    string
+	records models.Records // nil if it was deleted
+}
+
+// tracked wraps the correction function f, which changes the RRsets in
+// changes (to their desired records, or nil if they are deleted), so
+// that VerifyPush checks them once f has succeeded.
+func (c *gcoreProvider) tracked(zone string, changes map[models.RecordKey]models.Records, f func() error) func() error {
+	return c.trackedIn(zone, zone, changes, f)
+}
+
+// trackedIn is tracked for RRsets in another zone than domain's, such
+// as its reverse PTR records, which VerifyPush(domain) checks too.
+func (c *gcoreProvider) trackedIn(domain, zone string, changes map[models.RecordKey]models.Records, f func() error) func() error {
+	return func() error {
+		if err := f(); err != nil {
+			return err
+		}
+		c.pushedMu.Lock()
+		defer c.pushedMu.Unlock()
+		if c.pushed == nil {
+			c.pushed = map[string]map[models.RecordKey]pushedRRset{}
+		}
+		if c.pushed[domain] == nil {
+			c.pushed[domain] = map[models.RecordKey]pushedRRset{}
+		}
+		for key, recs := range changes {
+			c.pushed[domain][key] = pushedRRset{zone: zone, records: recs}
+		}
+		return nil
+	}
+}
+
+// VerifyPush re-reads each RRset of domain, and each reverse PTR RRset
+// of its addresses, changed by the corrections that have run, and
+// describes those that don't have the records that were pushed.
+func (c *gcoreProvider) VerifyPush(domain string) ([]string, error) {
+	c.pushedMu.Lock()
+	changes := c.pushed[domain]
+	delete(c.pushed, domain)
+	c.pushedMu.Unlock()
+
+	keys := make([]models.RecordKey, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].NameFQDN != keys[j].NameFQDN {
+			return keys[i].NameFQDN < keys[j].NameFQDN
+		}
+		return keys[i].Type < keys[j].Type
+	})
+
+	var mismatches []string
+	for _, key := range keys {
+		name, zone := nativeName(key), changes[key].zone
+		var actual models.Records
+		rrset, err := c.provider.RRSet(c.ctx, zone, name, key.Type)
+		var apiErr dnssdk.APIError
+		switch {
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+			// deleted
+		case err != nil:
+			return nil, err
+		default:
+			if actual, err = nativeToRecords(rrset, zone, name, key.Type); err != nil {
+				return nil, err
+			}
+		}
+
+		pushed := changes[key].records
+		actual = normalizeExisting(pushed, actual)
+		exp, got := recordStrings(pushed), recordStrings(actual)
+		if exp != got {
+			mismatches = append(mismatches, fmt.Sprintf("%s %s: expected [%s], got [%s]", key.NameFQDN, key.Type, exp, got))
+		}
+	}
+	return mismatches, nil
+}
+
+// recordStrings describes recs as they are compared when generating
+// corrections, in a consistent order.
+func recordStrings(recs models.Records) string {
+	s := make([]string, 0, len(recs))
+	for _, rc := range recs {
+		s = append(s, rc.ToDiffable(getCompareMetadata(rc)))
+	}
+	sort.Strings(s)
+	return strings.Join(s, ", ")
+}
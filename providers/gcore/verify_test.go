@@ -0,0 +1,148 @@
+package gcore
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestVerifyPush(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1")
+	api.addRRSet("example.com", "old.example.com", "A", 300, "192.0.2.1")
+	api.addRRSet("example.com", "same.example.com", "A", 300, "192.0.2.1")
+	c := newTestProvider(t, api)
+
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "A", "192.0.2.2"),
+		makeRC("same", "A", "192.0.2.1"),
+	}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only the changed RRsets are read, not the zone or unchanged ones.
+	n := len(api.requests)
+	mismatches, err := c.VerifyPush("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("expected no mismatches, got %q", mismatches)
+	}
+	exp := []string{
+		"GET /v2/zones/example.com/old.example.com/A",
+		"GET /v2/zones/example.com/www.example.com/A",
+	}
+	if got := api.requests[n:]; !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected requests %q, got %q", exp, got)
+	}
+
+	// The changes are only verified once.
+	n = len(api.requests)
+	if _, err := c.VerifyPush("example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if got := api.requests[n:]; len(got) != 0 {
+		t.Errorf("expected no requests, got %q", got)
+	}
+}
+
+func TestVerifyPushMismatch(t *testing.T) {
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.1")
+	c := newTestProvider(t, api)
+
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{
+		makeRC("www", "A", "192.0.2.2"),
+	}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Something else changes the RRset before it is verified.
+	api.addRRSet("example.com", "www.example.com", "A", 300, "192.0.2.3")
+
+	mismatches, err := c.VerifyPush("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 1 || !strings.Contains(mismatches[0], "www.example.com A: expected [192.0.2.2 ttl=300], got [192.0.2.3 ttl=300]") {
+		t.Errorf("unexpected mismatches %q", mismatches)
+	}
+}
+
+func TestVerifyPushReversePTR(t *testing.T) {
+	const zone, name = "2.0.192.in-addr.arpa", "1.2.0.192.in-addr.arpa"
+	api := newFakeAPI()
+	api.addZone("example.com")
+	api.addZone(zone)
+	api.addRRSet(zone, name, "PTR", 300, "old.example.com.")
+	c := newTestProvider(t, api)
+
+	www := makeRC("www", "A", "192.0.2.1")
+	www.Metadata = map[string]string{metaPTR: "true"}
+	dc := &models.DomainConfig{Name: "example.com", Records: models.Records{www}}
+	corrections, err := c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The PTR RRset is read from the reverse zone.
+	n := len(api.requests)
+	mismatches, err := c.VerifyPush("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("expected no mismatches, got %q", mismatches)
+	}
+	exp := []string{
+		"GET /v2/zones/" + zone + "/" + name + "/PTR",
+		"GET /v2/zones/example.com/www.example.com/A",
+	}
+	if got := api.requests[n:]; !reflect.DeepEqual(got, exp) {
+		t.Errorf("expected requests %q, got %q", exp, got)
+	}
+
+	// Something else changes the PTR record before it is verified.
+	api.addRRSet(zone, name, "PTR", 300, "old.example.com.")
+	corrections, err = c.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, correction := range corrections {
+		if err := correction.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	api.addRRSet(zone, name, "PTR", 300, "other.example.com.")
+	mismatches, err = c.VerifyPush("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 1 || !strings.Contains(mismatches[0], name+" PTR: expected [www.example.com. ttl=300], got [other.example.com. ttl=300]") {
+		t.Errorf("unexpected mismatches %q", mismatches)
+	}
+}
@@ -0,0 +1,40 @@
+package gcore
+
+// Practices that still work, but are deprecated or unwise, are printed
+// as warnings when corrections are generated. They never stop a push.
+
+import (
+	"fmt"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+// minApexNSTTL is the lowest TTL of the apex NS records that doesn't
+// get a warning.
+const minApexNSTTL = 3600
+
+// practiceWarnings returns the warnings about the desired records of dc
+// and the existing records of the zone.
+func practiceWarnings(dc *models.DomainConfig, existing models.Records) []string {
+	var warnings []string
+	for _, rec := range existing {
+		if rec.Type == "SPF" {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s has a record of type SPF, which is deprecated (RFC 7208 section 3.1). Publish the policy as a TXT record instead: TXT(%q, %q)",
+				rec.GetLabelFQDN(), rec.GetLabel(), rec.GetTargetTXTJoined()))
+		}
+	}
+
+	var nsTTL uint32
+	for _, rec := range dc.Records {
+		if rec.Type == "NS" && rec.GetLabel() == "@" && (nsTTL == 0 || rec.TTL < nsTTL) {
+			nsTTL = rec.TTL
+		}
+	}
+	if nsTTL != 0 && nsTTL < minApexNSTTL {
+		warnings = append(warnings, fmt.Sprintf(
+			"the NS records of %s have a TTL of %d seconds. Resolvers cache them for longer anyway, so a low TTL only adds queries; use at least %d, such as NAMESERVER_TTL(\"1d\")",
+			dc.Name, nsTTL, minApexNSTTL))
+	}
+	return append(warnings, policyWarnings(dc.Records)...)
+}
@@ -0,0 +1,54 @@
+package gcore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/v3/models"
+)
+
+func TestPracticeWarnings(t *testing.T) {
+	spf := makeRC("@", "SPF", "")
+	spf.SetTargetTXT("v=spf1 -all")
+	ns := func(ttl uint32) *models.RecordConfig {
+		rc := makeRC("@", "NS", "ns1.gcorelabs.net.")
+		rc.TTL = ttl
+		return rc
+	}
+
+	for _, tc := range []struct {
+		name     string
+		desired  models.Records
+		existing models.Records
+		warnings []string
+	}{
+		{
+			name:     "SPF type",
+			desired:  models.Records{ns(86400)},
+			existing: models.Records{spf},
+			warnings: []string{`example.com has a record of type SPF, which is deprecated (RFC 7208 section 3.1). Publish the policy as a TXT record instead: TXT("@", "v=spf1 -all")`},
+		},
+		{
+			name:     "low NS TTL",
+			desired:  models.Records{ns(300), ns(86400)},
+			warnings: []string{"the NS records of example.com have a TTL of 300 seconds"},
+		},
+		{
+			name:    "no warnings",
+			desired: models.Records{ns(3600), makeRC("www", "A", "192.0.2.1")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dc := &models.DomainConfig{Name: "example.com", Records: tc.desired}
+			got := practiceWarnings(dc, tc.existing)
+			if len(got) != len(tc.warnings) {
+				t.Fatalf("got warnings %q, expected %q", got, tc.warnings)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tc.warnings[i]) {
+					t.Errorf("got warning %q, expected it to start with %q", got[i], tc.warnings[i])
+				}
+			}
+		})
+	}
+}
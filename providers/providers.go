@@ -1,11 +1,13 @@
 package providers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 
 	"github.com/StackExchange/dnscontrol/v3/models"
+	"github.com/StackExchange/dnscontrol/v3/pkg/diff"
 )
 
 // Registrar is an interface for a domain registrar. It can return a list of needed corrections to be applied in the future. Implement this only if the provider is a "registrar" (i.e. can update the NS records of the parent to a domain).
@@ -31,6 +33,94 @@ type ZoneLister interface {
 	ListZones() ([]string, error)
 }
 
+// ZoneMetadataGetter may be implemented by providers that have
+// zone-level settings worth showing in "get-zones --meta", such as
+// DNSSEC parameters. It is informational only.
+type ZoneMetadataGetter interface {
+	GetZoneMetadata(domain string) (map[string]string, error)
+}
+
+// CapabilityDiscoverer should be implemented by providers whose
+// capabilities depend on the user's account. The capabilities it
+// returns are applied like a capability override, before the ones in
+// creds.json and dnsconfig.js, so they may only enable capabilities
+// listed in the provider's OverridableCapabilities. It should return
+// nil if discovery is disabled.
+type CapabilityDiscoverer interface {
+	DiscoverCapabilities() (map[Capability]bool, error)
+}
+
+// DiagnosticsReporter may be implemented by providers that can report
+// on their use of the provider's API during the run, such as the number
+// of requests made and the remaining quota. It is printed by
+// --diagnostics, one line per string.
+type DiagnosticsReporter interface {
+	Diagnostics() []string
+}
+
+// PushVerifier may be implemented by providers that can check that the
+// corrections they made were applied. VerifyPush re-reads the records of
+// domain changed by the corrections that have run, and describes each
+// that doesn't match what was sent. It is called by push --verify.
+type PushVerifier interface {
+	VerifyPush(domain string) ([]string, error)
+}
+
+// CompareOnlySetter may be implemented by providers that can be made
+// read-only. After SetCompareOnly, the corrections they return have no
+// F, so that they can't make changes. It is called by preview
+// --compare-only.
+type CompareOnlySetter interface {
+	SetCompareOnly()
+}
+
+// ZoneMigrator may be implemented by providers that can move the
+// records of a zone to a zone with a new name. It is called by the
+// migrate-zone command.
+type ZoneMigrator interface {
+	MigrateZone(from, to string, deleteOld bool) error
+}
+
+// ManagedStamper may be implemented by providers that can mark the
+// records they write as written by DNSControl, so that the drift command
+// can tell them from records added elsewhere. StampsManaged reports
+// whether the provider marks them. LastManaged returns the time
+// DNSControl last wrote rc, a record read from the provider, or "" if it
+// didn't.
+type ManagedStamper interface {
+	StampsManaged() bool
+	LastManaged(rc *models.RecordConfig) string
+}
+
+// DiffRecorder may be implemented by providers that prepare the records
+// before comparing them, such as by adding or converting records, or
+// that compare more than the generic diff does. LastDiff returns the
+// diff that the provider's last corrections for domain were computed
+// from, or false if it hasn't computed any. It is used by preview
+// --explain and --show-all to describe the provider's diff without
+// repeating it.
+type DiffRecorder interface {
+	LastDiff(domain string) (unchanged, create, toDelete, modify diff.Changeset, ok bool)
+}
+
+// ContextSetter may be implemented by providers whose API requests can
+// be cancelled. After SetContext, the provider's requests are made with
+// ctx, so that they fail once it is done. It is called by preview and
+// push --timeout.
+type ContextSetter interface {
+	SetContext(ctx context.Context)
+}
+
+// SnapshotCorrector may be implemented by providers that can compute a
+// domain's corrections against existing, a saved snapshot of its
+// records, without contacting the provider. The corrections only
+// describe the changes; they can't make them. It is used by preview
+// --from-snapshot, which creates the provider with "_from_snapshot":
+// "true" in its credentials, so it must not require the others then.
+type SnapshotCorrector interface {
+	GetSnapshotCorrections(dc *models.DomainConfig, existing models.Records) ([]*models.Correction, error)
+}
+
 // RegistrarInitializer is a function to create a registrar. Function will be passed the unprocessed json payload from the configuration file for the given provider.
 type RegistrarInitializer func(map[string]string) (Registrar, error)
 